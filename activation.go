@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// Activation is a function applied on top of the linear output z = w * x + b.
+// The training process also needs its derivative to push the error back through it.
+type Activation interface {
+	Activate(z float64) float64
+	Derivative(z float64) float64
+}
+
+// Sigmoid squashes any z into the (0, 1) range: 1 / (1 + e^-z).
+// This turns NanoNeuron into a tiny logistic regression that can answer yes/no questions.
+type Sigmoid struct{}
+
+func (Sigmoid) String() string { return "sigmoid" }
+
+func (Sigmoid) goExpr(z string) string { return "1 / (1 + math.Exp(-(" + z + ")))" }
+
+// Activate implements Activation.
+// For negative z the equivalent e^z / (1 + e^z) form is used so that math.Exp
+// is only ever called with a non-positive argument and can't overflow.
+func (Sigmoid) Activate(z float64) float64 {
+	if z >= 0 {
+		return 1 / (1 + math.Exp(-z))
+	}
+	e := math.Exp(z)
+	return e / (1 + e)
+}
+
+// Derivative implements Activation: sigmoid(z) * (1 - sigmoid(z)).
+func (s Sigmoid) Derivative(z float64) float64 {
+	a := s.Activate(z)
+	return a * (1 - a)
+}
+
+// Tanh squashes any z into the (-1, 1) range: (e^z - e^-z) / (e^z + e^-z).
+// It's a sigmoid centered at zero. Computing it from the formula would overflow e^z for big z,
+// so it's left to math.Tanh, which returns exactly -1 or 1 far in the tails.
+type Tanh struct{}
+
+func (Tanh) String() string { return "tanh" }
+
+func (Tanh) goExpr(z string) string { return "math.Tanh(" + z + ")" }
+
+// Activate implements Activation.
+func (Tanh) Activate(z float64) float64 {
+	return math.Tanh(z)
+}
+
+// Derivative implements Activation: 1 - tanh(z)^2, with tanh(z) computed once.
+func (Tanh) Derivative(z float64) float64 {
+	t := math.Tanh(z)
+	return 1 - t*t
+}
+
+// Softplus is a smooth ReLU: log(1 + e^z), close to 0 for very negative z and to z for very
+// positive z, with the Sigmoid as its derivative. Computing it from the formula would overflow
+// e^z for big z, so positive z use the equivalent z + log(1 + e^-z), which keeps the argument
+// of math.Exp non-positive.
+type Softplus struct{}
+
+func (Softplus) String() string { return "softplus" }
+
+func (Softplus) goExpr(z string) string {
+	return "func(z float64) float64 { if z > 0 { return z + math.Log1p(math.Exp(-z)) }; return math.Log1p(math.Exp(z)) }(" + z + ")"
+}
+
+// Activate implements Activation.
+func (Softplus) Activate(z float64) float64 {
+	if z > 0 {
+		return z + math.Log1p(math.Exp(-z))
+	}
+	return math.Log1p(math.Exp(z))
+}
+
+// Derivative implements Activation: sigmoid(z).
+func (Softplus) Derivative(z float64) float64 {
+	return Sigmoid{}.Activate(z)
+}
+
+// chainRule pushes the error 'signal' of the model output for input 'x' back through the
+// output transform and the activation to the linear part z = w * x + b of the model.
+// For a plain linear model the signal passes through unchanged.
+func (n *NanoNeuron) chainRule(x, signal float64) float64 {
+	if n.activation != nil {
+		signal *= n.activation.Derivative(x*n.w + n.b)
+	}
+	if n.output != nil {
+		signal *= n.output.scale
+	}
+	return signal
+}
+
+// LeakyReLU returns the leaky rectified linear unit: z for z >= 0 and alpha * z otherwise.
+// Unlike a plain ReLU the small negative slope keeps some gradient flowing for negative z,
+// so the neuron can't "die". By convention the derivative at exactly z = 0 is 1.
+func LeakyReLU(alpha float64) Activation {
+	return leakyReLU{alpha: alpha}
+}
+
+type leakyReLU struct {
+	alpha float64
+}
+
+func (a leakyReLU) Activate(z float64) float64 {
+	if z >= 0 {
+		return z
+	}
+	return a.alpha * z
+}
+
+func (a leakyReLU) Derivative(z float64) float64 {
+	if z >= 0 {
+		return 1
+	}
+	return a.alpha
+}
+
+func (a leakyReLU) String() string { return fmt.Sprintf("leakyReLU[%g]", a.alpha) }
+
+func (a leakyReLU) goExpr(z string) string {
+	if a.alpha <= 1 {
+		return fmt.Sprintf("math.Max(%s, %s*(%s))", z, goFloat(a.alpha), z)
+	}
+	return fmt.Sprintf("math.Min(%s, %s*(%s))", z, goFloat(a.alpha), z)
+}
+
+// ELU returns the exponential linear unit: z for z >= 0 and alpha * (e^z - 1) otherwise.
+// It is smooth for negative z and saturates at -alpha instead of dying like a ReLU.
+// By convention the derivative at exactly z = 0 is 1 (the slope of the positive side).
+func ELU(alpha float64) Activation {
+	return elu{alpha: alpha}
+}
+
+type elu struct {
+	alpha float64
+}
+
+func (a elu) Activate(z float64) float64 {
+	if z >= 0 {
+		return z
+	}
+	return a.alpha * math.Expm1(z)
+}
+
+func (a elu) Derivative(z float64) float64 {
+	if z >= 0 {
+		return 1
+	}
+	return a.alpha * math.Exp(z)
+}
+
+func (a elu) String() string { return fmt.Sprintf("elu[%g]", a.alpha) }
+
+func (a elu) goExpr(z string) string {
+	return fmt.Sprintf("func(z float64) float64 { if z >= 0 { return z }; return %s * math.Expm1(z) }(%s)", goFloat(a.alpha), z)
+}
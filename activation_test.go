@@ -0,0 +1,102 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSigmoidExtremeInputs(t *testing.T) {
+	for _, test := range []struct{ z, want float64 }{
+		{-1000, 0},
+		{1000, 1},
+		{math.Inf(-1), 0},
+		{math.Inf(1), 1},
+		{0, 0.5},
+	} {
+		if got := (Sigmoid{}).Activate(test.z); got != test.want {
+			t.Errorf("sigmoid(%v) = %v, want %v", test.z, got, test.want)
+		}
+		if d := (Sigmoid{}).Derivative(test.z); math.IsNaN(d) {
+			t.Errorf("sigmoid'(%v) is NaN", test.z)
+		}
+	}
+}
+
+func TestTanhExtremeInputsAndDerivative(t *testing.T) {
+	for _, test := range []struct{ z, want float64 }{
+		{-1000, -1},
+		{1000, 1},
+		{math.Inf(-1), -1},
+		{math.Inf(1), 1},
+		{0, 0},
+	} {
+		if got := (Tanh{}).Activate(test.z); got != test.want {
+			t.Errorf("tanh(%v) = %v, want %v", test.z, got, test.want)
+		}
+		if d := (Tanh{}).Derivative(test.z); math.IsNaN(d) {
+			t.Errorf("tanh'(%v) is NaN", test.z)
+		}
+	}
+	const h = 1e-6
+	for _, z := range []float64{-5, -1, -0.3, 0, 0.7, 2, 8} {
+		numeric := (Tanh{}.Activate(z+h) - Tanh{}.Activate(z-h)) / (2 * h)
+		if d := (Tanh{}).Derivative(z); math.Abs(d-numeric) > 1e-9 {
+			t.Errorf("tanh'(%v) = %v, finite differences %v", z, d, numeric)
+		}
+	}
+}
+
+func TestSoftplusValuesDerivativeAndExtremeInputs(t *testing.T) {
+	for _, test := range []struct{ z, want, derivative float64 }{
+		{0, math.Ln2, 0.5},
+		{1, math.Log(1 + math.E), 1 / (1 + math.Exp(-1))},
+		{-2, math.Log(1 + math.Exp(-2)), 1 / (1 + math.Exp(2))},
+		// Far in the tails the formula would overflow or lose everything to rounding.
+		{1000, 1000, 1},
+		{-1000, 0, 0},
+		{-50, math.Exp(-50), math.Exp(-50)},
+	} {
+		if got := (Softplus{}).Activate(test.z); math.Abs(got-test.want) > 1e-15*math.Max(1, test.want) {
+			t.Errorf("softplus(%v) = %v, want %v", test.z, got, test.want)
+		}
+		if got := (Softplus{}).Derivative(test.z); math.Abs(got-test.derivative) > 1e-15 {
+			t.Errorf("softplus'(%v) = %v, want %v", test.z, got, test.derivative)
+		}
+	}
+	if got := (Softplus{}).Activate(math.Inf(1)); !math.IsInf(got, 1) {
+		t.Errorf("softplus(+Inf) = %v", got)
+	}
+	const h = 1e-6
+	for _, z := range []float64{-5, -1, -0.3, 0, 0.7, 2, 8} {
+		numeric := (Softplus{}.Activate(z+h) - Softplus{}.Activate(z-h)) / (2 * h)
+		if d := (Softplus{}).Derivative(z); math.Abs(d-numeric) > 1e-9 {
+			t.Errorf("softplus'(%v) = %v, finite differences %v", z, d, numeric)
+		}
+	}
+}
+
+func TestLeakyReLUAndELU(t *testing.T) {
+	tests := []struct {
+		name             string
+		act              Activation
+		z, y, derivative float64
+	}{
+		{"leaky positive", LeakyReLU(0.1), 3, 3, 1},
+		{"leaky negative", LeakyReLU(0.1), -3, -0.3, 0.1},
+		{"leaky zero", LeakyReLU(0.1), 0, 0, 1},
+		{"elu positive", ELU(2), 3, 3, 1},
+		{"elu negative", ELU(2), -1, 2 * (math.Exp(-1) - 1), 2 * math.Exp(-1)},
+		{"elu zero", ELU(2), 0, 0, 1},
+	}
+	for _, test := range tests {
+		if y := test.act.Activate(test.z); math.Abs(y-test.y) > 1e-15 {
+			t.Errorf("%s: activate(%v) = %v, want %v", test.name, test.z, y, test.y)
+		}
+		if d := test.act.Derivative(test.z); math.Abs(d-test.derivative) > 1e-15 {
+			t.Errorf("%s: derivative(%v) = %v, want %v", test.name, test.z, d, test.derivative)
+		}
+	}
+	if y := ELU(2).Activate(-1000); y != -2 {
+		t.Errorf("ELU saturates at %v, want -alpha", y)
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// RunAudit is the provenance record of a training run, to prove later that a result is
+// reproducible: everything that went into the run and what came out of it. It is meant to be
+// stored as JSON next to the model. The parameters are written as text, so the audit of a
+// diverged run (NaN or infinite parameters) can be stored as well.
+type RunAudit struct {
+	Config    TrainingConfig `json:"config"`
+	Seed      int64          `json:"seed"`
+	GoVersion string         `json:"go_version"`
+	// DataHash is the SHA-256 of the exact bits of the training inputs and labels.
+	DataHash string `json:"data_hash"`
+	Epochs   int    `json:"epochs"` // the epochs actually trained
+	W        string `json:"w"`      // the final parameters in full precision
+	B        string `json:"b"`
+	// Hash is the SHA-256 of all the above but the Go version, which is only recorded:
+	// two runs with the same hash had the same config and data and reached the very same
+	// parameters, whichever Go release built them.
+	Hash string `json:"hash"`
+}
+
+// AuditRun records the RunAudit of the training with TrainFromConfig of 'cfg' that produced
+// 'result'. The hash of the data-set comes from TrainingResult.DataHash, which only
+// TrainFromConfig fills in.
+func AuditRun(cfg TrainingConfig, result *TrainingResult) RunAudit {
+	audit := RunAudit{
+		Config:    cfg,
+		Seed:      cfg.Seed,
+		GoVersion: runtime.Version(),
+		DataHash:  result.DataHash,
+		Epochs:    len(result.CostHistory),
+		W:         strconv.FormatFloat(result.Final.w, 'g', -1, 64),
+		B:         strconv.FormatFloat(result.Final.b, 'g', -1, 64),
+	}
+	hashed := audit
+	hashed.GoVersion = ""
+	// Not JSON: it can't encode a NaN or an infinite alpha, which would make such runs collide.
+	var text strings.Builder
+	writeCanonical(&text, reflect.ValueOf(hashed))
+	sum := sha256.Sum256([]byte(text.String()))
+	audit.Hash = hex.EncodeToString(sum[:])
+	return audit
+}
+
+// writeCanonical writes 'v' as text for hashing: the structs field by field with their names, the
+// numbers in full precision (NaN and the infinities included), so different values never write
+// the same text. Nil pointers are written as "nil".
+func writeCanonical(b *strings.Builder, v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Struct:
+		b.WriteString("{")
+		for i := range v.NumField() {
+			b.WriteString(v.Type().Field(i).Name + ":")
+			writeCanonical(b, v.Field(i))
+			b.WriteString(";")
+		}
+		b.WriteString("}")
+	case reflect.Pointer:
+		if v.IsNil() {
+			b.WriteString("nil")
+		} else {
+			writeCanonical(b, v.Elem())
+		}
+	case reflect.Float32, reflect.Float64:
+		b.WriteString(strconv.FormatFloat(v.Float(), 'g', -1, 64))
+	case reflect.String:
+		b.WriteString(strconv.Quote(v.String()))
+	default:
+		fmt.Fprint(b, v.Interface())
+	}
+}
+
+// dataHash is the hex SHA-256 of the inputs and then the labels, 8 little endian bytes each.
+func dataHash(x, y []float64) string {
+	h := sha256.New()
+	var buf [8]byte
+	for _, column := range [][]float64{x, y} {
+		binary.LittleEndian.PutUint64(buf[:], uint64(len(column)))
+		h.Write(buf[:])
+		for _, v := range column {
+			binary.LittleEndian.PutUint64(buf[:], math.Float64bits(v))
+			h.Write(buf[:])
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
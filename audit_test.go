@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestAuditRunIsReproducible(t *testing.T) {
+	x, y := generateDataSets(0, 1, 0, NewRandSource(2))
+	audit := func(cfg TrainingConfig) RunAudit {
+		_, result, err := TrainFromConfig(cfg, x, y)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return AuditRun(cfg, result)
+	}
+	cfg := TrainingConfig{Epochs: 500, Alpha: 0.0005, Seed: 1}
+	first, second := audit(cfg), audit(cfg)
+	if first.Hash == "" || first != second {
+		t.Errorf("identical runs got the audits\n%+v\n%+v", first, second)
+	}
+
+	cfg.Seed = 2
+	if other := audit(cfg); other.Hash == first.Hash || other.DataHash != first.DataHash {
+		t.Errorf("another seed got the hash %s and the data hash %s", other.Hash, other.DataHash)
+	}
+	if dataHash(x, y[:len(y)-1]) == first.DataHash {
+		t.Error("different data got the same hash")
+	}
+
+	data, err := json.Marshal(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded RunAudit
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded != first {
+		t.Errorf("the JSON round trip gave %+v (%v)", decoded, err)
+	}
+}
+
+func TestAuditHashesNonFiniteConfigs(t *testing.T) {
+	result := &TrainingResult{CostHistory: []float64{1}, Final: &NanoNeuron{w: 1.8, b: 32}}
+	hashes := map[string]float64{}
+	for _, alpha := range []float64{math.NaN(), math.Inf(1), math.Inf(-1), 0.0005} {
+		hash := AuditRun(TrainingConfig{Epochs: 1, Alpha: alpha}, result).Hash
+		if other, ok := hashes[hash]; ok {
+			t.Errorf("alpha %v got the same hash as %v", alpha, other)
+		}
+		hashes[hash] = alpha
+	}
+}
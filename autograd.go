@@ -0,0 +1,111 @@
+package main
+
+// Value is a scalar that remembers how it was computed, a tiny automatic differentiation
+// (autograd) engine for teaching backpropagation. Write the forward pass with Add and Mul
+// and Backward computes the derivative of the result by every Value it was computed from,
+// applying the chain rule step by step backwards. This is exactly what backwardPropagation
+// does by hand for the NanoNeuron cost, see backwardPropagationAutograd.
+type Value struct {
+	Data float64 // the value itself
+	Grad float64 // the derivative of the Backward root by this value
+
+	parents  []*Value
+	backward func() // passes this value's Grad on to its parents
+}
+
+// NewValue creates a leaf value, i.e. an input or a parameter.
+func NewValue(data float64) *Value {
+	return &Value{Data: data}
+}
+
+// Add returns v + o.
+func (v *Value) Add(o *Value) *Value {
+	out := &Value{Data: v.Data + o.Data, parents: []*Value{v, o}}
+	out.backward = func() {
+		// d(v + o)/dv = 1 and d(v + o)/do = 1.
+		v.Grad += out.Grad
+		o.Grad += out.Grad
+	}
+	return out
+}
+
+// Sub returns v - o.
+func (v *Value) Sub(o *Value) *Value {
+	out := &Value{Data: v.Data - o.Data, parents: []*Value{v, o}}
+	out.backward = func() {
+		v.Grad += out.Grad
+		o.Grad -= out.Grad
+	}
+	return out
+}
+
+// Mul returns v * o.
+func (v *Value) Mul(o *Value) *Value {
+	out := &Value{Data: v.Data * o.Data, parents: []*Value{v, o}}
+	out.backward = func() {
+		// d(v * o)/dv = o and d(v * o)/do = v.
+		v.Grad += o.Data * out.Grad
+		o.Grad += v.Data * out.Grad
+	}
+	return out
+}
+
+// Activate returns a.Activate(v).
+func (v *Value) Activate(a Activation) *Value {
+	out := &Value{Data: a.Activate(v.Data), parents: []*Value{v}}
+	out.backward = func() {
+		v.Grad += a.Derivative(v.Data) * out.Grad
+	}
+	return out
+}
+
+// Backward computes the Grad of every value this one was computed from. The values are
+// visited in the reverse topological order, so every value has received the gradients from
+// all its uses before it passes its own on.
+func (v *Value) Backward() {
+	var order []*Value
+	visited := map[*Value]bool{}
+	var visit func(*Value)
+	visit = func(node *Value) {
+		if visited[node] {
+			return
+		}
+		visited[node] = true
+		for _, parent := range node.parents {
+			visit(parent)
+		}
+		order = append(order, node)
+	}
+	visit(v)
+
+	v.Grad = 1
+	for i := len(order) - 1; i >= 0; i-- {
+		if order[i].backward != nil {
+			order[i].backward()
+		}
+	}
+}
+
+// backwardPropagationAutograd is backwardPropagation with the derivatives computed by Value
+// instead of by hand. The forward pass is written down symbolically: the cost of every
+// example is costScale * (y - prediction) ^ 2 with prediction = x * w + b (plus the activation
+// and the output transform). The returned steps point downhill, like the ones of backwardPropagation.
+func backwardPropagationAutograd(model *NanoNeuron, xTrain, yTrain []float64) (float64, float64) {
+	mustMatch("autograd backward propagation", xTrain, yTrain)
+	w, b := NewValue(model.w), NewValue(model.b)
+	cost := NewValue(0)
+	for i := range xTrain {
+		prediction := NewValue(xTrain[i]).Mul(w).Add(b)
+		if model.activation != nil {
+			prediction = prediction.Activate(model.activation)
+		}
+		if model.output != nil {
+			prediction = prediction.Mul(NewValue(model.output.scale)).Add(NewValue(model.output.offset))
+		}
+		diff := NewValue(yTrain[i]).Sub(prediction)
+		cost = cost.Add(diff.Mul(diff).Mul(NewValue(costScale)))
+	}
+	cost = cost.Mul(NewValue(1 / float64(len(xTrain))))
+	cost.Backward()
+	return -w.Grad, -b.Grad
+}
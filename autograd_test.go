@@ -0,0 +1,40 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestValueBackward(t *testing.T) {
+	// f = (a * b + a) - b with the gradient (b + 1, a - 1); 'a' is used twice and gets both parts.
+	a, b := NewValue(3), NewValue(-2)
+	f := a.Mul(b).Add(a).Sub(b)
+	f.Backward()
+	if f.Data != -1 || a.Grad != -1 || b.Grad != 2 {
+		t.Errorf("got f=%v df/da=%v df/db=%v, want -1, -1 and 2", f.Data, a.Grad, b.Grad)
+	}
+}
+
+func TestAutogradMatchesTheHandDerivedGradient(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	for _, model := range []*NanoNeuron{
+		{w: 0.3, b: 0.7},
+		{w: 0.01, b: -1, activation: Sigmoid{}},
+		{w: 0.5, b: 1, output: &affine{scale: 1.8, offset: 32}},
+	} {
+		predictions, _ := forwardPropagation(model, x, y)
+		dW, dB := backwardPropagation(model, predictions, x, y)
+		autoW, autoB := backwardPropagationAutograd(model, x, y)
+		if math.Abs(autoW-dW) > 1e-9*math.Max(1, math.Abs(dW)) || math.Abs(autoB-dB) > 1e-9*math.Max(1, math.Abs(dB)) {
+			t.Errorf("%v: autograd (%v, %v), by hand (%v, %v)", model, autoW, autoB, dW, dB)
+		}
+	}
+
+	byHand := NewNanoNeuron(NewRandSource(1))
+	trainModel(byHand, 200, 0.0005, x, y, TrainOptions{})
+	auto := NewNanoNeuron(NewRandSource(1))
+	trainModel(auto, 200, 0.0005, x, y, TrainOptions{Autograd: true})
+	if math.Abs(auto.w-byHand.w) > 1e-9 || math.Abs(auto.b-byHand.b) > 1e-9 {
+		t.Errorf("the autograd training got %v, by hand %v", auto, byHand)
+	}
+}
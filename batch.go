@@ -0,0 +1,120 @@
+package main
+
+import "math"
+
+// AdaptiveBatch grows the mini-batches as the training goes on: small batches early make many
+// cheap steps while the gradient points clearly downhill, big batches late average out the noise
+// of the examples that would keep the parameters bouncing around the minimum.
+// After every epoch Rule picks the batch size of the next one, which is then clamped into
+// [MinBatch, MaxBatch]. The first epoch uses MinBatch.
+type AdaptiveBatch struct {
+	MinBatch int
+	MaxBatch int
+	// Rule gets the current batch size and the gradient noise scale (see gradientNoiseScale)
+	// measured after the epoch. Nil is NoiseScaleGrowth.
+	Rule func(current int, noiseScale float64) int
+}
+
+// NoiseScaleGrowth is the default growth rule of AdaptiveBatch: the batch size follows the
+// gradient noise scale, the batch size at which the noise of the gradient is about as big as
+// the gradient itself, but it never shrinks.
+func NoiseScaleGrowth(current int, noiseScale float64) int {
+	if !isFinite(noiseScale) {
+		return current
+	}
+	return max(current, int(math.Min(math.Ceil(noiseScale), math.MaxInt32)))
+}
+
+// next is the batch size of the epoch after the one trained with 'current'.
+func (a *AdaptiveBatch) next(model *NanoNeuron, current int, xTrain, yTrain []float64) int {
+	rule := a.Rule
+	if rule == nil {
+		rule = NoiseScaleGrowth
+	}
+	return min(max(rule(current, gradientNoiseScale(model, xTrain, yTrain)), a.MinBatch), a.MaxBatch)
+}
+
+// gradientNoiseScale is the "simple" gradient noise scale tr(Σ) / |G|^2 of the squared error:
+// the variance of the gradients (dW, dB) of the single examples summed over both parameters,
+// relative to the squared norm of their mean G, the full batch gradient. The gradient of a
+// batch of B examples has the variance tr(Σ) / B, so the noise scale is the batch size at
+// which the noise is as big as the signal. It grows as the training converges and G vanishes.
+func gradientNoiseScale(model *NanoNeuron, xTrain, yTrain []float64) float64 {
+	m := float64(len(xTrain))
+	var meanW, meanB, squares float64
+	for i, x := range xTrain {
+		delta := model.chainRule(x, 2*costScale*(yTrain[i]-model.predict(x)))
+		gW, gB := delta*x, delta
+		meanW += gW / m
+		meanB += gB / m
+		squares += (gW*gW + gB*gB) / m
+	}
+	norm := meanW*meanW + meanB*meanB
+	return math.Max(0, squares-norm) / norm
+}
+
+// BatchIterator splits a data-set into mini-batches of a fixed size, the last one possibly
+// smaller. Every example is in exactly one batch of a pass over the data-set.
+// With a random source the examples are shuffled before every pass, so a seeded source
+// (see NewRandSource) gives reproducible batches; without one they go in order.
+type BatchIterator struct {
+	x, y      []float64
+	batchSize int
+	rng       RandSource
+	// The shuffled examples of the current pass (only with rng).
+	xOrder, yOrder []float64
+	next           int
+}
+
+// NewBatchIterator creates an iterator over the batches of 'batchSize' examples (0 or more than
+// the examples means a single full batch) ready for the first pass. 'rng' may be nil.
+func NewBatchIterator(x, y []float64, batchSize int, rng RandSource) *BatchIterator {
+	mustMatch("batch iterator", x, y)
+	if batchSize <= 0 || batchSize > len(x) {
+		batchSize = len(x)
+	}
+	it := &BatchIterator{x: x, y: y, batchSize: batchSize, rng: rng}
+	if rng != nil {
+		it.xOrder = make([]float64, len(x))
+		it.yOrder = make([]float64, len(y))
+	}
+	it.Reset()
+	return it
+}
+
+// Next returns the next batch of the pass; 'ok' is false when the pass is over.
+// The batches are valid until the next Reset.
+func (it *BatchIterator) Next() (xBatch, yBatch []float64, ok bool) {
+	if it.next >= len(it.x) {
+		return nil, nil, false
+	}
+	x, y := it.x, it.y
+	if it.rng != nil {
+		x, y = it.xOrder, it.yOrder
+	}
+	end := min(it.next+it.batchSize, len(x))
+	xBatch, yBatch = x[it.next:end:end], y[it.next:end:end]
+	it.next = end
+	return xBatch, yBatch, true
+}
+
+// Reset starts a new pass over the data-set, shuffled again with the random source (if any).
+func (it *BatchIterator) Reset() {
+	it.next = 0
+	if it.rng == nil {
+		return
+	}
+	for i, j := range perm(len(it.x), it.rng) {
+		it.xOrder[i], it.yOrder[i] = it.x[j], it.y[j]
+	}
+}
+
+// Len is the number of examples in a pass.
+func (it *BatchIterator) Len() int {
+	return len(it.x)
+}
+
+// more tells if the pass has more batches left.
+func (it *BatchIterator) more() bool {
+	return it.next < len(it.x)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAdaptiveBatchGrowsAndConverges(t *testing.T) {
+	x, y := generateDataSets(0, 5, 0, NewRandSource(1))
+	model := NewNanoNeuron(NewRandSource(1))
+	result, err := Train(model, x, y, 3000, 0.0001, TrainOptions{AdaptiveBatch: &AdaptiveBatch{MinBatch: 1, MaxBatch: 50}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sizes := result.BatchSizeHistory
+	if len(sizes) != len(result.CostHistory) {
+		t.Fatalf("%d batch sizes for %d epochs", len(sizes), len(result.CostHistory))
+	}
+	for epoch := 1; epoch < len(sizes); epoch++ {
+		if sizes[epoch] < sizes[epoch-1] {
+			t.Fatalf("the batch shrank from %d to %d at epoch %d", sizes[epoch-1], sizes[epoch], epoch)
+		}
+	}
+	if first, last := sizes[0], sizes[len(sizes)-1]; first != 1 || last <= first || last > 50 {
+		t.Errorf("the batch size went from %d to %d, want it to grow from 1 up to at most 50", first, last)
+	}
+	// The noise of the labels alone costs about costScale * 5^2.
+	if cost := Evaluate(model, x, y).Cost; cost > 2*costScale*25 {
+		t.Errorf("trained to the cost %v from %v", cost, result.InitialCost)
+	}
+
+	if _, err := Train(model, x, y, 10, 0.0001, TrainOptions{AdaptiveBatch: &AdaptiveBatch{MinBatch: 10, MaxBatch: 5}}); err == nil {
+		t.Error("a maximum batch below the minimum was accepted")
+	}
+}
+
+func TestBatchIteratorCoversEveryExampleOnce(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	pass := func(it *BatchIterator) (xs []float64, sizes []int) {
+		for {
+			xBatch, yBatch, ok := it.Next()
+			if !ok {
+				return xs, sizes
+			}
+			for i := range xBatch {
+				if yBatch[i] != celsiusToFahrenheit(xBatch[i]) {
+					t.Fatalf("the example %v got the label %v", xBatch[i], yBatch[i])
+				}
+			}
+			xs = append(xs, xBatch...)
+			sizes = append(sizes, len(xBatch))
+		}
+	}
+	it := NewBatchIterator(x, y, 30, NewRandSource(1))
+	first, sizes := pass(it)
+	if !slices.Equal(sizes, []int{30, 30, 30, 10}) {
+		t.Errorf("batch sizes %v, want 3 full batches and a partial one of 10", sizes)
+	}
+	if !slices.Equal(slices.Sorted(slices.Values(first)), x) {
+		t.Errorf("the pass %v isn't every example exactly once", first)
+	}
+	it.Reset()
+	second, _ := pass(it)
+	if !slices.Equal(slices.Sorted(slices.Values(second)), x) || slices.Equal(first, second) {
+		t.Errorf("the second pass %v isn't a reshuffled pass over all the examples", second)
+	}
+	if again, _ := pass(NewBatchIterator(x, y, 30, NewRandSource(1))); !slices.Equal(again, first) {
+		t.Error("the same seed shuffled the examples differently")
+	}
+	if ordered, _ := pass(NewBatchIterator(x, y, 30, nil)); !slices.Equal(ordered, x) {
+		t.Errorf("without a random source the examples went %v", ordered)
+	}
+}
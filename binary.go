@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// The first byte of the binary encoding, so the format can evolve.
+const binaryVersion = 1
+
+// The flags byte of the binary encoding. Any other bit is unknown and rejected by UnmarshalBinary.
+const (
+	binaryHasOutput  = 1 << 0
+	binaryKnownFlags = binaryHasOutput
+)
+
+// MarshalBinary encodes the model compactly: a version byte, a flags byte and the parameters
+// 'w' and 'b' (plus the output transform, if any) as little-endian float64 values.
+// It implements encoding.BinaryMarshaler, so the model works with gob and other encoders.
+// Activations are code, not data, so models with an activation can't be encoded.
+func (n *NanoNeuron) MarshalBinary() ([]byte, error) {
+	if n.activation != nil {
+		return nil, fmt.Errorf("binary encoding: activation %v can't be encoded", n.activation)
+	}
+	values := []float64{n.w, n.b}
+	var flags byte
+	if n.output != nil {
+		flags |= binaryHasOutput
+		values = append(values, n.output.scale, n.output.offset)
+	}
+	data := []byte{binaryVersion, flags}
+	for _, v := range values {
+		data = binary.LittleEndian.AppendUint64(data, math.Float64bits(v))
+	}
+	return data, nil
+}
+
+// UnmarshalBinary decodes a model encoded by MarshalBinary, implementing encoding.BinaryUnmarshaler.
+// Unknown flags are errors: the data is corrupt or of a newer format that can't be decoded correctly.
+func (n *NanoNeuron) UnmarshalBinary(data []byte) error {
+	if len(data) < 2 {
+		return errors.New("binary decoding: data too short")
+	}
+	if data[0] != binaryVersion {
+		return fmt.Errorf("binary decoding: unknown version %d", data[0])
+	}
+	flags, data := data[1], data[2:]
+	if unknown := flags &^ binaryKnownFlags; unknown != 0 {
+		return fmt.Errorf("binary decoding: unknown flags %#02x", unknown)
+	}
+	count := 2
+	if flags&binaryHasOutput != 0 {
+		count += 2
+	}
+	if len(data) != count*8 {
+		return fmt.Errorf("binary decoding: expected %d bytes of parameters, got %d", count*8, len(data))
+	}
+	values := make([]float64, count)
+	for i := range values {
+		values[i] = math.Float64frombits(binary.LittleEndian.Uint64(data[i*8:]))
+	}
+	*n = NanoNeuron{w: values[0], b: values[1]}
+	if flags&binaryHasOutput != 0 {
+		n.output = &affine{scale: values[2], offset: values[3]}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBinaryRoundTrip(t *testing.T) {
+	for _, model := range []*NanoNeuron{
+		{w: 1.8, b: 32},
+		{w: math.Nextafter(1.8, 2), b: -0.1},
+		{w: 0.5, b: 1, output: &affine{scale: 1.8, offset: 32}},
+	} {
+		data, err := model.MarshalBinary()
+		if err != nil {
+			t.Fatalf("%v: MarshalBinary: %v", model, err)
+		}
+		var decoded NanoNeuron
+		if err := decoded.UnmarshalBinary(data); err != nil {
+			t.Fatalf("%v: UnmarshalBinary: %v", model, err)
+		}
+		if !reflect.DeepEqual(&decoded, model) {
+			t.Errorf("decoded %+v, want %+v", &decoded, model)
+		}
+	}
+	if _, err := (&NanoNeuron{activation: Sigmoid{}}).MarshalBinary(); err == nil {
+		t.Error("a model with an activation was encoded")
+	}
+	for _, data := range [][]byte{nil, {2, 0}, {binaryVersion, 0, 1, 2}} {
+		if err := new(NanoNeuron).UnmarshalBinary(data); err == nil {
+			t.Errorf("decoded %v without an error", data)
+		}
+	}
+
+	// A valid model whose header carries a flag this version doesn't know.
+	data, _ := (&NanoNeuron{w: 1.8, b: 32}).MarshalBinary()
+	for _, flag := range []byte{1 << 1, 1 << 7} {
+		corrupt := append([]byte(nil), data...)
+		corrupt[1] |= flag
+		if err := new(NanoNeuron).UnmarshalBinary(corrupt); err == nil || !strings.Contains(err.Error(), "unknown flags") {
+			t.Errorf("flag %#02x: got %v, want an unknown flags error", flag, err)
+		}
+	}
+}
+
+func TestGobEncodesTheModel(t *testing.T) {
+	model := &NanoNeuron{w: 1.8, b: 32, output: &affine{scale: 2, offset: -1}}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(model); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	var decoded NanoNeuron
+	if err := gob.NewDecoder(&buf).Decode(&decoded); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if !reflect.DeepEqual(&decoded, model) {
+		t.Errorf("decoded %+v, want %+v", &decoded, model)
+	}
+}
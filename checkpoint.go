@@ -0,0 +1,51 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// checkpoint is the content of a checkpoint file.
+type checkpoint struct {
+	Model     *NanoNeuron    `json:"model"`
+	Epoch     int            `json:"epoch"`
+	Optimizer OptimizerState `json:"optimizer"`
+}
+
+// SaveCheckpoint saves everything a long training needs to recover from a crash into the JSON
+// file 'path': the model, the number of epochs trained so far and the optimizer state
+// (see StatefulOptimizer, the zero OptimizerState for the plain gradient descent).
+// The file is written next to 'path' first and then renamed over it, so a crash while saving
+// leaves the previous checkpoint intact.
+func SaveCheckpoint(path string, model *NanoNeuron, epoch int, optState OptimizerState) error {
+	data, err := json.Marshal(checkpoint{Model: model, Epoch: epoch, Optimizer: optState})
+	if err != nil {
+		return fmt.Errorf("save checkpoint: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("save checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("save checkpoint: %w", err)
+	}
+	return nil
+}
+
+// LoadCheckpoint reads a checkpoint saved by SaveCheckpoint. To resume the training exactly,
+// restore the optimizer with SetState and continue from 'epoch' with a Trainer whose Resumed is 'epoch'.
+func LoadCheckpoint(path string) (model *NanoNeuron, epoch int, optState OptimizerState, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, OptimizerState{}, fmt.Errorf("load checkpoint: %w", err)
+	}
+	var c checkpoint
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, 0, OptimizerState{}, fmt.Errorf("load checkpoint %s: %w", path, err)
+	}
+	if c.Model == nil {
+		return nil, 0, OptimizerState{}, fmt.Errorf("load checkpoint %s: no model", path)
+	}
+	return c.Model, c.Epoch, c.Optimizer, nil
+}
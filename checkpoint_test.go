@@ -0,0 +1,44 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckpointResumesExactly(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	options := func(optimizer Optimizer) TrainOptions {
+		return TrainOptions{Optimizer: optimizer, Schedule: CosineAnnealing{MaxLR: 0.0001, MinLR: 0.00001, Restart: 300}}
+	}
+
+	uninterrupted := NewNanoNeuron(NewRandSource(1))
+	trainModel(uninterrupted, 1000, 0.01, x, y, options(NewMomentum(0.9)))
+
+	momentum := NewMomentum(0.9)
+	first := &Trainer{Model: NewNanoNeuron(NewRandSource(1)), XTrain: x, YTrain: y, Epochs: 500, Alpha: 0.01, Options: options(momentum)}
+	first.RunChunk(500)
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	if err := SaveCheckpoint(path, first.Model, first.Trained(), momentum.State()); err != nil {
+		t.Fatalf("SaveCheckpoint: %v", err)
+	}
+
+	model, epoch, state, err := LoadCheckpoint(path)
+	if err != nil {
+		t.Fatalf("LoadCheckpoint: %v", err)
+	}
+	if epoch != 500 {
+		t.Fatalf("loaded the epoch %d, want 500", epoch)
+	}
+	resumedMomentum := NewMomentum(0.9)
+	if err := resumedMomentum.SetState(state); err != nil {
+		t.Fatalf("SetState: %v", err)
+	}
+	resumed := &Trainer{Model: model, XTrain: x, YTrain: y, Epochs: 1000, Alpha: 0.01, Options: options(resumedMomentum), Resumed: epoch}
+	resumed.RunChunk(1000)
+	if resumed.Trained() != 1000 {
+		t.Errorf("trained up to the epoch %d, want 1000", resumed.Trained())
+	}
+	if *model != *uninterrupted {
+		t.Errorf("resumed model %v, want %v", model, uninterrupted)
+	}
+}
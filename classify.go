@@ -0,0 +1,41 @@
+package main
+
+import "math"
+
+// Classify answers a yes/no question about 'x': 1 if the model output exceeds the threshold, 0 otherwise.
+// NanoNeuron is a regressor, so this is only meaningful for a model with a Sigmoid
+// activation whose output can be read as the probability of class 1 (a threshold of 0.5 is the usual choice).
+func (n *NanoNeuron) Classify(x float64, threshold float64) int {
+	if n.predict(x) > threshold {
+		return 1
+	}
+	return 0
+}
+
+// Accuracy is the fraction of examples whose 0/1 label in 'y' matches the class predicted by Classify.
+func Accuracy(model *NanoNeuron, x, y []float64, threshold float64) float64 {
+	if len(x) == 0 {
+		return 0
+	}
+	correct := 0
+	for i := range x {
+		if float64(model.Classify(x[i], threshold)) == y[i] {
+			correct++
+		}
+	}
+	return float64(correct) / float64(len(x))
+}
+
+// ArgMax picks the class of a multiclass classification: the index of the highest of the
+// 'outputs', i.e. the predictions of one model per class. Ties are resolved deterministically
+// in favour of the lowest index. NaN outputs never win; without any number (an empty or an all
+// NaN slice) it returns -1.
+func ArgMax(outputs []float64) int {
+	best := -1
+	for i, v := range outputs {
+		if !math.IsNaN(v) && (best < 0 || v > outputs[best]) {
+			best = i
+		}
+	}
+	return best
+}
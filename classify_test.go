@@ -0,0 +1,54 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestClassifySeparableData(t *testing.T) {
+	// The class is 1 above x = 1.5, which a sigmoid of a line separates perfectly.
+	var x, y []float64
+	for i := -50; i < 50; i++ {
+		x = append(x, float64(i)/4)
+		if float64(i)/4 > 1.5 {
+			y = append(y, 1)
+		} else {
+			y = append(y, 0)
+		}
+	}
+	rng := NewRandSource(1)
+	model := &NanoNeuron{w: rng.Float64(), b: rng.Float64(), activation: Sigmoid{}}
+	trainModel(model, 20000, 0.5, x, y, TrainOptions{})
+	if accuracy := Accuracy(model, x, y, 0.5); accuracy != 1 {
+		t.Errorf("accuracy %v, want 1 (w=%v b=%v)", accuracy, model.w, model.b)
+	}
+	if model.Classify(-100, 0.5) != 0 || model.Classify(100, 0.5) != 1 {
+		t.Error("the far ends are classified the wrong way")
+	}
+
+	if accuracy := Accuracy(&NanoNeuron{w: -1, activation: Sigmoid{}}, x, y, 0.5); accuracy >= 0.5 {
+		t.Errorf("the flipped model has accuracy %v, want below 0.5", accuracy)
+	}
+	if accuracy := Accuracy(model, nil, nil, 0.5); accuracy != 0 {
+		t.Errorf("accuracy %v without examples, want 0", accuracy)
+	}
+}
+
+func TestArgMax(t *testing.T) {
+	nan := math.NaN()
+	for _, c := range []struct {
+		outputs []float64
+		want    int
+	}{
+		{[]float64{0.1, 0.7, 0.2}, 1},
+		{[]float64{0.3, 0.9, 0.9, 0.1}, 1},
+		{[]float64{-2, -2}, 0},
+		{[]float64{nan, 0.5, nan}, 1},
+		{[]float64{nan}, -1},
+		{nil, -1},
+	} {
+		if got := ArgMax(c.outputs); got != c.want {
+			t.Errorf("ArgMax(%v) = %d, want %d", c.outputs, got, c.want)
+		}
+	}
+}
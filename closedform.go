@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// FitClosedForm finds the exact least-squares 'w' and 'b' without any training.
+// Linear regression has a closed-form solution (the normal equations):
+// w = cov(x, y) / var(x), b = mean(y) - w * mean(x).
+// It's handy to compare what gradient descent has learned against the true optimum.
+// The solution doesn't exist when all the 'x' values are the same.
+func FitClosedForm(x, y []float64) (*NanoNeuron, error) {
+	if err := validateDataSet(x, y); err != nil {
+		return nil, fmt.Errorf("closed-form fit: %w", err)
+	}
+	xMean, yMean := mean(x), mean(y)
+	covariance, variance := 0.0, 0.0
+	for i := range x {
+		covariance += (x[i] - xMean) * (y[i] - yMean)
+		variance += (x[i] - xMean) * (x[i] - xMean)
+	}
+	if variance == 0 {
+		return nil, fmt.Errorf("closed-form fit: %w in x", ErrZeroVariance)
+	}
+	w := covariance / variance
+	return &NanoNeuron{w: w, b: yMean - w*xMean}, nil
+}
+
+// ParameterError is the absolute difference between the model parameters and
+// the closed-form optimum for the same data. Values close to zero mean that
+// gradient descent has really converged. Both errors are NaN when the optimum
+// can't be computed (see FitClosedForm).
+func ParameterError(model *NanoNeuron, x, y []float64) (dwErr, dbErr float64) {
+	optimum, err := FitClosedForm(x, y)
+	if err != nil {
+		return math.NaN(), math.NaN()
+	}
+	return math.Abs(model.w - optimum.w), math.Abs(model.b - optimum.b)
+}
+
+// Hessian is the matrix of the second derivatives of the cost of the plain linear model on
+// the data-set by its parameters, [[d²/dw², d²/dw db], [d²/db dw, d²/db²]]. The cost is
+// quadratic in 'w' and 'b', so the Hessian is the same everywhere and depends on the inputs
+// only: 2 * costScale * [[mean(x²), mean(x)], [mean(x), 1]]. 'y' must pair up with 'x'.
+func Hessian(x, y []float64) [2][2]float64 {
+	mustMatch("hessian", x, y)
+	return inputHessian(x)
+}
+
+// inputHessian is the Hessian of the cost on the inputs 'x', whatever their labels are.
+func inputHessian(x []float64) [2][2]float64 {
+	var xMean, xSquares float64
+	for _, v := range x {
+		xMean += v / float64(len(x))
+		xSquares += v * v / float64(len(x))
+	}
+	return [2][2]float64{
+		{2 * costScale * xSquares, 2 * costScale * xMean},
+		{2 * costScale * xMean, 2 * costScale},
+	}
+}
+
+// OptimalLearningRate is the largest learning rate for which the full-batch gradient descent
+// on the inputs 'x' is stable: 2 / the largest eigenvalue of the Hessian. Above it the steps
+// along the steepest direction of the cost overshoot more than they gain and the training
+// diverges; set alpha somewhat below it. Half of it converges the fastest along that direction,
+// the flattest one may still need many epochs. It is NaN for no inputs.
+func OptimalLearningRate(x []float64) float64 {
+	if len(x) == 0 {
+		return math.NaN()
+	}
+	h := inputHessian(x)
+	// The eigenvalues of the symmetric 2x2 matrix are mid ± radius.
+	mid := (h[0][0] + h[1][1]) / 2
+	radius := math.Hypot((h[0][0]-h[1][1])/2, h[0][1])
+	return 2 / (mid + radius)
+}
+
+// TrainNewton trains the plain linear model with Newton's method: every step moves the
+// parameters by the inverse Hessian times the gradient. Because the cost is quadratic the
+// first step already lands exactly on the optimum of FitClosedForm, the other steps only
+// confirm it. The result is reported like by Train, with the cost before every step.
+// Newton's method needs the Hessian to be invertible: ErrZeroVariance is returned when all
+// the inputs are the same. Models with an activation or an output transform aren't quadratic
+// and are rejected with ErrInvalidInput.
+func TrainNewton(model *NanoNeuron, x, y []float64, steps int) (*TrainingResult, error) {
+	if err := validateDataSet(x, y); err != nil {
+		return nil, fmt.Errorf("newton: %w", err)
+	}
+	if model.activation != nil || model.output != nil {
+		return nil, fmt.Errorf("newton: %w: the cost of %v isn't quadratic", ErrInvalidInput, model)
+	}
+	if stdDev(x, mean(x)) == 0 {
+		return nil, fmt.Errorf("newton: %w in x, the Hessian isn't invertible", ErrZeroVariance)
+	}
+	h := Hessian(x, y)
+	det := h[0][0]*h[1][1] - h[0][1]*h[1][0]
+	predictions, initialCost := forwardPropagation(model, x, y)
+	result := &TrainingResult{InitialCost: initialCost}
+	for step := 0; step < steps; step++ {
+		cost := forwardPropagationInto(model, x, y, predictions)
+		dW, dB := backwardPropagation(model, predictions, x, y)
+		// The gradient points downhill already, so the Newton step is added.
+		model.w += (h[1][1]*dW - h[0][1]*dB) / det
+		model.b += (h[0][0]*dB - h[1][0]*dW) / det
+		result.CostHistory = append(result.CostHistory, cost)
+		result.GradNormHistory = append(result.GradNormHistory, math.Hypot(dW, dB))
+	}
+	final := *model
+	result.Final = &final
+	return result, nil
+}
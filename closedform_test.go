@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestFitClosedFormCelsius(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	model, err := FitClosedForm(x, y)
+	if err != nil {
+		t.Fatalf("FitClosedForm: %v", err)
+	}
+	if math.Abs(model.w-1.8) > 1e-12 || math.Abs(model.b-32) > 1e-10 {
+		t.Errorf("got w=%v b=%v, want w=1.8 b=32", model.w, model.b)
+	}
+	if _, err := FitClosedForm([]float64{3, 3, 3}, []float64{1, 2, 3}); !errors.Is(err, ErrZeroVariance) {
+		t.Errorf("constant inputs: got %v, want ErrZeroVariance", err)
+	}
+}
+
+func TestParameterErrorAfterConvergence(t *testing.T) {
+	x, y := generateDataSets(0, 2, 0, NewRandSource(1))
+	model := NewNanoNeuron(NewRandSource(1))
+	dwBefore, dbBefore := ParameterError(model, x, y)
+	trainModel(model, 200000, 0.0005, x, y, TrainOptions{})
+	dwErr, dbErr := ParameterError(model, x, y)
+	if dwErr > 1e-6 || dbErr > 1e-4 {
+		t.Errorf("parameter errors %v and %v after the training, want them close to zero", dwErr, dbErr)
+	}
+	if dwErr >= dwBefore || dbErr >= dbBefore {
+		t.Errorf("the errors grew from %v and %v to %v and %v", dwBefore, dbBefore, dwErr, dbErr)
+	}
+	if dw, db := ParameterError(model, []float64{1, 1}, []float64{2, 3}); !math.IsNaN(dw) || !math.IsNaN(db) {
+		t.Errorf("got %v and %v without an optimum, want NaN", dw, db)
+	}
+}
+
+func TestTrainNewtonReachesTheOptimumInOneStep(t *testing.T) {
+	x, y := generateDataSets(0, 2, 0, NewRandSource(1))
+	h := Hessian(x, y)
+	// Twice costScale times mean(x²) = 3283.5, mean(x) = 49.5 and 1 of the inputs 0..99.
+	if want := [2][2]float64{{3283.5, 49.5}, {49.5, 1}}; math.Abs(h[0][0]-want[0][0]) > 1e-9 || h[0][1] != want[0][1] || h[1][0] != want[1][0] || h[1][1] != want[1][1] {
+		t.Errorf("Hessian %v, want %v", h, want)
+	}
+
+	optimum, err := FitClosedForm(x, y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	model := NewNanoNeuron(NewRandSource(1))
+	result, err := TrainNewton(model, x, y, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(model.w-optimum.w) > 1e-9 || math.Abs(model.b-optimum.b) > 1e-7 {
+		t.Errorf("one Newton step reached w=%v b=%v, the optimum is w=%v b=%v", model.w, model.b, optimum.w, optimum.b)
+	}
+	if len(result.CostHistory) != 1 || result.CostHistory[0] != result.InitialCost || *result.Final != *model {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	if _, err := TrainNewton(&NanoNeuron{}, []float64{0.1, 0.1}, []float64{1, 2}, 1); !errors.Is(err, ErrZeroVariance) {
+		t.Errorf("constant inputs: got %v, want ErrZeroVariance", err)
+	}
+	if _, err := TrainNewton(&NanoNeuron{activation: Sigmoid{}}, x, y, 1); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("a sigmoid model: got %v, want ErrInvalidInput", err)
+	}
+}
+
+func TestOptimalLearningRate(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	rate := OptimalLearningRate(x)
+	if !(rate > 0.0005 && rate < 0.001) {
+		t.Fatalf("the optimal learning rate of the Celsius data is %v", rate)
+	}
+
+	below := trainModel(NewNanoNeuron(NewRandSource(1)), 5000, 0.95*rate, x, y, TrainOptions{})
+	if cost := below.FinalCost(); !(cost < below.CostHistory[0]) || !isFinite(cost) {
+		t.Errorf("just below the rate the cost went from %v to %v", below.CostHistory[0], cost)
+	}
+	above := trainModel(NewNanoNeuron(NewRandSource(1)), 5000, 1.05*rate, x, y, TrainOptions{})
+	if cost := above.FinalCost(); isFinite(cost) && cost < 1e100 {
+		t.Errorf("just above the rate the cost went from %v to %v", above.CostHistory[0], cost)
+	}
+	if rate := OptimalLearningRate(nil); !math.IsNaN(rate) {
+		t.Errorf("the rate without inputs is %v", rate)
+	}
+}
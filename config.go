@@ -0,0 +1,213 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// TrainingConfig is the declarative description of a whole training: the model, the optimizer,
+// the learning rate schedule and the cost function are all named in it, so a script can keep
+// it in a JSON file (see LoadTrainingConfig) instead of in code. Empty fields use the defaults:
+// a linear model, the plain gradient descent, the constant 'alpha' and the squared error.
+type TrainingConfig struct {
+	Epochs int     `json:"epochs"`
+	Alpha  float64 `json:"alpha"`
+	// Seed is the seed of the random initialization of 'w' and 'b'.
+	Seed int64 `json:"seed"`
+	// Activation is one of "", "sigmoid", "tanh", "softplus", "leaky_relu" and "elu"; the last two take
+	// their alpha from ActivationAlpha.
+	Activation      string  `json:"activation,omitempty"`
+	ActivationAlpha float64 `json:"activation_alpha,omitempty"`
+	// Cost is the name of a registered cost function (see RegisterCostFunction), i.e. "absolute".
+	// Empty is the squared error of predictionCost.
+	Cost      string           `json:"cost,omitempty"`
+	Optimizer *OptimizerConfig `json:"optimizer,omitempty"`
+	Schedule  *ScheduleConfig  `json:"schedule,omitempty"`
+	BatchSize int              `json:"batch_size,omitempty"`
+}
+
+// OptimizerConfig names an Optimizer and its settings. Kind "momentum" is Momentum with Beta.
+type OptimizerConfig struct {
+	Kind string  `json:"kind"`
+	Beta float64 `json:"beta,omitempty"`
+}
+
+// ScheduleConfig names a LearningRateSchedule and its settings: Kind "cyclical" is Cyclical
+// (BaseLR, MaxLR, StepSize) and "cosine" is CosineAnnealing (MaxLR, MinLR, Epochs, Restart).
+type ScheduleConfig struct {
+	Kind     string  `json:"kind"`
+	BaseLR   float64 `json:"base_lr,omitempty"`
+	MaxLR    float64 `json:"max_lr,omitempty"`
+	MinLR    float64 `json:"min_lr,omitempty"`
+	StepSize int     `json:"step_size,omitempty"`
+	Epochs   int     `json:"epochs,omitempty"`
+	Restart  int     `json:"restart,omitempty"`
+}
+
+// LoadTrainingConfig reads a TrainingConfig from JSON. Unknown fields are reported as errors,
+// so a typo doesn't silently fall back to a default.
+func LoadTrainingConfig(r io.Reader) (TrainingConfig, error) {
+	var cfg TrainingConfig
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&cfg); err != nil {
+		return TrainingConfig{}, fmt.Errorf("training config: %w", err)
+	}
+	return cfg, nil
+}
+
+// The environment variables read by ConfigFromEnv.
+const (
+	envEpochs = "NN_EPOCHS"
+	envAlpha  = "NN_ALPHA"
+	envSeed   = "NN_SEED"
+)
+
+// The hyperparameters of the demo training, the defaults of ConfigFromEnv.
+const (
+	demoEpochs = 70000
+	demoAlpha  = 0.0005
+)
+
+// ConfigFromEnv reads the hyperparameters of the demo training from the environment, for a
+// containerized deployment: NN_EPOCHS (a positive integer), NN_ALPHA (a positive number) and
+// NN_SEED (an integer). Unset variables keep the defaults of the demo: 70000 epochs, alpha
+// 0.0005 and the seed of the -deterministic mode. The flags of main override the environment
+// (see demoConfig). Invalid values are reported as ErrInvalidHyperparameter naming the variable.
+func ConfigFromEnv() (TrainingConfig, error) {
+	return configFromEnv(nil)
+}
+
+// configFromEnv is ConfigFromEnv that doesn't read the variables in 'skip', they keep the defaults.
+func configFromEnv(skip map[string]bool) (TrainingConfig, error) {
+	cfg := TrainingConfig{Epochs: demoEpochs, Alpha: demoAlpha, Seed: deterministicSeed}
+	lookup := func(name string) (string, bool) {
+		if skip[name] {
+			return "", false
+		}
+		return os.LookupEnv(name)
+	}
+	if v, ok := lookup(envEpochs); ok {
+		epochs, err := strconv.Atoi(v)
+		if err != nil || epochs < 1 {
+			return TrainingConfig{}, fmt.Errorf("%w: %s=%q is not a positive integer", ErrInvalidHyperparameter, envEpochs, v)
+		}
+		cfg.Epochs = epochs
+	}
+	if v, ok := lookup(envAlpha); ok {
+		alpha, err := strconv.ParseFloat(v, 64)
+		if err != nil || !isFinite(alpha) || alpha <= 0 {
+			return TrainingConfig{}, fmt.Errorf("%w: %s=%q is not a positive number", ErrInvalidHyperparameter, envAlpha, v)
+		}
+		cfg.Alpha = alpha
+	}
+	if v, ok := lookup(envSeed); ok {
+		seed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return TrainingConfig{}, fmt.Errorf("%w: %s=%q is not an integer", ErrInvalidHyperparameter, envSeed, v)
+		}
+		cfg.Seed = seed
+	}
+	return cfg, nil
+}
+
+// demoConfig resolves the hyperparameters of the demo after the flags 'fs' were parsed: the
+// -epochs, -alpha and -seed flags set on the command line win with their values in 'flags',
+// the others come from the environment. The variables of the set flags aren't even read,
+// so an invalid one can't stop a run that overrides it.
+func demoConfig(fs *flag.FlagSet, flags TrainingConfig) (TrainingConfig, error) {
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	cfg, err := configFromEnv(map[string]bool{envEpochs: set["epochs"], envAlpha: set["alpha"], envSeed: set["seed"]})
+	if err != nil {
+		return TrainingConfig{}, err
+	}
+	if set["epochs"] {
+		cfg.Epochs = flags.Epochs
+	}
+	if set["alpha"] {
+		cfg.Alpha = flags.Alpha
+	}
+	if set["seed"] {
+		cfg.Seed = flags.Seed
+	}
+	return cfg, nil
+}
+
+// TrainFromConfig is the single declarative entry point to the training: it creates the model
+// and everything the training needs from 'cfg' and trains the model on the data-set with Train.
+// Unknown names in 'cfg' are reported as ErrInvalidHyperparameter. Like with Train the model
+// and the result are valid even together with ErrDiverged or ErrStalled. The result carries the
+// DataHash of the data-set for AuditRun.
+func TrainFromConfig(cfg TrainingConfig, x, y []float64) (*NanoNeuron, *TrainingResult, error) {
+	model, err := cfg.model()
+	if err != nil {
+		return nil, nil, err
+	}
+	opts, err := cfg.options()
+	if err != nil {
+		return nil, nil, err
+	}
+	result, err := Train(model, x, y, cfg.Epochs, cfg.Alpha, opts)
+	if result == nil {
+		return nil, nil, err
+	}
+	result.DataHash = dataHash(x, y)
+	return model, result, err
+}
+
+// model creates the untrained model of the config.
+func (cfg TrainingConfig) model() (*NanoNeuron, error) {
+	model := NewNanoNeuron(NewRandSource(cfg.Seed))
+	switch cfg.Activation {
+	case "":
+	case "sigmoid":
+		model.activation = Sigmoid{}
+	case "tanh":
+		model.activation = Tanh{}
+	case "softplus":
+		model.activation = Softplus{}
+	case "leaky_relu":
+		model.activation = LeakyReLU(cfg.ActivationAlpha)
+	case "elu":
+		model.activation = ELU(cfg.ActivationAlpha)
+	default:
+		return nil, fmt.Errorf("%w: unknown activation %q", ErrInvalidHyperparameter, cfg.Activation)
+	}
+	return model, nil
+}
+
+// options translates the config into the TrainOptions of its training.
+func (cfg TrainingConfig) options() (TrainOptions, error) {
+	opts := TrainOptions{BatchSize: cfg.BatchSize}
+	if cfg.Cost != "" {
+		cost, err := CostFunctionByName(cfg.Cost)
+		if err != nil {
+			return TrainOptions{}, err
+		}
+		opts.CostProvider = func(int) CostFunction { return cost }
+	}
+	if cfg.Optimizer != nil {
+		switch cfg.Optimizer.Kind {
+		case "momentum":
+			opts.Optimizer = NewMomentum(cfg.Optimizer.Beta)
+		default:
+			return TrainOptions{}, fmt.Errorf("%w: unknown optimizer %q", ErrInvalidHyperparameter, cfg.Optimizer.Kind)
+		}
+	}
+	if s := cfg.Schedule; s != nil {
+		switch s.Kind {
+		case "cyclical":
+			opts.Schedule = Cyclical{BaseLR: s.BaseLR, MaxLR: s.MaxLR, StepSize: s.StepSize}
+		case "cosine":
+			opts.Schedule = CosineAnnealing{MaxLR: s.MaxLR, MinLR: s.MinLR, Epochs: s.Epochs, Restart: s.Restart}
+		default:
+			return TrainOptions{}, fmt.Errorf("%w: unknown schedule %q", ErrInvalidHyperparameter, s.Kind)
+		}
+	}
+	return opts, nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestTrainFromConfig(t *testing.T) {
+	cfg, err := LoadTrainingConfig(strings.NewReader(`{
+		"epochs": 2000,
+		"alpha": 0.0001,
+		"seed": 1,
+		"cost": "absolute",
+		"optimizer": {"kind": "momentum", "beta": 0.9}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts, err := cfg.options()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if momentum, ok := opts.Optimizer.(*Momentum); !ok || momentum.Beta != 0.9 {
+		t.Errorf("optimizer %#v, want Momentum with Beta 0.9", opts.Optimizer)
+	}
+	if opts.CostProvider == nil || opts.CostProvider(0) != (AbsoluteError{}) {
+		t.Error("the cost isn't the absolute error")
+	}
+
+	x, y := generateDataSets(0, 0, 0, nil)
+	model, result, err := TrainFromConfig(cfg, x, y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := NewNanoNeuron(NewRandSource(1))
+	if _, err := Train(want, x, y, 2000, 0.0001, TrainOptions{
+		Optimizer:    NewMomentum(0.9),
+		CostProvider: func(int) CostFunction { return AbsoluteError{} },
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if *model != *want || len(result.CostHistory) != 2000 {
+		t.Errorf("trained %v in %d epochs, want %v in 2000", model, len(result.CostHistory), want)
+	}
+
+	for _, input := range []string{`{"epochs": 10, "alpha": 0.1, "cost": "huber"}`, `{"epochs": 10, "alpha": 0.1, "optimizer": {"kind": "adam"}}`} {
+		cfg, err := LoadTrainingConfig(strings.NewReader(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := TrainFromConfig(cfg, x, y); !errors.Is(err, ErrInvalidHyperparameter) {
+			t.Errorf("%s: got %v, want ErrInvalidHyperparameter", input, err)
+		}
+	}
+	if _, err := LoadTrainingConfig(strings.NewReader(`{"epoch": 10}`)); err == nil {
+		t.Error("the misspelled field was accepted")
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	for _, name := range []string{envEpochs, envAlpha, envSeed} {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (TrainingConfig{Epochs: 70000, Alpha: 0.0005, Seed: deterministicSeed}); cfg != want {
+		t.Errorf("without the variables got %+v, want the demo defaults %+v", cfg, want)
+	}
+
+	t.Setenv(envEpochs, "1500")
+	t.Setenv(envAlpha, "0.001")
+	t.Setenv(envSeed, "-7")
+	cfg, err = ConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (TrainingConfig{Epochs: 1500, Alpha: 0.001, Seed: -7}); cfg != want {
+		t.Errorf("got %+v, want %+v", cfg, want)
+	}
+
+	for _, test := range []struct{ name, value string }{
+		{envEpochs, "0"},
+		{envEpochs, "many"},
+		{envAlpha, "-0.1"},
+		{envAlpha, "NaN"},
+		{envSeed, "1.5"},
+	} {
+		t.Run(test.name+"="+test.value, func(t *testing.T) {
+			t.Setenv(test.name, test.value)
+			if _, err := ConfigFromEnv(); !errors.Is(err, ErrInvalidHyperparameter) || !strings.Contains(err.Error(), test.name) {
+				t.Errorf("got %v, want ErrInvalidHyperparameter naming %s", err, test.name)
+			}
+		})
+	}
+}
+
+func TestDemoConfigFlagsOverrideTheEnvironment(t *testing.T) {
+	t.Setenv(envEpochs, "1500")
+	t.Setenv(envAlpha, "bogus")
+	t.Setenv(envSeed, "7")
+	fs := flag.NewFlagSet("demo", flag.ContinueOnError)
+	alpha := fs.Float64("alpha", demoAlpha, "")
+	if err := fs.Parse([]string{"-alpha", "0.001"}); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := demoConfig(fs, TrainingConfig{Alpha: *alpha})
+	if err != nil {
+		t.Fatalf("the valid -alpha didn't override the invalid %s: %v", envAlpha, err)
+	}
+	if want := (TrainingConfig{Epochs: 1500, Alpha: 0.001, Seed: 7}); cfg != want {
+		t.Errorf("got %+v, want %+v", cfg, want)
+	}
+
+	if _, err := demoConfig(flag.NewFlagSet("demo", flag.ContinueOnError), TrainingConfig{}); !errors.Is(err, ErrInvalidHyperparameter) {
+		t.Errorf("without the -alpha flag got %v, want ErrInvalidHyperparameter", err)
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sync"
+)
+
+// CostFunction measures how wrong the predictions are and tells the backward propagation
+// in which direction to move them.
+type CostFunction interface {
+	// Cost is the cost of all the 'predictions' for the correct labels 'y'.
+	Cost(y, predictions []float64) float64
+	// Signals returns the error signal of every example: the negative derivative of Cost by that
+	// example's prediction, multiplied by the number of examples (the backward propagation
+	// averages over the examples). For the squared error it is 2 * costScale * (y - prediction).
+	Signals(y, predictions []float64) []float64
+}
+
+// SquaredError is the default cost: the average of (y - prediction) ^ 2 * costScale, see predictionCost.
+type SquaredError struct{}
+
+// Cost implements CostFunction.
+func (SquaredError) Cost(y, predictions []float64) float64 {
+	cost := 0.0
+	for i := range y {
+		cost += predictionCost(y[i], predictions[i])
+	}
+	return cost / float64(len(y))
+}
+
+// Signals implements CostFunction.
+func (SquaredError) Signals(y, predictions []float64) []float64 {
+	signals := make([]float64, len(y))
+	for i := range y {
+		signals[i] = 2 * costScale * (y[i] - predictions[i])
+	}
+	return signals
+}
+
+// AbsoluteError is the mean absolute error: the average of |y - prediction|.
+// Every example pulls with the same strength no matter how far it is, so outliers
+// distort the fit less than with the squared error.
+type AbsoluteError struct{}
+
+// Cost implements CostFunction.
+func (AbsoluteError) Cost(y, predictions []float64) float64 {
+	cost := 0.0
+	for i := range y {
+		cost += math.Abs(y[i] - predictions[i])
+	}
+	return cost / float64(len(y))
+}
+
+// Signals implements CostFunction.
+func (AbsoluteError) Signals(y, predictions []float64) []float64 {
+	signals := make([]float64, len(y))
+	for i := range y {
+		signals[i] = sign(y[i] - predictions[i])
+	}
+	return signals
+}
+
+// Huber is the squared error for the residuals up to Delta and the absolute error beyond:
+// costScale * r^2 near the labels and 2 * costScale * Delta * (|r| - Delta / 2) far from them, so it
+// is smooth at the minimum like the squared error while the outliers pull only with a constant
+// strength like with the absolute error. It isn't registered by name, because it only makes sense with a Delta.
+type Huber struct {
+	Delta float64 // the residual where the squared error turns into the absolute one, i.e. 1
+}
+
+// Cost implements CostFunction.
+func (h Huber) Cost(y, predictions []float64) float64 {
+	cost := 0.0
+	for i := range y {
+		if r := math.Abs(y[i] - predictions[i]); r <= h.Delta {
+			cost += costScale * r * r
+		} else {
+			cost += 2 * costScale * h.Delta * (r - h.Delta/2)
+		}
+	}
+	return cost / float64(len(y))
+}
+
+// Signals implements CostFunction.
+func (h Huber) Signals(y, predictions []float64) []float64 {
+	signals := make([]float64, len(y))
+	for i := range y {
+		r := y[i] - predictions[i]
+		signals[i] = 2 * costScale * math.Max(-h.Delta, math.Min(h.Delta, r))
+	}
+	return signals
+}
+
+// MaxError is the L-infinity cost: the largest residual max|y - prediction| of the batch.
+// Minimizing it fits the worst example instead of the average one. Its (sub)gradient only
+// comes from the single worst example of each batch, so the cost is non-smooth and the
+// training moves slowly and may zigzag between the extreme examples.
+type MaxError struct{}
+
+// Cost implements CostFunction.
+func (MaxError) Cost(y, predictions []float64) float64 {
+	worst := 0.0
+	for i := range y {
+		worst = math.Max(worst, math.Abs(y[i]-predictions[i]))
+	}
+	return worst
+}
+
+// Signals implements CostFunction.
+func (MaxError) Signals(y, predictions []float64) []float64 {
+	signals := make([]float64, len(y))
+	worst := -1
+	for i := range y {
+		if worst < 0 || math.Abs(y[i]-predictions[i]) > math.Abs(y[worst]-predictions[worst]) {
+			worst = i
+		}
+	}
+	if worst >= 0 {
+		// The cost isn't averaged, so undo the averaging of the backward propagation.
+		signals[worst] = sign(y[worst]-predictions[worst]) * float64(len(y))
+	}
+	return signals
+}
+
+// costFunctions are the cost functions known by name, see RegisterCostFunction.
+var (
+	costFunctionsMu sync.RWMutex
+	costFunctions   = map[string]CostFunction{
+		"squared":  SquaredError{},
+		"absolute": AbsoluteError{},
+		"max":      MaxError{},
+	}
+)
+
+// RegisterCostFunction makes 'cf' known as 'name', so it can be picked by name, i.e. in a
+// TrainingConfig. The built-in "squared", "absolute" and "max" are registered from the start.
+// Registering a name again replaces its cost function. It panics if 'cf' is nil.
+func RegisterCostFunction(name string, cf CostFunction) {
+	if cf == nil {
+		panic("RegisterCostFunction: nil cost function " + name)
+	}
+	costFunctionsMu.Lock()
+	defer costFunctionsMu.Unlock()
+	costFunctions[name] = cf
+}
+
+// CostFunctionByName returns the cost function registered as 'name'.
+// An unknown name is reported as ErrInvalidHyperparameter.
+func CostFunctionByName(name string) (CostFunction, error) {
+	costFunctionsMu.RLock()
+	defer costFunctionsMu.RUnlock()
+	cf, ok := costFunctions[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown cost function %q", ErrInvalidHyperparameter, name)
+	}
+	return cf, nil
+}
+
+func sign(v float64) float64 {
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	}
+	return 0
+}
+
+// backwardPropagationCost is backwardPropagation for any CostFunction.
+func backwardPropagationCost(model *NanoNeuron, cost CostFunction, predictions, xTrain, yTrain []float64) (float64, float64) {
+	mustMatch("backward propagation", xTrain, yTrain)
+	mustMatch("backward propagation", xTrain, predictions)
+	m := len(xTrain)
+	signals := cost.Signals(yTrain, predictions)
+	dW := 0.0
+	dB := 0.0
+	for i := 0; i < m; i++ {
+		delta := model.chainRule(xTrain[i], signals[i])
+		dW += delta * xTrain[i]
+		dB += delta
+	}
+	dW /= float64(m)
+	dB /= float64(m)
+	return dW, dB
+}
+
+// finiteExamples drops the examples whose prediction cost isn't a finite number.
+// It returns the remaining examples and the number of dropped ones.
+func finiteExamples(x, y, predictions []float64) ([]float64, []float64, []float64, int) {
+	keep := 0
+	for i := range x {
+		if isFinite(predictionCost(y[i], predictions[i])) {
+			keep++
+		}
+	}
+	if keep == len(x) {
+		return x, y, predictions, 0
+	}
+	xf := make([]float64, 0, keep)
+	yf := make([]float64, 0, keep)
+	pf := make([]float64, 0, keep)
+	for i := range x {
+		if isFinite(predictionCost(y[i], predictions[i])) {
+			xf = append(xf, x[i])
+			yf = append(yf, y[i])
+			pf = append(pf, predictions[i])
+		}
+	}
+	return xf, yf, pf, len(x) - keep
+}
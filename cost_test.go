@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestMaxErrorShrinksTheWorstResidual(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	model := NewNanoNeuron(NewRandSource(deterministicSeed))
+	worst := MaxError{}.Cost(y, model.PredictBatch(x))
+	result := trainModel(model, 5000, 0.0005, x, y, TrainOptions{
+		CostProvider: func(int) CostFunction { return MaxError{} },
+	})
+	if got := (MaxError{}).Cost(y, model.PredictBatch(x)); !(got < worst/2) {
+		t.Errorf("the max residual went from %v to %v", worst, got)
+	}
+	if result.InitialCost != worst {
+		t.Errorf("initial cost %v, want the max residual %v", result.InitialCost, worst)
+	}
+}
+
+func TestHuberIsSquaredNearAndAbsoluteFar(t *testing.T) {
+	h := Huber{Delta: 2}
+	y := []float64{0, 0, 0}
+	predictions := []float64{1, -2, 10}
+	// 0.5 * 1^2, 0.5 * 2^2 and 2 * (10 - 1).
+	if got, want := h.Cost(y, predictions), (0.5+2+18)/3; math.Abs(got-want) > 1e-12 {
+		t.Errorf("cost %v, want %v", got, want)
+	}
+	if got := h.Signals(y, predictions); got[0] != -1 || got[1] != 2 || got[2] != -2 {
+		t.Errorf("signals %v, want [-1 2 -2]", got)
+	}
+}
+
+// logCoshError is a custom cost: the average of log(cosh(y - prediction)).
+type logCoshError struct{}
+
+func (logCoshError) Cost(y, predictions []float64) float64 {
+	cost := 0.0
+	for i := range y {
+		cost += math.Log(math.Cosh(y[i] - predictions[i]))
+	}
+	return cost / float64(len(y))
+}
+
+func (logCoshError) Signals(y, predictions []float64) []float64 {
+	signals := make([]float64, len(y))
+	for i := range y {
+		signals[i] = math.Tanh(y[i] - predictions[i])
+	}
+	return signals
+}
+
+func TestCostFunctionRegistry(t *testing.T) {
+	if cf, err := CostFunctionByName("absolute"); err != nil || cf != (AbsoluteError{}) {
+		t.Errorf("got %v, %v for the built-in absolute error", cf, err)
+	}
+	if _, err := CostFunctionByName("log_cosh"); !errors.Is(err, ErrInvalidHyperparameter) {
+		t.Errorf("got %v for an unregistered name, want ErrInvalidHyperparameter", err)
+	}
+	RegisterCostFunction("log_cosh", logCoshError{})
+	if cf, err := CostFunctionByName("log_cosh"); err != nil || cf != (logCoshError{}) {
+		t.Errorf("got %v, %v for the registered log-cosh error", cf, err)
+	}
+
+	x, y := generateDataSets(0, 0, 0, nil)
+	model, result, err := TrainFromConfig(TrainingConfig{Epochs: 100, Alpha: 0.0005, Seed: 1, Cost: "log_cosh"}, x, y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := NewNanoNeuron(NewRandSource(1))
+	trainModel(want, 100, 0.0005, x, y, TrainOptions{CostProvider: func(int) CostFunction { return logCoshError{} }})
+	if *model != *want || result.InitialCost != (logCoshError{}).Cost(y, NewNanoNeuron(NewRandSource(1)).PredictBatch(x)) {
+		t.Errorf("the config trained %v, the log-cosh error %v", model, want)
+	}
+}
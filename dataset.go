@@ -0,0 +1,255 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+)
+
+// Stats describes a single column of a data-set.
+type Stats struct {
+	Min  float64
+	Max  float64
+	Mean float64
+	Std  float64 // population standard deviation
+}
+
+// DataSummary is a quick look at a data-set before training.
+type DataSummary struct {
+	X Stats
+	Y Stats
+	// Correlation is the Pearson correlation between 'x' and 'y'.
+	// Values close to 1 or -1 mean that a straight line (a NanoNeuron) fits the data well.
+	// It is NaN when one of the columns is constant.
+	Correlation float64
+}
+
+// DescribeDataSet returns min/max/mean/std of both columns and their correlation.
+// It helps to spot scaling issues and to see if a linear fit is appropriate at all.
+// The columns must be of the same length.
+func DescribeDataSet(x, y []float64) DataSummary {
+	mustMatch("describe data-set", x, y)
+	summary := DataSummary{
+		X: describe(x),
+		Y: describe(y),
+	}
+	n := len(x)
+	if n == 0 || summary.X.Std == 0 || summary.Y.Std == 0 {
+		summary.Correlation = math.NaN()
+		return summary
+	}
+	covariance := 0.0
+	for i := 0; i < n; i++ {
+		covariance += (x[i] - summary.X.Mean) * (y[i] - summary.Y.Mean)
+	}
+	covariance /= float64(n)
+	summary.Correlation = covariance / (summary.X.Std * summary.Y.Std)
+	return summary
+}
+
+func describe(values []float64) Stats {
+	if len(values) == 0 {
+		return Stats{}
+	}
+	s := Stats{
+		Min:  values[0],
+		Max:  values[0],
+		Mean: mean(values),
+	}
+	for _, v := range values {
+		s.Min = math.Min(s.Min, v)
+		s.Max = math.Max(s.Max, v)
+	}
+	s.Std = stdDev(values, s.Mean)
+	return s
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// stdDev is the population standard deviation of 'values' around 'm'.
+func stdDev(values []float64, m float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += (v - m) * (v - m)
+	}
+	return math.Sqrt(sum / float64(len(values)))
+}
+
+// Standardize scales 'values' to zero mean and unit standard deviation (z-scores), i.e. the
+// features of a MultiNanoNeuron, and returns the scaled copy together with the mean and the
+// standard deviation to apply to new inputs: (x - m) / std. The default, sample = false, is
+// the population standard deviation (divided by N) like in Stats; sample = true divides by N-1
+// instead, the unbiased estimate of the variance of the population the values were drawn
+// from, which is noticeably bigger for small data-sets. Constant values (and a single value
+// with 'sample') have no spread: they are only centered and std is 0.
+func Standardize(values []float64, sample bool) (scaled []float64, m, std float64) {
+	m = mean(values)
+	std = stdDev(values, m)
+	if sample {
+		std = sampleStdDev(values, m)
+	}
+	scaled = make([]float64, len(values))
+	for i, v := range values {
+		scaled[i] = v - m
+		if std != 0 {
+			scaled[i] /= std
+		}
+	}
+	return scaled, m, std
+}
+
+// sampleStdDev is the sample standard deviation of 'values' around 'm' (Bessel's correction),
+// 0 for less than two values.
+func sampleStdDev(values []float64, m float64) float64 {
+	n := len(values)
+	if n < 2 {
+		return 0
+	}
+	return stdDev(values, m) * math.Sqrt(float64(n)/float64(n-1))
+}
+
+// DataSet is a collection of labeled examples that can grow over time.
+type DataSet struct {
+	X []float64
+	Y []float64
+}
+
+// Append adds one labeled example to the data-set.
+func (d *DataSet) Append(x, y float64) {
+	d.X = append(d.X, x)
+	d.Y = append(d.Y, y)
+}
+
+// Len is the number of examples in the data-set.
+func (d *DataSet) Len() int {
+	return len(d.X)
+}
+
+// ConcatWeighted merges data-sets from sources of differing reliability into one, every
+// example weighted by the weight of its source, for the training with TrainOptions.Weights
+// (i.e. 1 for the trusted measurements and 0.2 for a noisy crowd-sourced set). The examples
+// keep the order of the sets and their order within them. It panics with ErrLengthMismatch
+// unless there is one weight per set and every set has one label per input.
+func ConcatWeighted(sets []DataSet, weights []float64) (x, y, sampleWeights []float64) {
+	if len(sets) != len(weights) {
+		panic(fmt.Errorf("concat weighted: %w: %d data-sets but %d weights", ErrLengthMismatch, len(sets), len(weights)))
+	}
+	total := 0
+	for _, set := range sets {
+		mustMatch("concat weighted", set.X, set.Y)
+		total += set.Len()
+	}
+	x = make([]float64, 0, total)
+	y = make([]float64, 0, total)
+	sampleWeights = make([]float64, 0, total)
+	for i, set := range sets {
+		x = append(x, set.X...)
+		y = append(y, set.Y...)
+		for range set.Len() {
+			sampleWeights = append(sampleWeights, weights[i])
+		}
+	}
+	return x, y, sampleWeights
+}
+
+// Dedup collapses the exactly repeated (x, y) pairs into single examples weighted by the
+// number of their occurrences, in the order they first appear. Training on them with
+// TrainOptions.Weights learns the same as training on all the rows, without repeating
+// the work for the duplicates.
+func Dedup(x, y []float64) (xu, yu []float64, weights []float64) {
+	mustMatch("dedup", x, y)
+	index := make(map[[2]uint64]int, len(x))
+	for i := range x {
+		key := [2]uint64{math.Float64bits(x[i]), math.Float64bits(y[i])}
+		if j, ok := index[key]; ok {
+			weights[j]++
+			continue
+		}
+		index[key] = len(xu)
+		xu = append(xu, x[i])
+		yu = append(yu, y[i])
+		weights = append(weights, 1)
+	}
+	return xu, yu, weights
+}
+
+// Interpolate augments a small data-set with synthetic examples: after sorting the examples
+// by 'x' it inserts factor-1 evenly spaced points on the straight segment between every two
+// consecutive examples. Examples with the same 'x' are kept as they are, with no points
+// between them (there is no segment to put them on). A factor below 2 returns the sorted copy.
+func Interpolate(x, y []float64, factor int) (xa, ya []float64) {
+	mustMatch("interpolate", x, y)
+	order := make([]int, len(x))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return x[order[i]] < x[order[j]] })
+
+	for k, i := range order {
+		if k > 0 && factor > 1 {
+			prev := order[k-1]
+			if x[i] != x[prev] {
+				for step := 1; step < factor; step++ {
+					t := float64(step) / float64(factor)
+					xa = append(xa, x[prev]+(x[i]-x[prev])*t)
+					ya = append(ya, y[prev]+(y[i]-y[prev])*t)
+				}
+			}
+		}
+		xa = append(xa, x[i])
+		ya = append(ya, y[i])
+	}
+	return xa, ya
+}
+
+// SplitOrdered splits the data-set into the first 'trainFrac' of the examples (rounded) for the
+// training and the rest for the testing, keeping their order. Unlike a random split it doesn't
+// leak the future into the past of time series like data. The parts share the memory of 'x'
+// and 'y' (appending to the training part doesn't overwrite the test part). 'trainFrac' is
+// clamped to [0, 1].
+func SplitOrdered(x, y []float64, trainFrac float64) (xTrain, yTrain, xTest, yTest []float64) {
+	mustMatch("split", x, y)
+	k := int(math.Round(math.Max(0, math.Min(1, trainFrac)) * float64(len(x))))
+	return x[:k:k], y[:k:k], x[k:], y[k:]
+}
+
+// Histogram counts the 'values' in 'bins' equally wide bins spanning [min, max], i.e. to check
+// the labels for imbalance or skew before the training. It returns the count of every bin and
+// the bins+1 edges of the bins; the last bin includes the max. When all the values are the same
+// they all go into the first bin. Values that aren't finite numbers (NaN or infinite) belong to
+// no bin, they are left out and not counted. Nil is returned for no finite values or bins < 1.
+func Histogram(values []float64, bins int) ([]int, []float64) {
+	values = slices.DeleteFunc(slices.Clone(values), func(v float64) bool { return !isFinite(v) })
+	if len(values) == 0 || bins < 1 {
+		return nil, nil
+	}
+	lo, hi := slices.Min(values), slices.Max(values)
+	width := (hi - lo) / float64(bins)
+	edges := make([]float64, bins+1)
+	for i := range edges {
+		edges[i] = lo + float64(i)*width
+	}
+	edges[bins] = hi
+	counts := make([]int, bins)
+	for _, v := range values {
+		bin := 0
+		if width > 0 {
+			bin = min(int((v-lo)/width), bins-1)
+		}
+		counts[bin]++
+	}
+	return counts, edges
+}
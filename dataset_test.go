@@ -0,0 +1,205 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestDescribeDataSetOfTheCelsiusData(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	summary := DescribeDataSet(x, y)
+	if math.Abs(summary.Correlation-1) > 1e-12 {
+		t.Errorf("correlation %v, want 1", summary.Correlation)
+	}
+	if summary.X.Min != 0 || summary.X.Max != 99 || summary.X.Mean != 49.5 {
+		t.Errorf("x stats %+v, want min 0, max 99 and mean 49.5", summary.X)
+	}
+	if summary.Y.Min != 32 || math.Abs(summary.Y.Max-210.2) > 1e-9 || math.Abs(summary.Y.Std-1.8*summary.X.Std) > 1e-9 {
+		t.Errorf("y stats %+v, want min 32, max 210.2 and 1.8 times the spread of x", summary.Y)
+	}
+}
+
+func TestDescribeDataSetRejectsMismatchedColumns(t *testing.T) {
+	defer func() {
+		err, _ := recover().(error)
+		if !errors.Is(err, ErrLengthMismatch) {
+			t.Errorf("got %v, want ErrLengthMismatch", err)
+		}
+	}()
+	DescribeDataSet([]float64{1, 2, 3}, []float64{1, 2})
+}
+
+func TestDedupTrainsLikeTheFullData(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	// Every third example occurs three times and the first one twice.
+	var xs, ys []float64
+	for i := range x {
+		repeats := 1
+		if i%3 == 0 {
+			repeats = 3
+		}
+		if i == 0 {
+			repeats = 2
+		}
+		for range repeats {
+			xs = append(xs, x[i])
+			ys = append(ys, y[i])
+		}
+	}
+	xu, yu, weights := Dedup(xs, ys)
+	if !slices.Equal(xu, x) || !slices.Equal(yu, y) {
+		t.Fatalf("deduplicated to %d examples, want the %d distinct ones in order", len(xu), len(x))
+	}
+	if weights[0] != 2 || weights[3] != 3 || weights[4] != 1 {
+		t.Errorf("got the weights %v", weights[:5])
+	}
+
+	full := NewNanoNeuron(NewRandSource(1))
+	trainModel(full, 5000, 0.0005, xs, ys, TrainOptions{})
+	weighted := NewNanoNeuron(NewRandSource(1))
+	trainModel(weighted, 5000, 0.0005, xu, yu, TrainOptions{Weights: weights})
+	if math.Abs(full.w-weighted.w) > 1e-9 || math.Abs(full.b-weighted.b) > 1e-9 {
+		t.Errorf("the weighted training got %v, the full data %v", weighted, full)
+	}
+}
+
+func TestInterpolateAddsPointsOnTheSegments(t *testing.T) {
+	// Unsorted, with the duplicate x = 2.
+	x := []float64{4, 0, 2, 2}
+	y := []float64{0, 10, 6, 8}
+	const factor = 4
+	xa, ya := Interpolate(x, y, factor)
+	// 3 segments of distinct x, of which (2, 6)-(2, 8) has none: 4 examples + 2 * 3 new points.
+	if len(xa) != 4+2*(factor-1) || len(ya) != len(xa) {
+		t.Fatalf("got %d and %d examples, want %d", len(xa), len(ya), 4+2*(factor-1))
+	}
+	wantX := []float64{0, 0.5, 1, 1.5, 2, 2, 2.5, 3, 3.5, 4}
+	wantY := []float64{10, 9, 8, 7, 6, 8, 6, 4, 2, 0}
+	if !slices.Equal(xa, wantX) || !slices.Equal(ya, wantY) {
+		t.Errorf("got x=%v y=%v\nwant x=%v y=%v", xa, ya, wantX, wantY)
+	}
+
+	if xs, ys := Interpolate(x, y, 1); !slices.Equal(xs, []float64{0, 2, 2, 4}) || !slices.Equal(ys, []float64{10, 6, 8, 0}) {
+		t.Errorf("factor 1 gave x=%v y=%v, want the sorted examples", xs, ys)
+	}
+}
+
+func TestSplitOrderedKeepsTheOrder(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	xTrain, yTrain, xTest, yTest := SplitOrdered(x, y, 0.8)
+	if len(xTrain) != 80 || len(yTrain) != 80 || len(xTest) != 20 || len(yTest) != 20 {
+		t.Fatalf("split into %d/%d training and %d/%d test examples, want 80 and 20", len(xTrain), len(yTrain), len(xTest), len(yTest))
+	}
+	if !slices.Equal(slices.Concat(xTrain, xTest), x) || !slices.Equal(slices.Concat(yTrain, yTest), y) {
+		t.Error("the parts don't make up the data-set in its order")
+	}
+	if xTrain[len(xTrain)-1] >= xTest[0] {
+		t.Errorf("the training part ends at %v after the test part starts at %v", xTrain[len(xTrain)-1], xTest[0])
+	}
+	xTrain = append(xTrain, -1)
+	if xTest[0] == -1 {
+		t.Error("appending to the training part overwrote the test part")
+	}
+	for _, frac := range []float64{-1, 0, 1, 2} {
+		xTrain, _, xTest, _ := SplitOrdered(x, y, frac)
+		if want := int(math.Max(0, math.Min(1, frac))) * len(x); len(xTrain) != want || len(xTest) != len(x)-want {
+			t.Errorf("fraction %v: split into %d and %d examples", frac, len(xTrain), len(xTest))
+		}
+	}
+}
+
+func TestHistogramOfAUniformDistribution(t *testing.T) {
+	rng := NewRandSource(1)
+	values := make([]float64, 10000)
+	for i := range values {
+		values[i] = 10 + 20*rng.Float64()
+	}
+	counts, edges := Histogram(values, 5)
+	if len(counts) != 5 || len(edges) != 6 {
+		t.Fatalf("got %d counts and %d edges, want 5 and 6", len(counts), len(edges))
+	}
+	if edges[0] != slices.Min(values) || edges[5] != slices.Max(values) || math.Abs(edges[1]-edges[0]-4) > 0.01 {
+		t.Errorf("edges %v, want about 10, 14, ..., 30", edges)
+	}
+	total := 0
+	for bin, count := range counts {
+		total += count
+		// 4 standard deviations of a binomial count with p = 0.2.
+		if math.Abs(float64(count)-2000) > 4*40 {
+			t.Errorf("bin %d has %d values, want about 2000", bin, count)
+		}
+	}
+	if total != len(values) {
+		t.Errorf("counted %d of %d values", total, len(values))
+	}
+
+	if counts, edges := Histogram([]float64{3, 3, 3}, 4); !slices.Equal(counts, []int{3, 0, 0, 0}) || edges[0] != 3 || edges[4] != 3 {
+		t.Errorf("equal values: got %v with edges %v, want all in the first bin", counts, edges)
+	}
+	if counts, edges := Histogram(nil, 4); counts != nil || edges != nil {
+		t.Errorf("no values: got %v and %v", counts, edges)
+	}
+}
+
+func TestHistogramLeavesOutNonFiniteValues(t *testing.T) {
+	values := []float64{0, math.NaN(), 1, math.Inf(1), 2, 3, math.Inf(-1)}
+	counts, edges := Histogram(values, 2)
+	if !slices.Equal(counts, []int{2, 2}) || !slices.Equal(edges, []float64{0, 1.5, 3}) {
+		t.Errorf("got counts %v and edges %v, want [2 2] and [0 1.5 3]", counts, edges)
+	}
+	if counts, edges := Histogram([]float64{math.NaN(), math.Inf(1)}, 3); counts != nil || edges != nil {
+		t.Errorf("no finite values got %v and %v, want nil", counts, edges)
+	}
+}
+
+func TestStandardizePopulationAndSample(t *testing.T) {
+	values := []float64{1, 2, 3, 4}
+	population, m, std := Standardize(values, false)
+	if m != 2.5 || math.Abs(std-math.Sqrt(1.25)) > 1e-15 {
+		t.Errorf("population mean %v and std %v, want 2.5 and %v", m, std, math.Sqrt(1.25))
+	}
+	sample, m, sampleStd := Standardize(values, true)
+	if m != 2.5 || math.Abs(sampleStd-math.Sqrt(5.0/3)) > 1e-15 {
+		t.Errorf("sample mean %v and std %v, want 2.5 and %v", m, sampleStd, math.Sqrt(5.0/3))
+	}
+	// The variances differ by N/(N-1), the z-scores by its square root.
+	if ratio := sampleStd * sampleStd / (std * std); math.Abs(ratio-4.0/3) > 1e-15 {
+		t.Errorf("the variances differ by %v, want 4/3", ratio)
+	}
+	for i := range values {
+		if math.Abs(population[i]/sample[i]-math.Sqrt(4.0/3)) > 1e-15 {
+			t.Errorf("the z-scores of %v are %v and %v", values[i], population[i], sample[i])
+		}
+	}
+
+	if scaled, m, std := Standardize([]float64{7}, true); scaled[0] != 0 || m != 7 || std != 0 {
+		t.Errorf("a single value standardized to %v with mean %v and std %v", scaled, m, std)
+	}
+}
+
+func TestConcatWeighted(t *testing.T) {
+	trusted := DataSet{X: []float64{0, 10}, Y: []float64{32, 50}}
+	noisy := DataSet{X: []float64{20, 30, 40}, Y: []float64{69, 85, 105}}
+	x, y, weights := ConcatWeighted([]DataSet{trusted, noisy, {}}, []float64{1, 0.2, 5})
+	if want := []float64{0, 10, 20, 30, 40}; !slices.Equal(x, want) {
+		t.Errorf("got the inputs %v, want %v", x, want)
+	}
+	if want := []float64{32, 50, 69, 85, 105}; !slices.Equal(y, want) {
+		t.Errorf("got the labels %v, want %v", y, want)
+	}
+	if want := []float64{1, 1, 0.2, 0.2, 0.2}; !slices.Equal(weights, want) {
+		t.Errorf("got the weights %v, want %v", weights, want)
+	}
+	if _, err := Train(NewNanoNeuron(NewRandSource(1)), x, y, 10, 0.0005, TrainOptions{Weights: weights}); err != nil {
+		t.Errorf("the weighted training failed: %v", err)
+	}
+
+	defer func() {
+		if err, _ := recover().(error); !errors.Is(err, ErrLengthMismatch) {
+			t.Errorf("a missing weight panicked with %v, want ErrLengthMismatch", err)
+		}
+	}()
+	ConcatWeighted([]DataSet{trusted, noisy}, []float64{1})
+}
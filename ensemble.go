@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// BlendByValidation blends the predictions of 'models' into one predictor, each model weighted
+// inversely to its cost on the validation data-set 'xVal', 'yVal': the better a model does
+// there, the more it counts. Models with a NaN or infinite cost get no weight; models with a
+// zero cost fit the validation data perfectly and get all of it between them. The blend is
+// only as good as the validation data and it helps most when the errors of the models differ,
+// so they partly cancel out. Without any usable model the predictor returns NaN.
+func BlendByValidation(models []*NanoNeuron, xVal, yVal []float64) func(float64) float64 {
+	weights := make([]float64, len(models))
+	perfect := false
+	for i, model := range models {
+		_, cost := forwardPropagation(model, xVal, yVal)
+		switch {
+		case !isFinite(cost):
+		case cost == 0:
+			if !perfect {
+				clear(weights)
+				perfect = true
+			}
+			weights[i] = 1
+		case !perfect:
+			weights[i] = 1 / cost
+		}
+	}
+	total := 0.0
+	for _, weight := range weights {
+		total += weight
+	}
+	return func(x float64) float64 {
+		if total == 0 {
+			return math.NaN()
+		}
+		y := 0.0
+		for i, model := range models {
+			if weights[i] != 0 {
+				y += weights[i] * model.predict(x)
+			}
+		}
+		return y / total
+	}
+}
+
+// AverageModels averages the parameters of 'models' into a new model, i.e. for a federated
+// training where every party trains on its own data and only the models are merged. The
+// 'weights' (i.e. the sizes of the data-sets of the parties) make it a weighted mean, nil
+// weighs all the models the same. Averaging parameters only makes sense for models of the
+// same shape: ErrInvalidInput is returned unless all have the same activation and either
+// all or none an output transform (which is averaged as well). No models are an ErrInvalidInput
+// too, weights not pairing up with the models an ErrLengthMismatch and negative weights or
+// weights summing up to zero an ErrInvalidHyperparameter.
+func AverageModels(models []*NanoNeuron, weights []float64) (*NanoNeuron, error) {
+	if len(models) == 0 {
+		return nil, fmt.Errorf("average models: %w: no models", ErrInvalidInput)
+	}
+	if weights != nil && len(weights) != len(models) {
+		return nil, fmt.Errorf("average models: %w: %d models but %d weights", ErrLengthMismatch, len(models), len(weights))
+	}
+	first := models[0]
+	total := 0.0
+	for i, model := range models {
+		if model.activation != first.activation || (model.output == nil) != (first.output == nil) {
+			return nil, fmt.Errorf("average models: %w: model %d (%v) has another shape than %v", ErrInvalidInput, i, model, first)
+		}
+		if weights != nil {
+			if !(weights[i] >= 0) || math.IsInf(weights[i], 0) {
+				return nil, fmt.Errorf("average models: %w: weight %v", ErrInvalidHyperparameter, weights[i])
+			}
+			total += weights[i]
+		}
+	}
+	if weights == nil {
+		total = float64(len(models))
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("average models: %w: the weights sum up to zero", ErrInvalidHyperparameter)
+	}
+
+	average := &NanoNeuron{activation: first.activation}
+	if first.output != nil {
+		average.output = &affine{}
+	}
+	for i, model := range models {
+		weight := 1.0
+		if weights != nil {
+			weight = weights[i]
+		}
+		share := weight / total
+		average.w += share * model.w
+		average.b += share * model.b
+		if model.output != nil {
+			average.output.scale += share * model.output.scale
+			average.output.offset += share * model.output.offset
+		}
+	}
+	return average, nil
+}
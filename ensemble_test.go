@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestBlendByValidation(t *testing.T) {
+	xVal, yVal := generateDataSets(0.5, 0, 0, nil)
+	// The first model is 1 too high, the second 1.5 too low and the third is broken.
+	models := []*NanoNeuron{{w: 1.8, b: 33}, {w: 1.8, b: 30.5}, {w: math.NaN()}}
+	blend := BlendByValidation(models, xVal, yVal)
+
+	cost := func(predict func(float64) float64) float64 {
+		sum := 0.0
+		for i, x := range xVal {
+			sum += predictionCost(yVal[i], predict(x))
+		}
+		return sum / float64(len(xVal))
+	}
+	best := math.Inf(1)
+	for _, model := range models {
+		if c := cost(model.predict); c < best {
+			best = c
+		}
+	}
+	if blended := cost(blend); !(blended <= best) {
+		t.Errorf("the blended validation cost %v is worse than the best model's %v", blended, best)
+	}
+
+	perfect := &NanoNeuron{w: 1.8, b: 32}
+	if y := BlendByValidation(append(models, perfect), xVal, yVal)(10); y != perfect.predict(10) {
+		t.Errorf("the perfect model got outvoted: %v", y)
+	}
+	if y := BlendByValidation(models[2:], xVal, yVal)(10); !math.IsNaN(y) {
+		t.Errorf("a blend without usable models predicted %v", y)
+	}
+}
+
+func TestAverageModelsOfTheHalvesApproximatesTheWhole(t *testing.T) {
+	x, y := generateDataSets(0, 2, 0, NewRandSource(3))
+	var halves [2][2][]float64
+	for i := range x {
+		halves[i%2][0] = append(halves[i%2][0], x[i])
+		halves[i%2][1] = append(halves[i%2][1], y[i])
+	}
+	train := func(x, y []float64) *NanoNeuron {
+		model := NewNanoNeuron(NewRandSource(1))
+		if _, err := Train(model, x, y, 70000, 0.0005, TrainOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		return model
+	}
+	whole := train(x, y)
+	parts := []*NanoNeuron{train(halves[0][0], halves[0][1]), train(halves[1][0], halves[1][1])}
+	average, err := AverageModels(parts, []float64{float64(len(halves[0][0])), float64(len(halves[1][0]))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(average.w-whole.w) > 0.01 || math.Abs(average.b-whole.b) > 0.2 {
+		t.Errorf("the average of %v and %v is %v, the whole data trained %v", parts[0], parts[1], average, whole)
+	}
+
+	// The halves are of the same size, so the plain average is the same.
+	if plain, err := AverageModels(parts, nil); err != nil || math.Abs(plain.w-average.w) > 1e-12 || math.Abs(plain.b-average.b) > 1e-12 {
+		t.Errorf("the unweighted average is %v (%v), want %v", plain, err, average)
+	}
+	for _, test := range []struct {
+		models  []*NanoNeuron
+		weights []float64
+		want    error
+	}{
+		{nil, nil, ErrInvalidInput},
+		{[]*NanoNeuron{whole, {w: 1, activation: Sigmoid{}}}, nil, ErrInvalidInput},
+		{[]*NanoNeuron{whole, (&NanoNeuron{w: 1}).WithOutputTransform(2, 0)}, nil, ErrInvalidInput},
+		{parts, []float64{1}, ErrLengthMismatch},
+		{parts, []float64{1, -1}, ErrInvalidHyperparameter},
+		{parts, []float64{0, 0}, ErrInvalidHyperparameter},
+	} {
+		if _, err := AverageModels(test.models, test.weights); !errors.Is(err, test.want) {
+			t.Errorf("AverageModels(%v, %v) returned %v, want %v", test.models, test.weights, err, test.want)
+		}
+	}
+}
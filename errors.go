@@ -0,0 +1,38 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors returned by the package. They are usually wrapped with more context,
+// so check them with errors.Is.
+var (
+	// ErrEmptyDataSet means that there are no examples to learn from or to evaluate on.
+	ErrEmptyDataSet = errors.New("empty data-set")
+	// ErrLengthMismatch means that the inputs and the labels don't pair up.
+	ErrLengthMismatch = errors.New("length mismatch")
+	// ErrZeroVariance means that all the inputs are the same, so no line can be fitted through them.
+	ErrZeroVariance = errors.New("zero variance")
+	// ErrDiverged means that the training blew up: the cost is no longer a finite number.
+	ErrDiverged = errors.New("training diverged")
+	// ErrStalled means that the training got stuck far from the target, see TrainOptions.StallWindow.
+	ErrStalled = errors.New("training stalled")
+	// ErrInvalidHyperparameter means that a training setting is out of its valid range.
+	ErrInvalidHyperparameter = errors.New("invalid hyperparameter")
+	// ErrInvalidInput means that a prediction was asked for with a value or a model that is not a finite number.
+	ErrInvalidInput = errors.New("invalid input")
+	// ErrNotInvertible means that the model maps different inputs to the same output, so it can't be run backwards.
+	ErrNotInvertible = errors.New("model is not invertible")
+)
+
+// validateDataSet checks that 'x' and 'y' form a usable data-set.
+func validateDataSet(x, y []float64) error {
+	if len(x) != len(y) {
+		return fmt.Errorf("%w: %d inputs but %d labels", ErrLengthMismatch, len(x), len(y))
+	}
+	if len(x) == 0 {
+		return ErrEmptyDataSet
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestErrorSentinels(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	train := func(x, y []float64, epochs int, alpha float64, opts TrainOptions) error {
+		_, err := Train(NewNanoNeuron(NewRandSource(1)), x, y, epochs, alpha, opts)
+		return err
+	}
+	for _, test := range []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"empty data", train(nil, nil, 10, 0.0005, TrainOptions{}), ErrEmptyDataSet},
+		{"mismatched lengths", train(x, y[:50], 10, 0.0005, TrainOptions{}), ErrLengthMismatch},
+		{"mismatched validation", train(x, y, 10, 0.0005, TrainOptions{XVal: x, YVal: y[1:]}), ErrLengthMismatch},
+		{"diverged", train(x, y, 1000, 0.01, TrainOptions{}), ErrDiverged},
+		{"no epochs", train(x, y, 0, 0.0005, TrainOptions{}), ErrInvalidHyperparameter},
+		{"negative alpha", train(x, y, 10, -1, TrainOptions{}), ErrInvalidHyperparameter},
+		{"stalled", train(x, y, 1000, 1e-12, TrainOptions{StallWindow: 10, StallEpsilon: 1, StallTarget: 1}), ErrStalled},
+		{"constant inputs", func() error {
+			_, err := FitClosedForm([]float64{1, 1}, []float64{2, 3})
+			return err
+		}(), ErrZeroVariance},
+		{"NaN input", func() error {
+			_, err := (&NanoNeuron{w: 1.8, b: 32}).PredictSafe(math.NaN())
+			return err
+		}(), ErrInvalidInput},
+		{"empty evaluation", func() error {
+			_, err := EvaluateSafe(&NanoNeuron{w: 1.8, b: 32}, nil, nil)
+			return err
+		}(), ErrEmptyDataSet},
+		{"mismatched evaluation", func() error {
+			_, err := EvaluateSafe(&NanoNeuron{w: 1.8, b: 32}, x, y[1:])
+			return err
+		}(), ErrLengthMismatch},
+		{"flat model", func() error {
+			_, err := (&NanoNeuron{b: 32}).PredictInverse(50)
+			return err
+		}(), ErrNotInvertible},
+	} {
+		if !errors.Is(test.err, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, test.err, test.want)
+		}
+	}
+}
+
+func TestTrainingOnNoExamplesRunsNoEpochs(t *testing.T) {
+	model := &NanoNeuron{w: 1, b: 2}
+	result := trainModel(model, 10, 0.1, nil, nil, TrainOptions{})
+	if len(result.CostHistory) != 0 || result.PerfectFit || !math.IsNaN(result.InitialCost) || *model != (NanoNeuron{w: 1, b: 2}) {
+		t.Errorf("trained %v on no examples with the costs %v (perfect fit=%v)", model, result.CostHistory, result.PerfectFit)
+	}
+	trainer := &Trainer{Model: model, Epochs: 10, Alpha: 0.1}
+	if !trainer.RunChunk(5) || trainer.Trained() != 0 {
+		t.Errorf("the trainer isn't done without examples after %d epochs", trainer.Trained())
+	}
+}
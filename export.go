@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// goExpresser is implemented by activations that can be written down as a Go expression of 'z'.
+type goExpresser interface {
+	goExpr(z string) string
+}
+
+// GoSource writes the trained model down as a dependency-free Go function named 'funcName',
+// i.e. "func predictFoo(x float64) float64 { return x*1.8 + 32 }", with the learned constants inlined.
+// The constants are written with the shortest representation that parses back to exactly the
+// same float64, so for a linear model the function returns the very same predictions.
+// A model with the Sigmoid activation needs the "math" package imported next to the function.
+// Rather than writing a function that computes another model or doesn't compile, it returns
+// an error for an activation that can't be written as Go and ErrInvalidInput for parameters
+// that aren't finite numbers.
+func (n *NanoNeuron) GoSource(funcName string) (string, error) {
+	return n.GoSourcePrecision(funcName, 0)
+}
+
+// GoSourcePrecision is GoSource writing the learned constants with 'digits' significant digits
+// (0 means full precision), like the %.6g verb does for 6, so the exported function reads
+// better. The rounding changes a constant by up to a relative 5 * 10^-digits and the predictions
+// change by about as much relative to the terms of the sum: with 6 digits the prediction of
+// w = 1.80000123 at x = 100 is off by 0.000123. The constants of an activation keep their
+// full precision. The errors are those of GoSource.
+func (n *NanoNeuron) GoSourcePrecision(funcName string, digits int) (string, error) {
+	params := []float64{n.w, n.b}
+	if n.output != nil {
+		params = append(params, n.output.scale, n.output.offset)
+	}
+	for _, v := range params {
+		if !isFinite(v) {
+			return "", fmt.Errorf("%w: go source: parameter %v has no Go constant", ErrInvalidInput, v)
+		}
+	}
+	if digits <= 0 {
+		digits = -1 // the shortest representation that reads back exactly
+	}
+	expr := "x*" + goFloatPrecision(n.w, digits) + signed(n.b, digits)
+	if n.activation != nil {
+		a, ok := n.activation.(goExpresser)
+		if !ok {
+			return "", fmt.Errorf("go source: activation %v can't be written as Go", n.activation)
+		}
+		expr = a.goExpr(expr)
+	}
+	if n.output != nil {
+		expr = "(" + expr + ")*" + goFloatPrecision(n.output.scale, digits) + signed(n.output.offset, digits)
+	}
+	return fmt.Sprintf("func %s(x float64) float64 { return %s }\n", funcName, expr), nil
+}
+
+// goFloat writes 'v' as a Go constant in full precision.
+func goFloat(v float64) string {
+	return goFloatPrecision(v, -1)
+}
+
+// goFloatPrecision writes 'v' as a Go constant with 'digits' significant digits (-1 is full precision).
+func goFloatPrecision(v float64, digits int) string {
+	s := strconv.FormatFloat(v, 'g', digits, 64)
+	if v < 0 {
+		return "(" + s + ")"
+	}
+	return s
+}
+
+// signed writes " + v" or " - |v|" with 'digits' significant digits to append a constant to an expression.
+func signed(v float64, digits int) string {
+	if v < 0 || (v == 0 && 1/v < 0) {
+		return " - " + strconv.FormatFloat(-v, 'g', digits, 64)
+	}
+	return " + " + strconv.FormatFloat(v, 'g', digits, 64)
+}
+
+// graphJSON is the portable ONNX-like representation of the model written by ExportGraph.
+type graphJSON struct {
+	Format    string             `json:"format"`  // always "nano-neuron-graph"
+	Version   int                `json:"version"` // of the schema, currently 1
+	Inputs    []string           `json:"inputs"`
+	Outputs   []string           `json:"outputs"`
+	Constants map[string]float64 `json:"constants"`
+	Nodes     []graphNode        `json:"nodes"`
+}
+
+// graphNode is one operation of the graph reading its named inputs and writing its named output.
+type graphNode struct {
+	Op         string   `json:"op"`
+	Inputs     []string `json:"inputs"`
+	Output     string   `json:"output"`
+	Activation string   `json:"activation,omitempty"` // the name of the function of an Activation node
+}
+
+// ExportGraph writes the model as a small JSON computation graph for other tooling.
+// It isn't full ONNX, just the same idea with a documented schema:
+//
+//	{
+//	  "format": "nano-neuron-graph", "version": 1,
+//	  "inputs": ["x"], "outputs": ["y"],
+//	  "constants": {"w": 1.8, "b": 32},
+//	  "nodes": [
+//	    {"op": "MatMul", "inputs": ["x", "w"], "output": "xw"},
+//	    {"op": "Add", "inputs": ["xw", "b"], "output": "y"}
+//	  ]
+//	}
+//
+// A model with an activation gets an {"op": "Activation", "activation": "sigmoid"} node (named
+// after the activation's String method) and the output transform adds a Mul node with the
+// "scale" and an Add node with the "offset" constants. The nodes are in the order of evaluation.
+func (n *NanoNeuron) ExportGraph(w io.Writer) error {
+	graph := graphJSON{
+		Format:    "nano-neuron-graph",
+		Version:   1,
+		Inputs:    []string{"x"},
+		Constants: map[string]float64{"w": n.w, "b": n.b},
+	}
+	last := "x"
+	add := func(node graphNode) {
+		graph.Nodes = append(graph.Nodes, node)
+		last = node.Output
+	}
+	add(graphNode{Op: "MatMul", Inputs: []string{last, "w"}, Output: "xw"})
+	add(graphNode{Op: "Add", Inputs: []string{last, "b"}, Output: "z"})
+	if n.activation != nil {
+		name, ok := n.activation.(fmt.Stringer)
+		if !ok {
+			return fmt.Errorf("export graph: activation %T has no name", n.activation)
+		}
+		add(graphNode{Op: "Activation", Inputs: []string{last}, Output: "a", Activation: name.String()})
+	}
+	if n.output != nil {
+		graph.Constants["scale"] = n.output.scale
+		graph.Constants["offset"] = n.output.offset
+		add(graphNode{Op: "Mul", Inputs: []string{last, "scale"}, Output: "scaled"})
+		add(graphNode{Op: "Add", Inputs: []string{last, "offset"}, Output: "shifted"})
+	}
+	// The last node writes the output of the graph.
+	graph.Nodes[len(graph.Nodes)-1].Output = "y"
+	graph.Outputs = []string{"y"}
+	for name, v := range graph.Constants {
+		if !isFinite(v) {
+			return fmt.Errorf("export graph: constant %s is %v", name, v)
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(graph)
+}
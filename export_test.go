@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"maps"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+// parseGoSource type-checks the function written by GoSource and returns its declaration.
+func parseGoSource(t *testing.T, source string) *ast.FuncDecl {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "model.go", "package model\n\nimport \"math\"\n\nvar _ = math.Pi\n\n"+source, 0)
+	if err != nil {
+		t.Fatalf("%q doesn't parse: %v", source, err)
+	}
+	config := types.Config{Importer: importer.Default()}
+	if _, err := config.Check("model", fset, []*ast.File{file}, nil); err != nil {
+		t.Fatalf("%q doesn't compile: %v", source, err)
+	}
+	return file.Decls[len(file.Decls)-1].(*ast.FuncDecl)
+}
+
+// evalGoExpr evaluates the float64 expression 'e' of 'x', as far as GoSource writes them.
+func evalGoExpr(e ast.Expr, x float64) (float64, error) {
+	switch e := e.(type) {
+	case *ast.Ident:
+		if e.Name == "x" {
+			return x, nil
+		}
+	case *ast.BasicLit:
+		return strconv.ParseFloat(e.Value, 64)
+	case *ast.ParenExpr:
+		return evalGoExpr(e.X, x)
+	case *ast.UnaryExpr:
+		v, err := evalGoExpr(e.X, x)
+		if e.Op == token.SUB {
+			return -v, err
+		}
+	case *ast.BinaryExpr:
+		a, err := evalGoExpr(e.X, x)
+		if err != nil {
+			return 0, err
+		}
+		b, err := evalGoExpr(e.Y, x)
+		switch e.Op {
+		case token.ADD:
+			return a + b, err
+		case token.SUB:
+			return a - b, err
+		case token.MUL:
+			return a * b, err
+		case token.QUO:
+			return a / b, err
+		}
+	case *ast.CallExpr:
+		functions := map[string]func(float64) float64{"math.Exp": math.Exp, "math.Tanh": math.Tanh}
+		if name := types.ExprString(e.Fun); functions[name] != nil && len(e.Args) == 1 {
+			v, err := evalGoExpr(e.Args[0], x)
+			return functions[name](v), err
+		}
+	}
+	return 0, fmt.Errorf("can't evaluate %s", types.ExprString(e))
+}
+
+func TestGoSourceCompilesAndPredicts(t *testing.T) {
+	for name, model := range map[string]*NanoNeuron{
+		"linear":    {w: 1.8000000000000007, b: 31.999999999999996},
+		"negative":  {w: -0.3, b: -12.5},
+		"sigmoid":   {w: 0.5, b: -2, activation: Sigmoid{}},
+		"tanh":      {w: 0.5, b: -2, activation: Tanh{}},
+		"transform": (&NanoNeuron{w: 1.8, b: 32}).WithOutputTransform(5.0/9, 273.15-32*5.0/9),
+	} {
+		source, err := model.GoSource("predictModel")
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		decl := parseGoSource(t, source)
+		if decl.Name.Name != "predictModel" {
+			t.Errorf("%s: the function is called %s", name, decl.Name.Name)
+		}
+		result := decl.Body.List[0].(*ast.ReturnStmt).Results[0]
+		for _, x := range []float64{-40, 0, 3.5, 100} {
+			got, err := evalGoExpr(result, x)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			// The plain linear model is reproduced bit for bit.
+			tolerance := 0.0
+			if model.activation != nil || model.output != nil {
+				tolerance = 1e-12 * math.Max(1, math.Abs(got))
+			}
+			if want := model.predict(x); math.Abs(got-want) > tolerance {
+				t.Errorf("%s at %v: the source gives %v, predict %v", name, x, got, want)
+			}
+		}
+	}
+	// The activations written as function literals at least compile.
+	for _, activation := range []Activation{LeakyReLU(0.1), LeakyReLU(2), ELU(1), Softplus{}} {
+		source, err := (&NanoNeuron{w: 1, b: 2, activation: activation}).GoSource("predictModel")
+		if err != nil {
+			t.Fatalf("%v: %v", activation, err)
+		}
+		parseGoSource(t, source)
+	}
+}
+
+func TestGoSourcePrecision(t *testing.T) {
+	model := &NanoNeuron{w: 1.8000012345678901, b: 31.999987654321}
+	predictions := func(digits int) func(float64) float64 {
+		source, err := model.GoSourcePrecision("predictModel", digits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result := parseGoSource(t, source).Body.List[0].(*ast.ReturnStmt).Results[0]
+		return func(x float64) float64 {
+			y, err := evalGoExpr(result, x)
+			if err != nil {
+				t.Fatalf("%q: %v", source, err)
+			}
+			return y
+		}
+	}
+	full, rounded := predictions(0), predictions(6)
+	want := "func predictModel(x float64) float64 { return x*1.8 + 32 }\n"
+	if got, _ := model.GoSourcePrecision("predictModel", 6); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	for _, x := range []float64{-40, 0, 3.5, 100} {
+		// Full precision reproduces the predictions bit for bit, 6 digits about to 5e-6.
+		if got, want := full(x), model.predict(x); got != want {
+			t.Errorf("at %v the full precision source gives %v, predict %v", x, got, want)
+		}
+		if got, want := rounded(x), model.predict(x); math.Abs(got-want) > 5e-6*(math.Abs(model.w*x)+math.Abs(model.b)) {
+			t.Errorf("at %v the 6 digits source gives %v, predict %v", x, got, want)
+		}
+	}
+}
+
+func TestExportGraph(t *testing.T) {
+	for _, test := range []struct {
+		model     *NanoNeuron
+		constants map[string]float64
+		nodes     []graphNode
+	}{
+		{
+			&NanoNeuron{w: 1.8, b: 32},
+			map[string]float64{"w": 1.8, "b": 32},
+			[]graphNode{
+				{Op: "MatMul", Inputs: []string{"x", "w"}, Output: "xw"},
+				{Op: "Add", Inputs: []string{"xw", "b"}, Output: "y"},
+			},
+		},
+		{
+			&NanoNeuron{w: 0.5, b: -2, activation: Sigmoid{}, output: &affine{scale: 100, offset: 10}},
+			map[string]float64{"w": 0.5, "b": -2, "scale": 100, "offset": 10},
+			[]graphNode{
+				{Op: "MatMul", Inputs: []string{"x", "w"}, Output: "xw"},
+				{Op: "Add", Inputs: []string{"xw", "b"}, Output: "z"},
+				{Op: "Activation", Inputs: []string{"z"}, Output: "a", Activation: "sigmoid"},
+				{Op: "Mul", Inputs: []string{"a", "scale"}, Output: "scaled"},
+				{Op: "Add", Inputs: []string{"scaled", "offset"}, Output: "y"},
+			},
+		},
+	} {
+		var buf bytes.Buffer
+		if err := test.model.ExportGraph(&buf); err != nil {
+			t.Fatalf("%v: ExportGraph: %v", test.model, err)
+		}
+		var graph graphJSON
+		if err := json.Unmarshal(buf.Bytes(), &graph); err != nil {
+			t.Fatalf("%v: invalid JSON: %v", test.model, err)
+		}
+		if graph.Format != "nano-neuron-graph" || graph.Version != 1 ||
+			!slices.Equal(graph.Inputs, []string{"x"}) || !slices.Equal(graph.Outputs, []string{"y"}) {
+			t.Errorf("%v: got the header %q v%d, inputs %v, outputs %v", test.model, graph.Format, graph.Version, graph.Inputs, graph.Outputs)
+		}
+		if !maps.Equal(graph.Constants, test.constants) {
+			t.Errorf("%v: got the constants %v, want %v", test.model, graph.Constants, test.constants)
+		}
+		if !reflect.DeepEqual(graph.Nodes, test.nodes) {
+			t.Errorf("%v: got the nodes\n%+v\nwant\n%+v", test.model, graph.Nodes, test.nodes)
+		}
+	}
+}
+
+// opaqueActivation is an activation that can't be written as Go.
+type opaqueActivation struct{}
+
+func (opaqueActivation) Activate(z float64) float64   { return z }
+func (opaqueActivation) Derivative(z float64) float64 { return 1 }
+
+func TestGoSourceRejectsWhatItCantWrite(t *testing.T) {
+	for name, model := range map[string]*NanoNeuron{
+		"activation":   {w: 1, b: 2, activation: opaqueActivation{}},
+		"NaN w":        {w: math.NaN(), b: 2},
+		"infinite b":   {w: 1, b: math.Inf(-1)},
+		"output scale": (&NanoNeuron{w: 1, b: 2}).WithOutputTransform(math.Inf(1), 0),
+	} {
+		if source, err := model.GoSource("predictModel"); err == nil {
+			t.Errorf("%s: wrote %q", name, source)
+		}
+	}
+	if _, err := (&NanoNeuron{w: math.NaN()}).GoSource("predictModel"); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("NaN w: got %v, want ErrInvalidInput", err)
+	}
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// String describes the learned linear equation, i.e. "y = 1.800x + 32.000".
+// If the model has an activation the equation is wrapped into it, i.e. "y = sigmoid(0.500x - 2.000)",
+// and an output transform is shown around everything else.
+func (n *NanoNeuron) String() string {
+	equation := fmt.Sprintf("%.3fx %s", n.w, signedTerm(n.b))
+	if n.activation != nil {
+		equation = fmt.Sprintf("%v(%s)", n.activation, equation)
+	}
+	if n.output != nil {
+		equation = fmt.Sprintf("%.3f(%s) %s", n.output.scale, equation, signedTerm(n.output.offset))
+	}
+	return "y = " + equation
+}
+
+// signedTerm formats 'v' as a term added to an equation, with its sign as the operator:
+// "+ 32.000" or "- 32.000" (never "+ -32.000").
+func signedTerm(v float64) string {
+	if math.Signbit(v) {
+		return fmt.Sprintf("- %.3f", -v)
+	}
+	return fmt.Sprintf("+ %.3f", v)
+}
+
+// String describes the learned linear equation like NanoNeuron.String, with every weight
+// followed by the index of its feature, i.e. "y = 1.800x0 - 0.500x1 + 32.000".
+func (n *MultiNanoNeuron) String() string {
+	var equation strings.Builder
+	for i, w := range n.w {
+		if i == 0 {
+			fmt.Fprintf(&equation, "%.3fx%d", w, i)
+		} else {
+			fmt.Fprintf(&equation, " %sx%d", signedTerm(w), i)
+		}
+	}
+	if len(n.w) == 0 {
+		fmt.Fprintf(&equation, "%.3f", n.b)
+	} else {
+		fmt.Fprintf(&equation, " %s", signedTerm(n.b))
+	}
+	return "y = " + equation.String()
+}
+
+// PredictFormatted returns the prediction for 'x' rounded to the given number of decimals.
+// Rounding is done half-away-from-zero on the shortest decimal representation of the
+// prediction, so 2.675 -> "2.68" and -2.5 -> "-3" as a human would expect
+// (plain math.Round(v*100)/100 gets 2.675 wrong because it is stored as 2.67499...).
+func (n *NanoNeuron) PredictFormatted(x float64, decimals int) (string, error) {
+	if decimals < 0 {
+		return "", fmt.Errorf("decimals must not be negative, got %d", decimals)
+	}
+	return roundDecimal(n.predict(x), decimals), nil
+}
+
+// roundDecimal formats 'v' with exactly 'decimals' digits after the point using
+// half-away-from-zero rounding.
+func roundDecimal(v float64, decimals int) string {
+	s := strconv.FormatFloat(v, 'f', -1, 64)
+	if s == "NaN" || s == "+Inf" || s == "-Inf" {
+		return s
+	}
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	intPart, fracPart := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, fracPart = s[:i], s[i+1:]
+	}
+	if len(fracPart) <= decimals {
+		fracPart += strings.Repeat("0", decimals-len(fracPart))
+		return joinDecimal(negative, intPart, fracPart)
+	}
+
+	// Keep 'decimals' digits and look at the first dropped one to decide the rounding.
+	roundUp := fracPart[decimals] >= '5'
+	digits := []byte(intPart + fracPart[:decimals])
+	if roundUp {
+		i := len(digits) - 1
+		for ; i >= 0; i-- {
+			if digits[i] == '9' {
+				digits[i] = '0'
+				continue
+			}
+			digits[i]++
+			break
+		}
+		if i < 0 {
+			digits = append([]byte{'1'}, digits...)
+		}
+	}
+	split := len(digits) - decimals
+	return joinDecimal(negative, string(digits[:split]), string(digits[split:]))
+}
+
+func joinDecimal(negative bool, intPart, fracPart string) string {
+	s := intPart
+	if fracPart != "" {
+		s += "." + fracPart
+	}
+	if negative && strings.Trim(s, "0.") != "" {
+		s = "-" + s
+	}
+	return s
+}
+
+// Column layout of the training progress table printed in -verbose mode.
+const progressFormat = "%8v  %16v  %14v  %14v"
+
+// progressHeader is the header line of the training progress table.
+func progressHeader() string {
+	return fmt.Sprintf(progressFormat, "Epoch", "Cost", "w", "b")
+}
+
+// progressRow formats one epoch of the training progress table, aligned with progressHeader.
+func progressRow(epoch int, cost, w, b float64) string {
+	return fmt.Sprintf(progressFormat, epoch,
+		strconv.FormatFloat(cost, 'g', 8, 64),
+		strconv.FormatFloat(w, 'f', 6, 64),
+		strconv.FormatFloat(b, 'f', 6, 64))
+}
+
+// DiffModels reports what a retraining changed: a line per parameter with its old value,
+// its new value and the change, i.e.
+//
+//	w: 1.700000 -> 1.800000 (+0.100000)
+//	b: 35.000000 -> 32.000000 (-3.000000)
+func DiffModels(before, after *NanoNeuron) string {
+	var report strings.Builder
+	diffParameter(&report, "w", before.w, after.w)
+	diffParameter(&report, "b", before.b, after.b)
+	return report.String()
+}
+
+// DiffMultiModels is DiffModels for multi-feature models with a line per weight, named by
+// the index of its feature (w0, w1, ...). A weight only one of the models has is shown as
+// "none" on the other side, without a change.
+func DiffMultiModels(before, after *MultiNanoNeuron) string {
+	var report strings.Builder
+	for i := range max(len(before.w), len(after.w)) {
+		name := "w" + strconv.Itoa(i)
+		switch {
+		case i >= len(before.w):
+			fmt.Fprintf(&report, "%s: none -> %.6f\n", name, after.w[i])
+		case i >= len(after.w):
+			fmt.Fprintf(&report, "%s: %.6f -> none\n", name, before.w[i])
+		default:
+			diffParameter(&report, name, before.w[i], after.w[i])
+		}
+	}
+	diffParameter(&report, "b", before.b, after.b)
+	return report.String()
+}
+
+// diffParameter writes the line of DiffModels of a single parameter.
+func diffParameter(report *strings.Builder, name string, before, after float64) {
+	fmt.Fprintf(report, "%s: %.6f -> %.6f (%+.6f)\n", name, before, after, after-before)
+}
+
+// OutputFormatter writes a prediction down for an output sink, one line per prediction
+// (without the line break), see PredictStreamWith.
+type OutputFormatter interface {
+	Format(x, prediction float64) string
+}
+
+// PlainFormatter writes the prediction alone, i.e. "50".
+type PlainFormatter struct{}
+
+// Format implements OutputFormatter.
+func (PlainFormatter) Format(_, prediction float64) string {
+	return formatNumber(prediction)
+}
+
+// CSVFormatter writes the input and the prediction as a CSV row, i.e. "10,50".
+type CSVFormatter struct{}
+
+// Format implements OutputFormatter.
+func (CSVFormatter) Format(x, prediction float64) string {
+	return formatNumber(x) + "," + formatNumber(prediction)
+}
+
+// JSONFormatter writes a JSON object per line (JSON Lines), i.e. {"x":10,"prediction":50}.
+// JSON has no NaN or infinities, they are written as null.
+type JSONFormatter struct{}
+
+// Format implements OutputFormatter.
+func (JSONFormatter) Format(x, prediction float64) string {
+	number := func(v float64) string {
+		if !isFinite(v) {
+			return "null"
+		}
+		return formatNumber(v)
+	}
+	return `{"x":` + number(x) + `,"prediction":` + number(prediction) + `}`
+}
+
+// formatNumber is the shortest representation of 'v' that reads back exactly.
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
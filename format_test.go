@@ -0,0 +1,130 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestPredictFormatted(t *testing.T) {
+	identity := &NanoNeuron{w: 1}
+	for _, test := range []struct {
+		x        float64
+		decimals int
+		want     string
+	}{
+		{158.0002, 0, "158"},
+		{158.0002, 2, "158.00"},
+		{158.0002, 4, "158.0002"},
+		{158.0002, 6, "158.000200"},
+		{2.675, 2, "2.68"},
+		{2.5, 0, "3"},
+		{-2.5, 0, "-3"},
+		{-2.675, 2, "-2.68"},
+		{9.995, 2, "10.00"},
+		{99.96, 1, "100.0"},
+		{-0.004, 2, "0.00"},
+		{1e-7, 3, "0.000"},
+	} {
+		got, err := identity.PredictFormatted(test.x, test.decimals)
+		if err != nil || got != test.want {
+			t.Errorf("%v with %d decimals: got %q (%v), want %q", test.x, test.decimals, got, err, test.want)
+		}
+	}
+	if _, err := identity.PredictFormatted(1, -1); err == nil {
+		t.Error("negative decimals were accepted")
+	}
+}
+
+func TestNanoNeuronString(t *testing.T) {
+	for _, test := range []struct {
+		model *NanoNeuron
+		want  string
+	}{
+		{&NanoNeuron{w: 1.8, b: 32}, "y = 1.800x + 32.000"},
+		{&NanoNeuron{w: 0.5, b: -2}, "y = 0.500x - 2.000"},
+		{&NanoNeuron{w: 1.8, b: -32}, "y = 1.800x - 32.000"},
+		{&NanoNeuron{w: 1.8, b: math.Copysign(0, -1)}, "y = 1.800x - 0.000"},
+		{&NanoNeuron{w: 0.5, b: -2, activation: Sigmoid{}}, "y = sigmoid(0.500x - 2.000)"},
+		{&NanoNeuron{w: 1, b: -1, output: &affine{scale: 2, offset: -32}}, "y = 2.000(1.000x - 1.000) - 32.000"},
+	} {
+		if got := test.model.String(); got != test.want {
+			t.Errorf("got %q, want %q", got, test.want)
+		}
+	}
+}
+
+func TestMultiNanoNeuronString(t *testing.T) {
+	for _, test := range []struct {
+		model *MultiNanoNeuron
+		want  string
+	}{
+		{&MultiNanoNeuron{w: []float64{1.8, -0.5}, b: 32}, "y = 1.800x0 - 0.500x1 + 32.000"},
+		{&MultiNanoNeuron{w: []float64{-1, 2, 0.25}, b: -3}, "y = -1.000x0 + 2.000x1 + 0.250x2 - 3.000"},
+		{&MultiNanoNeuron{b: 7}, "y = 7.000"},
+		{&MultiNanoNeuron{w: []float64{1, math.Copysign(0, -1)}, b: math.Copysign(0, -1)}, "y = 1.000x0 - 0.000x1 - 0.000"},
+	} {
+		if got := test.model.String(); got != test.want {
+			t.Errorf("got %q, want %q", got, test.want)
+		}
+	}
+}
+
+func TestProgressRowAlignsWithTheHeader(t *testing.T) {
+	header := progressHeader()
+	row := progressRow(5000, 0.000123456789, 1.8, -32.5)
+	want := "    5000     0.00012345679        1.800000      -32.500000"
+	if row != want {
+		t.Errorf("got  %q\nwant %q", row, want)
+	}
+	if len(row) != len(header) {
+		t.Fatalf("the row is %d characters wide, the header %d", len(row), len(header))
+	}
+	// Every column is right aligned, so its last character is at the same place in both lines.
+	for _, column := range []int{8, 26, 42, 58} {
+		if header[column-1] == ' ' || row[column-1] == ' ' {
+			t.Errorf("column ending at %d isn't aligned:\n%s\n%s", column, header, row)
+		}
+	}
+}
+
+func TestOutputFormatters(t *testing.T) {
+	for _, test := range []struct {
+		formatter     OutputFormatter
+		x, prediction float64
+		want          string
+	}{
+		{PlainFormatter{}, 10, 50, "50"},
+		{PlainFormatter{}, 0.1, 32.18, "32.18"},
+		{CSVFormatter{}, 10, 50, "10,50"},
+		{CSVFormatter{}, -40, -40, "-40,-40"},
+		{JSONFormatter{}, 10, 50, `{"x":10,"prediction":50}`},
+		{JSONFormatter{}, 1e21, math.NaN(), `{"x":1e+21,"prediction":null}`},
+	} {
+		if got := test.formatter.Format(test.x, test.prediction); got != test.want {
+			t.Errorf("%T(%v, %v) = %q, want %q", test.formatter, test.x, test.prediction, got, test.want)
+		}
+	}
+
+	var out strings.Builder
+	if err := PredictStreamWith(&NanoNeuron{w: 1.8, b: 32}, strings.NewReader("0\n100\n"), &out, CSVFormatter{}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := out.String(), "0,32\n100,212\n"; got != want {
+		t.Errorf("got output %q, want %q", got, want)
+	}
+}
+
+func TestDiffModels(t *testing.T) {
+	got := DiffModels(&NanoNeuron{w: 1.7, b: 35}, &NanoNeuron{w: 1.8, b: 32})
+	want := "w: 1.700000 -> 1.800000 (+0.100000)\nb: 35.000000 -> 32.000000 (-3.000000)\n"
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	got = DiffMultiModels(&MultiNanoNeuron{w: []float64{1, 2}, b: 0.5}, &MultiNanoNeuron{w: []float64{1.5, 2, -1}, b: 0.25})
+	want = "w0: 1.000000 -> 1.500000 (+0.500000)\nw1: 2.000000 -> 2.000000 (+0.000000)\nw2: none -> -1.000000\nb: 0.500000 -> 0.250000 (-0.250000)\n"
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
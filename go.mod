@@ -1,3 +1,3 @@
 module github.com/aquilax/nano-neuron-go
 
-go 1.16
+go 1.23
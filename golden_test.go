@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"math"
+	"os"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata instead of comparing with them")
+
+// golden are the numbers the demo training is pinned to.
+type golden struct {
+	W        float64 `json:"w"`
+	B        float64 `json:"b"`
+	TestCost float64 `json:"test_cost"`
+}
+
+// goldenTolerance is the relative difference allowed from the golden values. It only absorbs
+// the rounding differences between platforms (i.e. fused multiply-adds), any real change of
+// the training shows up far above it.
+const goldenTolerance = 1e-9
+
+// TestDemoTrainingGolden runs the training of the demo with its fixed seed and hyperparameters.
+// After an intended change of the numbers regenerate the golden values with
+//
+//	go test -run TestDemoTrainingGolden -update
+func TestDemoTrainingGolden(t *testing.T) {
+	model := NewNanoNeuron(NewRandSource(deterministicSeed))
+	xTrain, yTrain := generateDataSets(0, 0, 0, nil)
+	xTest, yTest := generateDataSets(0.5, 0, 0, nil)
+	trainModel(model, 70000, 0.0005, xTrain, yTrain, TrainOptions{})
+	_, testCost := forwardPropagation(model, xTest, yTest)
+	got := golden{W: model.w, B: model.b, TestCost: testCost}
+
+	const path = "testdata/demo_training.golden.json"
+	if *update {
+		data, err := json.MarshalIndent(got, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run with -update to create it)", err)
+	}
+	var want golden
+	if err := json.Unmarshal(data, &want); err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+	for _, c := range []struct {
+		name      string
+		got, want float64
+	}{{"w", got.W, want.W}, {"b", got.B, want.B}, {"test cost", got.TestCost, want.TestCost}} {
+		if math.Abs(c.got-c.want) > goldenTolerance*math.Abs(c.want) {
+			t.Errorf("%s = %v, golden %v", c.name, c.got, c.want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+)
+
+// WriteCostHistoryJSON writes the cost history as a JSON array of numbers, one per epoch,
+// for plotting the learning curve with external tools. Values are streamed one by one rather
+// than marshaled as a whole, so even very long histories don't have to be buffered.
+// JSON has no NaN or infinity, so non-finite costs (a diverged training) are written as null.
+func WriteCostHistoryJSON(w io.Writer, history []float64) error {
+	out := bufio.NewWriter(w)
+	out.WriteByte('[')
+	for i, cost := range history {
+		if i > 0 {
+			out.WriteByte(',')
+		}
+		out.WriteString(jsonNumber(cost))
+	}
+	out.WriteString("]\n")
+	return out.Flush()
+}
+
+// WriteCostHistoryJSONSampled writes every 'every'-th epoch of the cost history as a JSON array
+// of {"epoch": ..., "cost": ...} objects. The last epoch is always included.
+func WriteCostHistoryJSONSampled(w io.Writer, history []float64, every int) error {
+	if every < 1 {
+		every = 1
+	}
+	out := bufio.NewWriter(w)
+	out.WriteByte('[')
+	first := true
+	for epoch, cost := range history {
+		if epoch%every != 0 && epoch != len(history)-1 {
+			continue
+		}
+		if !first {
+			out.WriteByte(',')
+		}
+		first = false
+		out.WriteString(`{"epoch":` + strconv.Itoa(epoch) + `,"cost":` + jsonNumber(cost) + "}")
+	}
+	out.WriteString("]\n")
+	return out.Flush()
+}
+
+func jsonNumber(v float64) string {
+	if !isFinite(v) {
+		return "null"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// MovingAverage smooths a jagged (i.e. mini-batch) cost history for plotting: every epoch gets
+// the average of the last 'window' costs up to it. The first epochs average over as many costs
+// as there are so far, so a window longer than the history is fine too. For window <= 1 the
+// history is returned as it is.
+func MovingAverage(history []float64, window int) []float64 {
+	if window <= 1 {
+		return history
+	}
+	smoothed := make([]float64, len(history))
+	sum := 0.0
+	for i, cost := range history {
+		sum += cost
+		if i >= window {
+			sum -= history[i-window]
+		}
+		smoothed[i] = sum / float64(min(i+1, window))
+	}
+	return smoothed
+}
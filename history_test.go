@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestWriteCostHistoryJSONParsesBack(t *testing.T) {
+	history := []float64{4706.976207037086, 12.5, 1e-300, 0.1 + 0.2, 0}
+	var buf bytes.Buffer
+	if err := WriteCostHistoryJSON(&buf, history); err != nil {
+		t.Fatalf("WriteCostHistoryJSON: %v", err)
+	}
+	var got []float64
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	if !slices.Equal(got, history) {
+		t.Errorf("got %v, want %v", got, history)
+	}
+
+	buf.Reset()
+	if err := WriteCostHistoryJSON(&buf, []float64{1, math.NaN(), math.Inf(1)}); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != "[1,null,null]\n" {
+		t.Errorf("non-finite costs written as %q", got)
+	}
+}
+
+func TestWriteCostHistoryJSONSampled(t *testing.T) {
+	history := []float64{5, 4, 3, 2, 1}
+	var buf bytes.Buffer
+	if err := WriteCostHistoryJSONSampled(&buf, history, 2); err != nil {
+		t.Fatalf("WriteCostHistoryJSONSampled: %v", err)
+	}
+	var got []struct {
+		Epoch int
+		Cost  float64
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	for i, sample := range got {
+		if sample.Epoch != 2*i || sample.Cost != history[sample.Epoch] {
+			t.Errorf("sample %d is %+v", i, sample)
+		}
+	}
+	if len(got) != 3 {
+		t.Errorf("got %d samples, want 3", len(got))
+	}
+}
+
+func TestMovingAverage(t *testing.T) {
+	constant := []float64{3, 3, 3, 3, 3}
+	if got := MovingAverage(constant, 3); !slices.Equal(got, constant) {
+		t.Errorf("a constant history smoothed to %v", got)
+	}
+	history := []float64{1, 2, 3, 4, 5, 6}
+	tests := []struct {
+		window int
+		want   []float64
+	}{
+		{3, []float64{1, 1.5, 2, 3, 4, 5}},
+		{2, []float64{1, 1.5, 2.5, 3.5, 4.5, 5.5}},
+		{10, []float64{1, 1.5, 2, 2.5, 3, 3.5}},
+		{1, history},
+		{0, history},
+		{-2, history},
+	}
+	for _, test := range tests {
+		if got := MovingAverage(history, test.window); !slices.Equal(got, test.want) {
+			t.Errorf("window %d: got %v, want %v", test.window, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+package main
+
+// Initializer sets up the parameters of a model right before its training starts.
+// It may look at the training data to make a better first guess than a random one.
+type Initializer func(model *NanoNeuron, xTrain, yTrain []float64)
+
+// BiasFromMean starts from a horizontal line through the average label: w = 0, b = mean(yTrain).
+// That is the best constant prediction, so the training starts much closer to the optimum
+// than with random parameters and needs fewer epochs to converge.
+func BiasFromMean(model *NanoNeuron, xTrain, yTrain []float64) {
+	model.w = 0
+	model.b = mean(yTrain)
+}
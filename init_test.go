@@ -0,0 +1,28 @@
+package main
+
+import "testing"
+
+func TestBiasFromMean(t *testing.T) {
+	// Centered inputs, so the bias barely depends on the slope.
+	x, y := generateDataSets(-50, 0, 0, nil)
+	model := NewNanoNeuron(NewRandSource(1))
+	BiasFromMean(model, x, y)
+	if model.w != 0 || model.b != mean(y) {
+		t.Errorf("got w=%v b=%v, want w=0 b=%v", model.w, model.b, mean(y))
+	}
+
+	epochsTo := func(init Initializer) int {
+		result := trainModel(NewNanoNeuron(NewRandSource(1)), 200000, 0.0005, x, y, TrainOptions{Init: init})
+		for epoch, cost := range result.CostHistory {
+			if cost < 1e-3 {
+				return epoch
+			}
+		}
+		t.Fatal("the training never got below 0.001")
+		return 0
+	}
+	fromMean, random := epochsTo(BiasFromMean), epochsTo(nil)
+	if fromMean >= random {
+		t.Errorf("from the mean it took %d epochs, from random parameters %d, want fewer", fromMean, random)
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMarshalJSONPrecisionRoundTrip(t *testing.T) {
+	model := &NanoNeuron{w: 1.8000012345678, b: 31.99999876543, output: &affine{scale: 0.123456789, offset: -4567.891}}
+	for _, digits := range []int{3, 6, 9} {
+		data, err := model.MarshalJSONPrecision(digits)
+		if err != nil {
+			t.Fatalf("%d digits: %v", digits, err)
+		}
+		var decoded NanoNeuron
+		if err := decoded.UnmarshalJSON(data); err != nil {
+			t.Fatalf("%d digits: can't decode %s: %v", digits, data, err)
+		}
+		// Half a unit of the last kept digit.
+		bound := 5 * math.Pow(10, -float64(digits))
+		for _, pair := range [][2]float64{
+			{decoded.w, model.w}, {decoded.b, model.b},
+			{decoded.output.scale, model.output.scale}, {decoded.output.offset, model.output.offset},
+		} {
+			if relative := math.Abs(pair[0]-pair[1]) / math.Abs(pair[1]); relative > bound {
+				t.Errorf("%d digits: %v came back as %v, a relative error of %v", digits, pair[1], pair[0], relative)
+			}
+		}
+	}
+
+	data, err := model.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var exact NanoNeuron
+	if err := exact.UnmarshalJSON(data); err != nil || exact.w != model.w || exact.b != model.b {
+		t.Errorf("full precision came back as %v (%v), want %v", &exact, err, model)
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import "fmt"
+
+// Layer is a row of independent NanoNeurons, a first step towards a real neural network.
+// Every neuron starts from different random parameters and learns the same data on its own,
+// averaging their predictions then smooths out the differences of the single trainings
+// (variance reduction through averaging).
+type Layer struct {
+	Neurons []*NanoNeuron
+}
+
+// NewLayer creates a layer of 'size' randomly set up neurons, all of them drawn from 'rng'.
+func NewLayer(size int, rng RandSource) *Layer {
+	layer := &Layer{Neurons: make([]*NanoNeuron, size)}
+	for i := range layer.Neurons {
+		layer.Neurons[i] = NewNanoNeuron(rng)
+	}
+	return layer
+}
+
+// Train trains every neuron of the layer on the same data-set (see Train).
+// The results are in the order of the neurons.
+func (l *Layer) Train(xTrain, yTrain []float64, epochs int, alpha float64, opts TrainOptions) ([]*TrainingResult, error) {
+	results := make([]*TrainingResult, len(l.Neurons))
+	for i, neuron := range l.Neurons {
+		result, err := Train(neuron, xTrain, yTrain, epochs, alpha, opts)
+		if err != nil {
+			return nil, fmt.Errorf("neuron %d: %w", i, err)
+		}
+		results[i] = result
+	}
+	return results, nil
+}
+
+// PredictMean is the average prediction of all the neurons of the layer (0 for an empty layer).
+func (l *Layer) PredictMean(x float64) float64 {
+	predictions := make([]float64, len(l.Neurons))
+	for i, neuron := range l.Neurons {
+		predictions[i] = neuron.predict(x)
+	}
+	return mean(predictions)
+}
@@ -0,0 +1,22 @@
+package main
+
+import "testing"
+
+func TestLayerMeanVariesLessAcrossSeedsThanANeuron(t *testing.T) {
+	// Half trained, the predictions still depend on the random start of the models.
+	x, y := generateDataSets(0, 0, 0, nil)
+	const seeds, size, epochs, probe = 30, 8, 300, 50.0
+	var single, layered []float64
+	for seed := range int64(seeds) {
+		layer := NewLayer(size, NewRandSource(seed))
+		if _, err := layer.Train(x, y, epochs, 0.0005, TrainOptions{}); err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+		single = append(single, layer.Neurons[0].predict(probe))
+		layered = append(layered, layer.PredictMean(probe))
+	}
+	singleStd, layerStd := stdDev(single, mean(single)), stdDev(layered, mean(layered))
+	if !(layerStd < singleStd/2) {
+		t.Errorf("the predictions of a neuron vary by %v across seeds, the ones of the layer by %v", singleStd, layerStd)
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// LoadDataSetCSV reads a two-column 'x,y' data-set, one example per row.
+// A first row that isn't numeric is treated as a header and skipped.
+// Gzip-compressed input is detected by its magic bytes and decompressed transparently,
+// so both data.csv and data.csv.gz can be passed in as they are.
+// It is LoadDataSetDelimited with a comma, so comment lines starting with '#' are skipped too.
+func LoadDataSetCSV(r io.Reader) (x, y []float64, err error) {
+	return LoadDataSetDelimited(r, ',')
+}
+
+// LoadDataSetDelimited reads a two-column data-set like LoadDataSetCSV, with the columns
+// separated by 'delim' instead of a comma, i.e. '\t' or ' '. Blank lines and comment lines
+// starting with '#' are skipped. With a whitespace delimiter a run of them separates the
+// columns, so the columns may be aligned.
+func LoadDataSetDelimited(r io.Reader, delim rune) (x, y []float64, err error) {
+	r, err = maybeGunzip(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	reader := csv.NewReader(r)
+	reader.Comma = delim
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+	records := 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		records++
+		xv, xErr := strconv.ParseFloat(strings.TrimSpace(record[0]), 64)
+		yv, yErr := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		if xErr != nil || yErr != nil {
+			if records == 1 {
+				continue // header
+			}
+			// The line in the file, counting the skipped blank and comment lines too.
+			line, _ := reader.FieldPos(0)
+			if xErr == nil {
+				xErr = yErr
+			}
+			return nil, nil, fmt.Errorf("line %d: %w", line, xErr)
+		}
+		x = append(x, xv)
+		y = append(y, yv)
+	}
+	return x, y, nil
+}
+
+// LoadMultiFeatureCSV reads a multi-feature data-set picking the feature columns 'featureCols'
+// and the target column 'targetCol' (0-based) of every row; the other columns are ignored.
+// The rows of X are the examples, in the order of 'featureCols'. Like in LoadDataSetCSV a first
+// row that isn't numeric is treated as a header and gzip-compressed input is detected.
+// All the rows must have the same number of columns and the columns must exist.
+func LoadMultiFeatureCSV(r io.Reader, featureCols []int, targetCol int) (X [][]float64, y []float64, err error) {
+	r, err = maybeGunzip(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	columns := append(slices.Clone(featureCols), targetCol)
+	line := 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		line++
+		if line == 1 {
+			for _, column := range columns {
+				if column < 0 || column >= len(record) {
+					return nil, nil, fmt.Errorf("line %d: column %d out of range, the rows have %d columns", line, column, len(record))
+				}
+			}
+		}
+		values := make([]float64, len(columns))
+		for i, column := range columns {
+			v, err := strconv.ParseFloat(strings.TrimSpace(record[column]), 64)
+			if err != nil {
+				if line == 1 {
+					values = nil // header
+					break
+				}
+				return nil, nil, fmt.Errorf("line %d, column %d: %w", line, column, err)
+			}
+			values[i] = v
+		}
+		if values == nil {
+			continue
+		}
+		X = append(X, values[:len(featureCols):len(featureCols)])
+		y = append(y, values[len(featureCols)])
+	}
+	return X, y, nil
+}
+
+// LoadDataSetJSON reads a data-set in either of the two usual JSON shapes: an object of two
+// arrays {"x": [...], "y": [...]} or an array of examples [{"x": ..., "y": ...}, ...].
+// Arrays of different lengths are reported as ErrLengthMismatch. Gzip-compressed input is
+// decompressed transparently, like in LoadDataSetCSV.
+func LoadDataSetJSON(r io.Reader) (x, y []float64, err error) {
+	r, err = maybeGunzip(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	var raw json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, nil, err
+	}
+	trimmed := strings.TrimSpace(string(raw))
+	if strings.HasPrefix(trimmed, "[") {
+		var examples []struct {
+			X *float64 `json:"x"`
+			Y *float64 `json:"y"`
+		}
+		if err := json.Unmarshal(raw, &examples); err != nil {
+			return nil, nil, err
+		}
+		for i, example := range examples {
+			if example.X == nil || example.Y == nil {
+				return nil, nil, fmt.Errorf("example %d: missing x or y", i)
+			}
+			x = append(x, *example.X)
+			y = append(y, *example.Y)
+		}
+		return x, y, nil
+	}
+	var columns struct {
+		X []float64 `json:"x"`
+		Y []float64 `json:"y"`
+	}
+	if err := json.Unmarshal(raw, &columns); err != nil {
+		return nil, nil, err
+	}
+	if len(columns.X) != len(columns.Y) {
+		return nil, nil, fmt.Errorf("%w: %d x values but %d y values", ErrLengthMismatch, len(columns.X), len(columns.Y))
+	}
+	return columns.X, columns.Y, nil
+}
+
+// maybeGunzip wraps 'r' into a gzip reader if the content starts with the gzip magic bytes.
+func maybeGunzip(r io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(r)
+	magic, err := buffered.Peek(2)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		return gzip.NewReader(buffered)
+	}
+	return buffered, nil
+}
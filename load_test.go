@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLoadDataSetCSVGzip(t *testing.T) {
+	const data = "celsius,fahrenheit\n0,32\n10,50\n-40,-40\n"
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	zw.Write([]byte(data))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for name, input := range map[string]*bytes.Reader{
+		"gzip":  bytes.NewReader(compressed.Bytes()),
+		"plain": bytes.NewReader([]byte(data)),
+	} {
+		x, y, err := LoadDataSetCSV(input)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if !slices.Equal(x, []float64{0, 10, -40}) || !slices.Equal(y, []float64{32, 50, -40}) {
+			t.Errorf("%s: got x=%v y=%v", name, x, y)
+		}
+	}
+}
+
+func TestLoadDataSetCSVReportsTheBadLine(t *testing.T) {
+	_, _, err := LoadDataSetCSV(strings.NewReader("x,y\n0,32\n10,fifty\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("got %v, want an error on line 3", err)
+	}
+}
+
+func TestLoadDataSetJSONShapes(t *testing.T) {
+	for name, input := range map[string]string{
+		"columns":  `{"x": [0, 10, -40], "y": [32, 50, -40]}`,
+		"examples": ` [{"x": 0, "y": 32}, {"y": 50, "x": 10}, {"x": -40, "y": -40}]`,
+	} {
+		x, y, err := LoadDataSetJSON(strings.NewReader(input))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if !slices.Equal(x, []float64{0, 10, -40}) || !slices.Equal(y, []float64{32, 50, -40}) {
+			t.Errorf("%s: got x=%v y=%v", name, x, y)
+		}
+	}
+}
+
+func TestLoadDataSetJSONRejectsBadData(t *testing.T) {
+	if _, _, err := LoadDataSetJSON(strings.NewReader(`{"x": [0, 10], "y": [32]}`)); !errors.Is(err, ErrLengthMismatch) {
+		t.Errorf("got %v for columns of different lengths, want ErrLengthMismatch", err)
+	}
+	for _, input := range []string{`[{"x": 0, "y": 32}, {"x": 10}]`, `{"x": [0], "y": ["hot"]}`, `[1, 2]`, ``} {
+		if _, _, err := LoadDataSetJSON(strings.NewReader(input)); err == nil {
+			t.Errorf("loaded %q without an error", input)
+		}
+	}
+}
+
+func TestLoadMultiFeatureCSVSelectsTheColumns(t *testing.T) {
+	const data = "id,size,rooms,price\n1,50,2,100\n2,80,3,170\n3,120,5,260\n"
+	X, y, err := LoadMultiFeatureCSV(strings.NewReader(data), []int{2, 1}, 3)
+	if err != nil {
+		t.Fatalf("LoadMultiFeatureCSV: %v", err)
+	}
+	wantX := [][]float64{{2, 50}, {3, 80}, {5, 120}}
+	if !slices.EqualFunc(X, wantX, slices.Equal[[]float64]) || !slices.Equal(y, []float64{100, 170, 260}) {
+		t.Errorf("got X=%v y=%v, want X=%v y=[100 170 260]", X, y, wantX)
+	}
+
+	for _, test := range []struct {
+		name, data string
+		features   []int
+		target     int
+		want       string
+	}{
+		{"feature out of range", data, []int{1, 4}, 3, "column 4 out of range"},
+		{"negative target", data, []int{1}, -1, "column -1 out of range"},
+		{"ragged row", "1,50,2,100\n2,80,170\n", []int{1, 2}, 3, "line 2"},
+		{"bad value", "1,50,2,100\n2,eighty,3,170\n", []int{1, 2}, 3, "line 2, column 1"},
+	} {
+		_, _, err := LoadMultiFeatureCSV(strings.NewReader(test.data), test.features, test.target)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: got %v, want an error with %q", test.name, err, test.want)
+		}
+	}
+}
+
+func TestLoadDataSetDelimitedTabs(t *testing.T) {
+	const data = "# Celsius to Fahrenheit\ncelsius\tfahrenheit\n0\t32\n\n# freezing and boiling\n100\t212\n-40\t-40\n"
+	x, y, err := LoadDataSetDelimited(strings.NewReader(data), '\t')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(x, []float64{0, 100, -40}) || !slices.Equal(y, []float64{32, 212, -40}) {
+		t.Errorf("got x=%v y=%v", x, y)
+	}
+
+	x, y, err = LoadDataSetDelimited(strings.NewReader("  0   32\n100  212\n"), ' ')
+	if err != nil || !slices.Equal(x, []float64{0, 100}) || !slices.Equal(y, []float64{32, 212}) {
+		t.Errorf("aligned columns: got x=%v y=%v, %v", x, y, err)
+	}
+
+	// The bad row is the 3rd record but the 6th line of the file.
+	const bad = "celsius\tfahrenheit\n# a comment\n0\t32\n\n# another one\n100\thot\n"
+	if _, _, err := LoadDataSetDelimited(strings.NewReader(bad), '\t'); err == nil || !strings.HasPrefix(err.Error(), "line 6:") {
+		t.Errorf("got %v, want the error on line 6", err)
+	}
+}
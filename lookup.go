@@ -0,0 +1,39 @@
+package main
+
+import "fmt"
+
+// LookupTable holds the predictions of a model precomputed on an evenly spaced grid of inputs,
+// for the fastest inference on a known input range: a lookup is an index computation and a
+// linear interpolation, whatever the activation and the output transform of the model.
+// Create it with NanoNeuron.ToLookupTable.
+type LookupTable struct {
+	lo, step float64
+	values   []float64
+}
+
+// ToLookupTable precomputes the predictions of the model on 'points' evenly spaced inputs from
+// 'lo' to 'hi', both included. Between the grid points Lookup interpolates linearly, so the
+// table of a plain linear model is exact up to rounding and the error of a curved one shrinks
+// with the square of the grid spacing. It panics unless points >= 2 and lo < hi.
+func (n *NanoNeuron) ToLookupTable(lo, hi float64, points int) LookupTable {
+	if points < 2 || !(lo < hi) {
+		panic(fmt.Errorf("lookup table: %w: %d points on [%v, %v]", ErrInvalidHyperparameter, points, lo, hi))
+	}
+	step := (hi - lo) / float64(points-1)
+	values := make([]float64, points)
+	for i := range values {
+		values[i] = n.predict(lo + float64(i)*step)
+	}
+	return LookupTable{lo: lo, step: step, values: values}
+}
+
+// Lookup returns the prediction for 'x' interpolated linearly between the two nearest grid
+// points. Inputs outside of the range of the table are extrapolated linearly from its first
+// or its last two points, which for a curved model gets the worse the further away they are.
+func (t LookupTable) Lookup(x float64) float64 {
+	position := (x - t.lo) / t.step
+	// Outside of the range the first or the last segment is extended.
+	i := min(max(int(position), 0), len(t.values)-2)
+	fraction := position - float64(i)
+	return t.values[i] + fraction*(t.values[i+1]-t.values[i])
+}
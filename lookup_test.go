@@ -0,0 +1,26 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLookupTableInterpolatesThePredictions(t *testing.T) {
+	linear := &NanoNeuron{w: 1.8, b: 32}
+	table := linear.ToLookupTable(-50, 150, 21)
+	for _, x := range []float64{-50, -12.3, 0, 37.5, 100, 150, -80, 400} {
+		// The linear model is exact on, between and (extrapolated) outside the grid points.
+		if got, want := table.Lookup(x), linear.predict(x); math.Abs(got-want) > 1e-9 {
+			t.Errorf("linear lookup at %v is %v, want %v", x, got, want)
+		}
+	}
+
+	// The error of a curved model is bounded by h²/8 * max|f''|, below 1e-3 for h = 0.1.
+	sigmoid := &NanoNeuron{w: 0.5, b: -2, activation: Sigmoid{}}
+	table = sigmoid.ToLookupTable(-10, 10, 201)
+	for x := -10.0; x <= 10; x += 0.0137 {
+		if got, want := table.Lookup(x), sigmoid.predict(x); math.Abs(got-want) > 1e-3 {
+			t.Errorf("sigmoid lookup at %v is %v, want %v", x, got, want)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"container/list"
+	"math"
+	"sync"
+)
+
+// MemoizingModel caches the predictions of a model for inputs that come again and again,
+// i.e. when serving the same temperatures over and over. The model doesn't change at inference,
+// so a cached prediction is always correct. At most MaxSize predictions are kept; when the
+// cache is full the least recently used one is evicted. It is safe for concurrent use.
+type MemoizingModel struct {
+	model   *NanoNeuron
+	maxSize int
+
+	mu      sync.Mutex
+	entries map[uint64]*list.Element // keyed by the exact bits of the input
+	lru     *list.List               // most recently used at the front
+}
+
+type memoEntry struct {
+	key        uint64
+	prediction float64
+}
+
+// NewMemoizingModel wraps 'model' with a prediction cache holding up to 'maxSize' entries.
+func NewMemoizingModel(model *NanoNeuron, maxSize int) *MemoizingModel {
+	return &MemoizingModel{
+		model:   model,
+		maxSize: maxSize,
+		entries: make(map[uint64]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// Predict returns the cached prediction for 'x' or predicts and caches it.
+func (m *MemoizingModel) Predict(x float64) float64 {
+	key := math.Float64bits(x)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if element, ok := m.entries[key]; ok {
+		m.lru.MoveToFront(element)
+		return element.Value.(*memoEntry).prediction
+	}
+	prediction := m.model.predict(x)
+	if m.maxSize <= 0 {
+		return prediction
+	}
+	if m.lru.Len() >= m.maxSize {
+		oldest := m.lru.Back()
+		m.lru.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoEntry).key)
+	}
+	m.entries[key] = m.lru.PushFront(&memoEntry{key: key, prediction: prediction})
+	return prediction
+}
+
+// Len is the number of cached predictions.
+func (m *MemoizingModel) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lru.Len()
+}
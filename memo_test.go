@@ -0,0 +1,32 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMemoizingModelMatchesAndEvicts(t *testing.T) {
+	model := &NanoNeuron{w: 1.8, b: 32}
+	memo := NewMemoizingModel(model, 2)
+	for _, x := range []float64{10, 20, 10, 30, 10, 20} {
+		if got, want := memo.Predict(x), model.predict(x); got != want {
+			t.Errorf("cached prediction for %v is %v, want %v", x, got, want)
+		}
+		if memo.Len() > 2 {
+			t.Fatalf("%d cached predictions, limit 2", memo.Len())
+		}
+	}
+	// 10 was used recently all along, 20 was evicted by 30 and has just evicted 30 in turn.
+	if _, ok := memo.entries[math.Float64bits(10)]; !ok {
+		t.Error("the most recently used input was evicted")
+	}
+	if _, ok := memo.entries[math.Float64bits(30)]; ok {
+		t.Error("the least recently used input is still cached")
+	}
+
+	uncached := NewMemoizingModel(model, 0)
+	uncached.Predict(1)
+	if uncached.Len() != 0 {
+		t.Errorf("a cache of size 0 keeps %d predictions", uncached.Len())
+	}
+}
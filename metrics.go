@@ -0,0 +1,273 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+)
+
+// PercentileResiduals returns the requested percentiles (0..100) of the absolute residuals
+// |y - prediction|, i.e. 50, 90 and 99 to see how big the typical and the worst errors are.
+// Values between two residuals are linearly interpolated.
+func PercentileResiduals(model *NanoNeuron, x, y []float64, percentiles []float64) ([]float64, error) {
+	if err := validateDataSet(x, y); err != nil {
+		return nil, fmt.Errorf("percentile residuals: %w", err)
+	}
+	residuals := make([]float64, len(x))
+	for i := range x {
+		residuals[i] = math.Abs(y[i] - model.predict(x[i]))
+	}
+	sort.Float64s(residuals)
+
+	result := make([]float64, len(percentiles))
+	for i, p := range percentiles {
+		if !(p >= 0 && p <= 100) {
+			return nil, fmt.Errorf("percentile residuals: percentile %v is out of the [0, 100] range", p)
+		}
+		result[i] = percentile(residuals, p)
+	}
+	return result, nil
+}
+
+// percentile interpolates the 'p'-th percentile of the sorted 'values'.
+func percentile(sorted []float64, p float64) float64 {
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	fraction := rank - float64(lower)
+	return sorted[lower] + (sorted[upper]-sorted[lower])*fraction
+}
+
+// ConvergenceRate fits an exponential decay cost = c * e^(-k*epoch) to the cost history
+// (least squares on the logarithm of the costs) and returns the decay constant 'k'.
+// The larger it is, the faster the training converges, so it allows to compare optimizers
+// and learning rates objectively. Zero is returned when the cost doesn't decrease.
+// Costs that aren't positive finite numbers are left out of the fit.
+func ConvergenceRate(history []float64) float64 {
+	var n, epochMean, logMean float64
+	for epoch, cost := range history {
+		if cost > 0 && isFinite(cost) {
+			n++
+			epochMean += float64(epoch)
+			logMean += math.Log(cost)
+		}
+	}
+	if n < 2 {
+		return 0
+	}
+	epochMean /= n
+	logMean /= n
+	covariance, variance := 0.0, 0.0
+	for epoch, cost := range history {
+		if cost > 0 && isFinite(cost) {
+			covariance += (float64(epoch) - epochMean) * (math.Log(cost) - logMean)
+			variance += (float64(epoch) - epochMean) * (float64(epoch) - epochMean)
+		}
+	}
+	return math.Max(0, -covariance/variance)
+}
+
+// EstimateEpochsForCost extrapolates the exponential decay of the cost history (see
+// ConvergenceRate) to the number of further epochs needed to reach 'targetCost'. The decay is
+// fitted to the later half of the history only, as the first epochs usually fall much faster
+// than the rest. Zero is returned when the target is already reached and -1 when it's
+// unreachable: the cost has plateaued above it or the target isn't positive.
+func EstimateEpochsForCost(history []float64, targetCost float64) int {
+	if len(history) == 0 || !(targetCost > 0) {
+		return -1
+	}
+	last := history[len(history)-1]
+	if last <= targetCost {
+		return 0
+	}
+	rate := ConvergenceRate(history[len(history)/2:])
+	if rate == 0 || !isFinite(last) {
+		return -1
+	}
+	epochs := math.Ceil(math.Log(last/targetCost) / rate)
+	if epochs > math.MaxInt32 {
+		return -1
+	}
+	return int(epochs)
+}
+
+// Metrics are the usual measures of how well a model fits a data-set.
+type Metrics struct {
+	Cost     float64 // the average prediction cost, as reported by the training
+	RMSE     float64 // root mean squared error
+	MAE      float64 // mean absolute error
+	R2       float64 // coefficient of determination, 1 is a perfect fit (NaN for constant labels)
+	MaxError float64 // the largest absolute error
+}
+
+// Evaluate computes all the Metrics of the model on the data-set in a single pass over it.
+// The metrics of an empty data-set are all NaN.
+func Evaluate(model *NanoNeuron, x, y []float64) Metrics {
+	return EvaluateWeighted(model, x, y, nil)
+}
+
+// EvaluateSafe is Evaluate for data-sets from outside: instead of the NaN metrics of no
+// examples it returns ErrEmptyDataSet, and ErrLengthMismatch instead of panicking.
+func EvaluateSafe(model *NanoNeuron, x, y []float64) (Metrics, error) {
+	if err := validateDataSet(x, y); err != nil {
+		return Metrics{}, fmt.Errorf("evaluate: %w", err)
+	}
+	return Evaluate(model, x, y), nil
+}
+
+// EvaluateWeighted is Evaluate where every example counts 'weights' times, consistent with
+// the weighted training of TrainOptions.Weights: the averages are normalized by the sum of
+// the weights. Nil weights count every example once. MaxError ignores the weights of the
+// examples, except that the examples of zero weight are left out.
+func EvaluateWeighted(model *NanoNeuron, x, y, weights []float64) Metrics {
+	mustMatch("evaluate", x, y)
+	if weights != nil {
+		mustMatch("evaluate", x, weights)
+	}
+	e := NewEvaluator(model)
+	for i := range x {
+		weight := 1.0
+		if weights != nil {
+			weight = weights[i]
+		}
+		e.add(x[i], y[i], weight)
+	}
+	return e.Metrics()
+}
+
+// Evaluator computes the Metrics of a model on a data-set that streams in, one example at
+// a time, without keeping the examples: only the sums of the errors and the running mean and
+// spread of the labels (Welford's algorithm) are kept. Create it with NewEvaluator.
+type Evaluator struct {
+	model *NanoNeuron
+
+	squares, absolutes, maxError float64
+	total, yMean, ySquares       float64
+}
+
+// NewEvaluator creates an Evaluator of 'model' that hasn't seen any example yet.
+func NewEvaluator(model *NanoNeuron) *Evaluator {
+	return &Evaluator{model: model}
+}
+
+// Add evaluates the model on the example ('x', 'y').
+func (e *Evaluator) Add(x, y float64) {
+	e.add(x, y, 1)
+}
+
+// add evaluates the model on the example ('x', 'y') counted 'weight' times.
+func (e *Evaluator) add(x, y, weight float64) {
+	if weight == 0 {
+		return
+	}
+	residual := y - e.model.predict(x)
+	e.squares += weight * residual * residual
+	e.absolutes += weight * math.Abs(residual)
+	e.maxError = math.Max(e.maxError, math.Abs(residual))
+
+	e.total += weight
+	delta := y - e.yMean
+	e.yMean += delta * weight / e.total
+	e.ySquares += weight * delta * (y - e.yMean)
+}
+
+// Metrics are the metrics of the examples added so far, exactly as Evaluate computes them on
+// all of them at once. Without any example all of them are NaN.
+func (e *Evaluator) Metrics() Metrics {
+	if e.total == 0 {
+		nan := math.NaN()
+		return Metrics{Cost: nan, RMSE: nan, MAE: nan, R2: nan, MaxError: nan}
+	}
+	r2 := math.NaN()
+	if e.ySquares > 0 {
+		r2 = 1 - e.squares/e.ySquares
+	}
+	return Metrics{
+		Cost:     e.squares * costScale / e.total,
+		RMSE:     math.Sqrt(e.squares / e.total),
+		MAE:      e.absolutes / e.total,
+		R2:       r2,
+		MaxError: e.maxError,
+	}
+}
+
+// ParameterDistance is the Euclidean distance between the (w, b) parameter vectors of two
+// models, i.e. to study how far apart trainings with different seeds end up.
+func ParameterDistance(a, b *NanoNeuron) float64 {
+	return math.Hypot(a.w-b.w, a.b-b.b)
+}
+
+// ResidualStats returns the mean and the (population) standard deviation of the residuals
+// y - prediction of the model on the data-set, i.e. the 'residualStd' of PredictInterval.
+func ResidualStats(model *NanoNeuron, x, y []float64) (residualMean, residualStd float64, err error) {
+	if err := validateDataSet(x, y); err != nil {
+		return 0, 0, fmt.Errorf("residual stats: %w", err)
+	}
+	residuals := make([]float64, len(x))
+	for i := range x {
+		residuals[i] = y[i] - model.predict(x[i])
+	}
+	residualMean = mean(residuals)
+	return residualMean, stdDev(residuals, residualMean), nil
+}
+
+// SkillScore compares the model with the most naive baseline, always predicting the mean label:
+// 1 - modelCost / baselineCost. Close to 1 the model is much better than the baseline, 0 means
+// no better and negative values mean even worse. It is NaN for constant labels (the baseline is
+// perfect) and for an empty data-set.
+func SkillScore(model *NanoNeuron, x, y []float64) float64 {
+	mustMatch("skill score", x, y)
+	yMean := mean(y)
+	modelCost, baselineCost := 0.0, 0.0
+	for i := range x {
+		modelCost += predictionCost(y[i], model.predict(x[i]))
+		baselineCost += predictionCost(y[i], yMean)
+	}
+	if baselineCost == 0 {
+		return math.NaN()
+	}
+	return 1 - modelCost/baselineCost
+}
+
+// BinStat is the error of the model on the examples of one input range [Lo, Hi).
+type BinStat struct {
+	Lo        float64
+	Hi        float64
+	Count     int
+	MeanError float64 // mean absolute error of the examples of the bin (NaN for an empty bin)
+}
+
+// ErrorByBin splits the input range [min(x), max(x)] into 'bins' equally wide bins and reports
+// the mean absolute error of every bin, revealing the regions where the model fits poorly.
+// The last bin includes max(x). Nil is returned for an empty data-set or bins < 1.
+func ErrorByBin(model *NanoNeuron, x, y []float64, bins int) []BinStat {
+	mustMatch("error by bin", x, y)
+	if len(x) == 0 || bins < 1 {
+		return nil
+	}
+	lo, hi := slices.Min(x), slices.Max(x)
+	width := (hi - lo) / float64(bins)
+	stats := make([]BinStat, bins)
+	sums := make([]float64, bins)
+	for i := range stats {
+		stats[i].Lo = lo + float64(i)*width
+		stats[i].Hi = lo + float64(i+1)*width
+	}
+	stats[bins-1].Hi = hi
+	for i := range x {
+		bin := 0
+		if width > 0 {
+			bin = min(int((x[i]-lo)/width), bins-1)
+		}
+		stats[bin].Count++
+		sums[bin] += math.Abs(y[i] - model.predict(x[i]))
+	}
+	for i := range stats {
+		stats[i].MeanError = math.NaN()
+		if stats[i].Count > 0 {
+			stats[i].MeanError = sums[i] / float64(stats[i].Count)
+		}
+	}
+	return stats
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPercentileResidualsOfKnownResiduals(t *testing.T) {
+	// The residuals of the identity model are 0, 1, 2, ..., 10 in absolute value.
+	model := &NanoNeuron{w: 1}
+	var x, y []float64
+	for i := range 11 {
+		side := float64(1 - 2*(i%2))
+		x = append(x, float64(i))
+		y = append(y, float64(i)+side*float64(i))
+	}
+	got, err := PercentileResiduals(model, x, y, []float64{0, 50, 90, 95, 100})
+	if err != nil {
+		t.Fatalf("PercentileResiduals: %v", err)
+	}
+	for i, want := range []float64{0, 5, 9, 9.5, 10} {
+		if math.Abs(got[i]-want) > 1e-12 {
+			t.Errorf("percentile %d is %v, want %v", i, got[i], want)
+		}
+	}
+
+	for _, p := range []float64{-1, 100.5, math.NaN()} {
+		if _, err := PercentileResiduals(model, x, y, []float64{p}); err == nil {
+			t.Errorf("no error for percentile %v", p)
+		}
+	}
+}
+
+func TestConvergenceRate(t *testing.T) {
+	history := make([]float64, 50)
+	for epoch := range history {
+		history[epoch] = 300 * math.Exp(-0.2*float64(epoch))
+	}
+	if k := ConvergenceRate(history); math.Abs(k-0.2) > 1e-12 {
+		t.Errorf("the rate of an exact decay by 0.2 is %v", k)
+	}
+	for _, flat := range [][]float64{{5, 5, 5}, {1, 2, 3}, {7}, nil} {
+		if k := ConvergenceRate(flat); k != 0 {
+			t.Errorf("the rate of %v is %v, want 0", flat, k)
+		}
+	}
+}
+
+func TestConvergenceRateComparesOptimizers(t *testing.T) {
+	// On the badly scaled Celsius inputs the plain gradient descent crawls along the valley
+	// while Adam scales the steps of 'w' and 'b' separately.
+	x, y := generateDataSets(0, 0, 0, nil)
+	sgd := trainModel(NewNanoNeuron(NewRandSource(1)), 2000, 0.0005, x, y, TrainOptions{})
+	adam := trainModel(NewNanoNeuron(NewRandSource(1)), 2000, 0.1, x, y, TrainOptions{Optimizer: NewAdam(0.9, 0.999)})
+	sgdRate, adamRate := ConvergenceRate(sgd.CostHistory), ConvergenceRate(adam.CostHistory)
+	if !(sgdRate > 0) || !(adamRate > 10*sgdRate) {
+		t.Errorf("convergence rate of SGD %v and of Adam %v, want Adam at least 10 times faster", sgdRate, adamRate)
+	}
+}
+
+func TestEstimateEpochsForCost(t *testing.T) {
+	history := make([]float64, 50)
+	for epoch := range history {
+		history[epoch] = 300 * math.Exp(-0.2*float64(epoch))
+	}
+	// The last epoch is 49, the cost of epoch 59.5 needs another 10.5, so 11 epochs.
+	if epochs := EstimateEpochsForCost(history, 300*math.Exp(-0.2*59.5)); epochs != 11 {
+		t.Errorf("estimated %d epochs, want 11", epochs)
+	}
+	if epochs := EstimateEpochsForCost(history, 1); epochs != 0 {
+		t.Errorf("estimated %d epochs for a reached target, want 0", epochs)
+	}
+
+	plateau := append(history[:25:25], make([]float64, 25)...)
+	for epoch := 25; epoch < len(plateau); epoch++ {
+		plateau[epoch] = 1
+	}
+	for _, target := range []float64{0.5, 0} {
+		if epochs := EstimateEpochsForCost(plateau, target); epochs != -1 {
+			t.Errorf("estimated %d epochs for the unreachable %v, want -1", epochs, target)
+		}
+	}
+}
+
+func TestEvaluatorStreamsTheMetricsOfEvaluate(t *testing.T) {
+	x, y := generateDataSets(0, 3, 0.05, NewRandSource(1))
+	model := &NanoNeuron{w: 1.7, b: 35}
+	evaluator := NewEvaluator(model)
+	if metrics := evaluator.Metrics(); !math.IsNaN(metrics.Cost) || !math.IsNaN(metrics.R2) {
+		t.Errorf("the metrics without examples are %+v, want NaN", metrics)
+	}
+	for i := range x {
+		evaluator.Add(x[i], y[i])
+	}
+	if got, want := evaluator.Metrics(), Evaluate(model, x, y); got != want {
+		t.Errorf("streamed %+v, want %+v", got, want)
+	}
+}
+
+func TestEvaluateMatchesTheSeparateMetrics(t *testing.T) {
+	x, y := generateDataSets(0, 3, 0.05, NewRandSource(1))
+	model := &NanoNeuron{w: 1.7, b: 35}
+	metrics := Evaluate(model, x, y)
+
+	_, cost := forwardPropagation(model, x, y)
+	absolutes, squares := 0.0, 0.0
+	for i := range x {
+		residual := y[i] - model.predict(x[i])
+		absolutes += math.Abs(residual)
+		squares += residual * residual
+	}
+	worst, err := PercentileResiduals(model, x, y, []float64{100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := float64(len(x))
+	mean := 0.0
+	for _, label := range y {
+		mean += label / n
+	}
+	spread := 0.0
+	for _, label := range y {
+		spread += (label - mean) * (label - mean)
+	}
+	for _, test := range []struct {
+		name      string
+		got, want float64
+	}{
+
+		{"cost", metrics.Cost, cost},
+		{"RMSE", metrics.RMSE, math.Sqrt(squares / n)},
+		{"MAE", metrics.MAE, absolutes / n},
+		{"R²", metrics.R2, 1 - squares/spread},
+		{"max error", metrics.MaxError, worst[0]},
+	} {
+		if math.Abs(test.got-test.want) > 1e-9*math.Max(1, math.Abs(test.want)) {
+			t.Errorf("%s is %v, want %v", test.name, test.got, test.want)
+		}
+	}
+}
+
+func TestParameterDistance(t *testing.T) {
+	a := &NanoNeuron{w: 1.8, b: 32}
+	if d := ParameterDistance(a, &NanoNeuron{w: 1.8, b: 32}); d != 0 {
+		t.Errorf("identical models are %v apart", d)
+	}
+	if d := ParameterDistance(a, &NanoNeuron{w: 4.8, b: 36}); math.Abs(d-5) > 1e-12 {
+		t.Errorf("got %v, want 5", d)
+	}
+	previous := 0.0
+	for _, offset := range []float64{0.1, 1, 10, 100} {
+		d := ParameterDistance(a, &NanoNeuron{w: a.w + offset, b: a.b - offset})
+		if d <= previous {
+			t.Errorf("moving the parameters by %v got them %v apart, not further than %v", offset, d, previous)
+		}
+		previous = d
+	}
+}
+
+func TestEvaluateWeightedMatchesTheWeightedResiduals(t *testing.T) {
+	model := &NanoNeuron{w: 2, b: 1}
+	x := []float64{0, 1, 2, 3}
+	y := []float64{1, 4, 4, 10} // residuals 0, 1, -1, 3
+	weights := []float64{1, 2, 0, 0.5}
+	metrics := EvaluateWeighted(model, x, y, weights)
+
+	total := 1 + 2 + 0 + 0.5
+	squares := 1*0.0 + 2*1 + 0*1 + 0.5*9
+	absolutes := 1*0.0 + 2*1 + 0*1 + 0.5*3
+	for _, test := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"cost", metrics.Cost, costScale * squares / total},
+		{"RMSE", metrics.RMSE, math.Sqrt(squares / total)},
+		{"MAE", metrics.MAE, absolutes / total},
+		{"max error", metrics.MaxError, 3},
+	} {
+		if math.Abs(test.got-test.want) > 1e-12 {
+			t.Errorf("%s is %v, want %v", test.name, test.got, test.want)
+		}
+	}
+
+	if unweighted, plain := EvaluateWeighted(model, x, y, nil), Evaluate(model, x, y); unweighted != plain {
+		t.Errorf("nil weights give %+v, want %+v", unweighted, plain)
+	}
+}
+
+func TestSkillScore(t *testing.T) {
+	x, y := generateDataSets(0, 1, 0, NewRandSource(1))
+	trained := NewNanoNeuron(NewRandSource(1))
+	trainModel(trained, 70000, 0.0005, x, y, TrainOptions{})
+	if score := SkillScore(trained, x, y); score < 0.99 {
+		t.Errorf("the trained model scored %v, want close to 1", score)
+	}
+	// An untrained model knows at best the mean label, which is exactly the baseline.
+	baseline := &NanoNeuron{w: 0, b: mean(y)}
+	if score := SkillScore(baseline, x, y); math.Abs(score) > 1e-9 {
+		t.Errorf("the mean predictor scored %v, want 0", score)
+	}
+	if score := SkillScore(NewNanoNeuron(NewRandSource(1)), x, y); score > 0 {
+		t.Errorf("the random initialization scored %v, want no better than the baseline", score)
+	}
+	if score := SkillScore(trained, x, make([]float64, len(x))); !math.IsNaN(score) {
+		t.Errorf("constant labels scored %v, want NaN", score)
+	}
+}
+
+func TestErrorByBinFindsTheBadRegion(t *testing.T) {
+	// The identity model fits all the examples except the ones in [80, 100].
+	model := &NanoNeuron{w: 1}
+	var x, y []float64
+	for i := range 101 {
+		x = append(x, float64(i))
+		residual := 0.1
+		if i >= 80 {
+			residual = 5
+		}
+		y = append(y, float64(i)+residual)
+	}
+	stats := ErrorByBin(model, x, y, 5)
+	if len(stats) != 5 {
+		t.Fatalf("got %d bins, want 5", len(stats))
+	}
+	total := 0
+	for i, stat := range stats {
+		total += stat.Count
+		want := 0.1
+		if i == 4 {
+			want = 5
+		}
+		if math.Abs(stat.MeanError-want) > 1e-9 {
+			t.Errorf("bin [%v, %v) has the mean error %v, want %v", stat.Lo, stat.Hi, stat.MeanError, want)
+		}
+	}
+	if total != len(x) || stats[0].Lo != 0 || stats[4].Hi != 100 {
+		t.Errorf("the bins %v don't cover all the %d examples", stats, len(x))
+	}
+	if ErrorByBin(model, nil, nil, 5) != nil || ErrorByBin(model, x, y, 0) != nil {
+		t.Error("expected no bins for an empty data-set or zero bins")
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+// MinimizeOptions are the settings of Minimize.
+type MinimizeOptions struct {
+	// Iterations is the largest number of gradient descent steps.
+	Iterations int
+	// Alpha is the learning rate, the size of the steps relative to the gradient.
+	Alpha float64
+	// Tolerance stops the descent once a step lowers the cost by less than it
+	// (0 runs all the Iterations). A step that raises the cost stops it as well then.
+	Tolerance float64
+	// After is called after every step with its index and the new parameters, which it must
+	// not modify. Returning false stops the descent.
+	After func(iteration int, params []float64) bool
+}
+
+// Minimize is plain gradient descent on any vector of parameters: every step moves the
+// parameters against 'grad', the gradient of 'cost' at them. It is the core of trainModel
+// stripped of NanoNeuron, so anything with a differentiable cost can be trained with it;
+// trainModel itself runs the plain full-batch training through it. 'grad' must return a
+// gradient of the same length as the parameters. The parameters passed in are left alone,
+// the minimum found is returned in a new slice. The descent ends early when the cost is no
+// longer finite. A nil 'cost' is never measured, then only After can end the descent early.
+func Minimize(params []float64, grad func([]float64) []float64, cost func([]float64) float64, opts MinimizeOptions) []float64 {
+	p := append([]float64(nil), params...)
+	var previous float64
+	if cost != nil {
+		previous = cost(p)
+	}
+	for iteration := range opts.Iterations {
+		g := grad(p)
+		mustMatch("minimize", g, p)
+		for i := range p {
+			p[i] -= opts.Alpha * g[i]
+		}
+		if opts.After != nil && !opts.After(iteration, p) {
+			break
+		}
+		if cost == nil {
+			continue
+		}
+		current := cost(p)
+		if !isFinite(current) || (opts.Tolerance > 0 && previous-current < opts.Tolerance) {
+			break
+		}
+		previous = current
+	}
+	return p
+}
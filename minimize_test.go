@@ -0,0 +1,73 @@
+package main
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestMinimizeQuadratic(t *testing.T) {
+	// f(p) = (p - 3)^2 has its minimum at 3.
+	cost := func(p []float64) float64 { return (p[0] - 3) * (p[0] - 3) }
+	grad := func(p []float64) []float64 { return []float64{2 * (p[0] - 3)} }
+	start := []float64{-10}
+	p := Minimize(start, grad, cost, MinimizeOptions{Iterations: 1000, Alpha: 0.1})
+	if math.Abs(p[0]-3) > 1e-9 {
+		t.Errorf("minimized to %v, want 3", p[0])
+	}
+	if start[0] != -10 {
+		t.Errorf("the start parameters changed to %v", start)
+	}
+
+	early := Minimize(start, grad, cost, MinimizeOptions{Iterations: 1000, Alpha: 0.1, Tolerance: 1e-3})
+	if math.Abs(early[0]-3) > 0.1 || early[0] == p[0] {
+		t.Errorf("the tolerance stopped at %v, want close to 3 but before %v", early[0], p[0])
+	}
+}
+
+func TestMinimizeTrainsNanoNeuronLikeTrainModel(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	model := NewNanoNeuron(NewRandSource(1))
+	probe := *model
+	cost := func(p []float64) float64 {
+		_ = probe.SetParameters(p)
+		_, cost := forwardPropagation(&probe, x, y)
+		return cost
+	}
+	grad := func(p []float64) []float64 {
+		_ = probe.SetParameters(p)
+		predictions, _ := forwardPropagation(&probe, x, y)
+		// backwardPropagation returns the downhill direction, the gradient is its negation.
+		dW, dB := backwardPropagation(&probe, predictions, x, y)
+		return []float64{-dW, -dB}
+	}
+	p := Minimize(model.Parameters(), grad, cost, MinimizeOptions{Iterations: 1000, Alpha: 0.0005})
+
+	trainModel(model, 1000, 0.0005, x, y, TrainOptions{})
+	if want := model.Parameters(); p[0] != want[0] || p[1] != want[1] {
+		t.Errorf("Minimize found %v, trainModel %v", p, want)
+	}
+}
+
+func TestTrainModelRunsThePlainDescentThroughMinimize(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	if !(&TrainOptions{RecordParams: true}).plainDescent(len(x), len(x)) {
+		t.Error("the plain full-batch training doesn't go through Minimize")
+	}
+	if (&TrainOptions{}).plainDescent(10, len(x)) {
+		t.Error("the mini-batches go through Minimize")
+	}
+	for _, opts := range []TrainOptions{{Unroll: true}, {Optimizer: NewMomentum(0.9)}, {FreezeB: true}} {
+		if opts.plainDescent(len(x), len(x)) {
+			t.Errorf("%+v goes through Minimize", opts)
+		}
+	}
+
+	// The epoch loop computes exactly the same descent, Unroll only takes it off the Minimize path.
+	viaMinimize, viaLoop := NewNanoNeuron(NewRandSource(1)), NewNanoNeuron(NewRandSource(1))
+	got := trainModel(viaMinimize, 1000, 0.0005, x, y, TrainOptions{})
+	want := trainModel(viaLoop, 1000, 0.0005, x, y, TrainOptions{Unroll: true})
+	if *viaMinimize != *viaLoop || !slices.Equal(got.CostHistory, want.CostHistory) || !slices.Equal(got.GradNormHistory, want.GradNormHistory) {
+		t.Errorf("Minimize trained %v, the epoch loop %v", viaMinimize, viaLoop)
+	}
+}
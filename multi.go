@@ -0,0 +1,258 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// MultiNanoNeuron is a NanoNeuron that has grown up to several inputs.
+// Instead of a single 'x' it looks at a vector of features and learns one weight per feature:
+// y = w[0] * x[0] + w[1] * x[1] + ... + b.
+type MultiNanoNeuron struct {
+	w []float64
+	b float64
+}
+
+// NewMultiNanoNeuron creates a model for 'features' inputs with parameters randomly set up from 'rng'.
+func NewMultiNanoNeuron(features int, rng RandSource) *MultiNanoNeuron {
+	w := make([]float64, features)
+	for i := range w {
+		w[i] = rng.Float64()
+	}
+	return &MultiNanoNeuron{w: w, b: rng.Float64()}
+}
+
+// The same linear dependency as NanoNeuron.predict, just with more than one input.
+func (n *MultiNanoNeuron) predict(x []float64) float64 {
+	y := n.b
+	for i, w := range n.w {
+		y += w * x[i]
+	}
+	return y
+}
+
+// PredictMatrix predicts every row of 'X' (one example per row) at once, for serving.
+// Every row must have one value per weight of the model, otherwise ErrLengthMismatch is
+// returned with the number of the first bad row.
+func (n *MultiNanoNeuron) PredictMatrix(X [][]float64) ([]float64, error) {
+	predictions := make([]float64, len(X))
+	for i, row := range X {
+		if len(row) != len(n.w) {
+			return nil, fmt.Errorf("row %d: %w: %d features but %d weights", i, ErrLengthMismatch, len(row), len(n.w))
+		}
+		predictions[i] = n.predict(row)
+	}
+	return predictions, nil
+}
+
+// FeatureImportance returns the absolute weights normalized to sum up to 1, so the feature
+// with the biggest influence on the prediction has the highest value.
+// Weights are only comparable when the features are on the same scale, so this is meaningful
+// only for a model trained on standardized features. All zeros are returned when every weight is 0.
+func (n *MultiNanoNeuron) FeatureImportance() []float64 {
+	importance := make([]float64, len(n.w))
+	total := 0.0
+	for i, w := range n.w {
+		importance[i] = math.Abs(w)
+		total += importance[i]
+	}
+	if total == 0 {
+		return importance
+	}
+	for i := range importance {
+		importance[i] /= total
+	}
+	return importance
+}
+
+// MultiParameterDistance is ParameterDistance for multi-feature models: the Euclidean distance
+// between all their weights and biases. It is NaN for models of different feature counts.
+func MultiParameterDistance(a, b *MultiNanoNeuron) float64 {
+	if len(a.w) != len(b.w) {
+		return math.NaN()
+	}
+	sum := (a.b - b.b) * (a.b - b.b)
+	for i := range a.w {
+		sum += (a.w[i] - b.w[i]) * (a.w[i] - b.w[i])
+	}
+	return math.Sqrt(sum)
+}
+
+// MultiTrainOptions holds the optional knobs of the multi-feature training process.
+type MultiTrainOptions struct {
+	// DropoutRate is the probability of zeroing each input feature of each training example.
+	// The surviving features are scaled by 1 / (1 - DropoutRate) so the expected input is unchanged.
+	// It makes the model less dependent on any single feature. Dropout is never applied at inference.
+	DropoutRate float64
+	// Rand is the source of the dropout decisions, so runs can be reproduced.
+	// It is required when DropoutRate > 0.
+	Rand RandSource
+	// Rates are per-weight learning rates used instead of 'alpha' for the weights,
+	// so features on very different scales can each get a suitable step size.
+	// 'alpha' is still used for the bias. Nil uses 'alpha' everywhere.
+	Rates []float64
+}
+
+// Train the multi-feature model with the same gradient descent as trainModel.
+// Every row of 'xTrain' is one example with one value per feature.
+func trainMultiModel(model *MultiNanoNeuron, epochs int, alpha float64, xTrain [][]float64, yTrain []float64, opts MultiTrainOptions) *TrainingResult {
+	m := len(xTrain)
+	costHistory := make([]float64, epochs)
+	initialCost := multiCost(model, xTrain, yTrain)
+
+	dW := make([]float64, len(model.w))
+	input := make([]float64, len(model.w))
+	for epoch := 0; epoch < epochs; epoch++ {
+		for j := range dW {
+			dW[j] = 0
+		}
+		dB := 0.0
+		cost := 0.0
+		for i := 0; i < m; i++ {
+			copy(input, xTrain[i])
+			if opts.DropoutRate > 0 {
+				dropout(input, opts.DropoutRate, opts.Rand)
+			}
+			prediction := model.predict(input)
+			cost += predictionCost(yTrain[i], prediction)
+			delta := yTrain[i] - prediction
+			for j := range dW {
+				dW[j] += delta * input[j]
+			}
+			dB += delta
+		}
+		costHistory[epoch] = cost / float64(m)
+
+		for j := range model.w {
+			rate := alpha
+			if opts.Rates != nil {
+				rate = opts.Rates[j]
+			}
+			model.w[j] += rate * dW[j] / float64(m)
+		}
+		model.b += alpha * dB / float64(m)
+	}
+	return &TrainingResult{
+		InitialCost: initialCost,
+		CostHistory: costHistory,
+	}
+}
+
+// TrainMulti is the checked entry point to trainMultiModel, like Train is to trainModel.
+func TrainMulti(model *MultiNanoNeuron, xTrain [][]float64, yTrain []float64, epochs int, alpha float64, opts MultiTrainOptions) (*TrainingResult, error) {
+	if len(xTrain) != len(yTrain) {
+		return nil, fmt.Errorf("training data: %w: %d rows but %d labels", ErrLengthMismatch, len(xTrain), len(yTrain))
+	}
+	if len(xTrain) == 0 {
+		return nil, fmt.Errorf("training data: %w", ErrEmptyDataSet)
+	}
+	for i, row := range xTrain {
+		if len(row) != len(model.w) {
+			return nil, fmt.Errorf("training data: %w: row %d has %d features, the model has %d", ErrLengthMismatch, i, len(row), len(model.w))
+		}
+	}
+	switch {
+	case epochs < 1:
+		return nil, fmt.Errorf("%w: epochs must be positive, got %d", ErrInvalidHyperparameter, epochs)
+	case opts.Rates != nil && len(opts.Rates) != len(model.w):
+		return nil, fmt.Errorf("%w: %d learning rates for %d weights", ErrInvalidHyperparameter, len(opts.Rates), len(model.w))
+	case opts.DropoutRate < 0 || opts.DropoutRate >= 1:
+		return nil, fmt.Errorf("%w: dropout rate must be in [0, 1), got %v", ErrInvalidHyperparameter, opts.DropoutRate)
+	case opts.DropoutRate > 0 && opts.Rand == nil:
+		return nil, fmt.Errorf("%w: dropout needs a random source", ErrInvalidHyperparameter)
+	}
+	return trainMultiModel(model, epochs, alpha, xTrain, yTrain, opts), nil
+}
+
+// multiCost is the average prediction cost of the multi-feature model.
+func multiCost(model *MultiNanoNeuron, x [][]float64, y []float64) float64 {
+	cost := 0.0
+	for i := range x {
+		cost += predictionCost(y[i], model.predict(x[i]))
+	}
+	return cost / float64(len(x))
+}
+
+// dropout zeroes every feature with probability 'rate' and scales the survivors (inverted dropout).
+func dropout(features []float64, rate float64, rng RandSource) {
+	scale := 1 / (1 - rate)
+	for j := range features {
+		if rng.Float64() < rate {
+			features[j] = 0
+		} else {
+			features[j] *= scale
+		}
+	}
+}
+
+// Features whose absolute correlation is above this value are considered near-duplicates.
+const collinearityThreshold = 0.99
+
+// CheckCollinearity finds the features that are (nearly) copies of an earlier feature.
+// 'features' is row-major like the training data of MultiNanoNeuron: one row per example.
+// Duplicated columns slow gradient descent down and make the normal equations singular,
+// so the returned feature indices can simply be dropped (the first feature of every
+// correlated group is kept and never reported). Constant columns have no correlation, but two
+// of them are still multiples of each other, so a constant column after another one is reported
+// too. Rows of different lengths are an ErrLengthMismatch.
+func CheckCollinearity(features [][]float64) ([]int, error) {
+	if len(features) == 0 {
+		return nil, nil
+	}
+	for i, row := range features {
+		if len(row) != len(features[0]) {
+			return nil, fmt.Errorf("%w: row %d has %d features, the first row %d", ErrLengthMismatch, i, len(row), len(features[0]))
+		}
+	}
+	columns := make([][]float64, len(features[0]))
+	for j := range columns {
+		columns[j] = make([]float64, len(features))
+		for i, row := range features {
+			columns[j][i] = row[j]
+		}
+	}
+
+	var duplicates []int
+	for j := 1; j < len(columns); j++ {
+		for k := 0; k < j; k++ {
+			correlation := DescribeDataSet(columns[k], columns[j]).Correlation
+			if math.Abs(correlation) > collinearityThreshold || (math.IsNaN(correlation) && constant(columns[k]) && constant(columns[j])) {
+				duplicates = append(duplicates, j)
+				break
+			}
+		}
+	}
+	return duplicates, nil
+}
+
+// constant tells if all the values are the same.
+func constant(values []float64) bool {
+	for _, v := range values {
+		if v != values[0] {
+			return false
+		}
+	}
+	return true
+}
+
+// TrainColumns trains the multi-feature model on column-major data: one slice per feature
+// instead of one row per example. The columns are transposed into rows internally, so the
+// result is exactly the same as training on the equivalent rows with TrainMulti.
+func TrainColumns(model *MultiNanoNeuron, columns [][]float64, y []float64, epochs int, alpha float64, opts MultiTrainOptions) (*TrainingResult, error) {
+	if len(columns) != len(model.w) {
+		return nil, fmt.Errorf("%w: %d columns for a model with %d features", ErrLengthMismatch, len(columns), len(model.w))
+	}
+	for j, column := range columns {
+		if len(column) != len(y) {
+			return nil, fmt.Errorf("%w: column %d has %d values but there are %d labels", ErrLengthMismatch, j, len(column), len(y))
+		}
+	}
+	rows := make([][]float64, len(y))
+	for i := range rows {
+		rows[i] = make([]float64, len(columns))
+		for j, column := range columns {
+			rows[i][j] = column[i]
+		}
+	}
+	return TrainMulti(model, rows, y, epochs, alpha, opts)
+}
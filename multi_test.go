@@ -0,0 +1,196 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+)
+
+// twoFeatureData is y = 2 * x0 - x1 + 3 on a small grid.
+func twoFeatureData() ([][]float64, []float64) {
+	var X [][]float64
+	var y []float64
+	for i := range 5 {
+		for j := range 5 {
+			X = append(X, []float64{float64(i), float64(j)})
+			y = append(y, 2*float64(i)-float64(j)+3)
+		}
+	}
+	return X, y
+}
+
+func TestDropoutRateZeroIsNoDropout(t *testing.T) {
+	X, y := twoFeatureData()
+	plain := NewMultiNanoNeuron(2, NewRandSource(1))
+	trainMultiModel(plain, 500, 0.01, X, y, MultiTrainOptions{})
+	zero := NewMultiNanoNeuron(2, NewRandSource(1))
+	trainMultiModel(zero, 500, 0.01, X, y, MultiTrainOptions{DropoutRate: 0, Rand: NewRandSource(2)})
+	if !slices.Equal(zero.w, plain.w) || zero.b != plain.b {
+		t.Errorf("rate 0 trained %v, without dropout %v", zero, plain)
+	}
+
+	dropped := NewMultiNanoNeuron(2, NewRandSource(1))
+	trainMultiModel(dropped, 500, 0.01, X, y, MultiTrainOptions{DropoutRate: 0.3, Rand: NewRandSource(2)})
+	if slices.Equal(dropped.w, plain.w) {
+		t.Error("dropout didn't change the training")
+	}
+	again := NewMultiNanoNeuron(2, NewRandSource(1))
+	trainMultiModel(again, 500, 0.01, X, y, MultiTrainOptions{DropoutRate: 0.3, Rand: NewRandSource(2)})
+	if !slices.Equal(again.w, dropped.w) || again.b != dropped.b {
+		t.Errorf("the same random source trained %v and %v", dropped, again)
+	}
+}
+
+func TestNoDropoutAtInference(t *testing.T) {
+	X, y := twoFeatureData()
+	model := NewMultiNanoNeuron(2, NewRandSource(1))
+	trainMultiModel(model, 500, 0.01, X, y, MultiTrainOptions{DropoutRate: 0.5, Rand: NewRandSource(2)})
+	for i, row := range X {
+		if want := model.b + model.w[0]*row[0] + model.w[1]*row[1]; model.predict(row) != want {
+			t.Errorf("row %d: predicted %v, want %v with all the features", i, model.predict(row), want)
+		}
+	}
+}
+
+func TestCheckCollinearityFlagsConstantColumns(t *testing.T) {
+	// The constant columns 1 and 3 are multiples of each other, column 2 varies.
+	features := [][]float64{{0, 5, 1, 5}, {1, 5, 7, 5}, {2, 5, 2, 5}}
+	got, err := CheckCollinearity(features)
+	if err != nil || !slices.Equal(got, []int{3}) {
+		t.Errorf("got %v (%v), want the repeated constant column [3]", got, err)
+	}
+	if _, err := CheckCollinearity([][]float64{{0, 1}, {1}}); !errors.Is(err, ErrLengthMismatch) {
+		t.Errorf("ragged rows got %v, want ErrLengthMismatch", err)
+	}
+}
+
+func TestCheckCollinearityFlagsADuplicatedColumn(t *testing.T) {
+	var features [][]float64
+	for i := range 20 {
+		x0, x1 := float64(i), float64((i*7)%11)
+		// Column 2 is a copy of column 0 in other units, column 3 is independent.
+		features = append(features, []float64{x0, x1, 1.8*x0 + 32, float64((i * 3) % 5)})
+	}
+	if got, _ := CheckCollinearity(features); !slices.Equal(got, []int{2}) {
+		t.Errorf("flagged %v, want [2]", got)
+	}
+	if got, _ := CheckCollinearity(nil); got != nil {
+		t.Errorf("flagged %v without features", got)
+	}
+}
+
+func TestFeatureImportanceOfADominantWeight(t *testing.T) {
+	model := &MultiNanoNeuron{w: []float64{0.5, -8, 1.5}, b: 100}
+	importance := model.FeatureImportance()
+	if want := []float64{0.05, 0.8, 0.15}; !slices.EqualFunc(importance, want, func(a, b float64) bool { return math.Abs(a-b) < 1e-12 }) {
+		t.Errorf("got %v, want %v", importance, want)
+	}
+	if got := (&MultiNanoNeuron{w: []float64{0, 0}}).FeatureImportance(); !slices.Equal(got, []float64{0, 0}) {
+		t.Errorf("zero weights have importance %v", got)
+	}
+}
+
+func TestTrainColumnsEqualsRows(t *testing.T) {
+	rows, y := twoFeatureData()
+	columns := make([][]float64, 2)
+	for _, row := range rows {
+		for j, v := range row {
+			columns[j] = append(columns[j], v)
+		}
+	}
+	byRows := NewMultiNanoNeuron(2, NewRandSource(1))
+	trainMultiModel(byRows, 500, 0.01, rows, y, MultiTrainOptions{})
+	byColumns := NewMultiNanoNeuron(2, NewRandSource(1))
+	if _, err := TrainColumns(byColumns, columns, y, 500, 0.01, MultiTrainOptions{}); err != nil {
+		t.Fatalf("TrainColumns: %v", err)
+	}
+	if !slices.Equal(byColumns.w, byRows.w) || byColumns.b != byRows.b {
+		t.Errorf("columns trained %v, rows %v", byColumns, byRows)
+	}
+
+	columns[1] = columns[1][1:]
+	if _, err := TrainColumns(NewMultiNanoNeuron(2, NewRandSource(1)), columns, y, 10, 0.01, MultiTrainOptions{}); !errors.Is(err, ErrLengthMismatch) {
+		t.Errorf("columns of different lengths: got %v, want ErrLengthMismatch", err)
+	}
+}
+
+func TestTrainMultiMatchesTheUncheckedLoop(t *testing.T) {
+	X, y := twoFeatureData()
+	checked := NewMultiNanoNeuron(2, NewRandSource(1))
+	if _, err := TrainMulti(checked, X, y, 500, 0.01, MultiTrainOptions{}); err != nil {
+		t.Fatalf("TrainMulti: %v", err)
+	}
+	unchecked := NewMultiNanoNeuron(2, NewRandSource(1))
+	trainMultiModel(unchecked, 500, 0.01, X, y, MultiTrainOptions{})
+	if !slices.Equal(checked.w, unchecked.w) || checked.b != unchecked.b {
+		t.Errorf("TrainMulti trained %v, trainMultiModel %v", checked, unchecked)
+	}
+}
+
+func TestPerFeatureRatesConvergeOnWildlyDifferentScales(t *testing.T) {
+	// The second feature is a thousand times bigger than the first one.
+	X, y := twoFeatureData()
+	for i := range X {
+		X[i][1] *= 1000
+		y[i] = 2*X[i][0] - 0.001*X[i][1] + 3
+	}
+	const epochs, alpha = 5000, 0.05
+	scaled := &MultiNanoNeuron{w: []float64{0, 0}}
+	if _, err := TrainMulti(scaled, X, y, epochs, alpha, MultiTrainOptions{Rates: []float64{alpha, alpha / 1e6}}); err != nil {
+		t.Fatalf("TrainMulti: %v", err)
+	}
+	if math.Abs(scaled.w[0]-2) > 1e-3 || math.Abs(scaled.w[1]+0.001) > 1e-6 || math.Abs(scaled.b-3) > 1e-3 {
+		t.Errorf("got %v, want y = 2x0 - 0.001x1 + 3", scaled)
+	}
+
+	// The single rate that is small enough for the second feature barely moves the first one.
+	single := &MultiNanoNeuron{w: []float64{0, 0}}
+	if _, err := TrainMulti(single, X, y, epochs, alpha/1e6, MultiTrainOptions{}); err != nil {
+		t.Fatalf("TrainMulti: %v", err)
+	}
+	if math.Abs(single.w[0]-2) < 0.5 {
+		t.Errorf("a single rate converged too: %v", single)
+	}
+
+	_, err := TrainMulti(&MultiNanoNeuron{w: []float64{0, 0}}, X, y, epochs, alpha, MultiTrainOptions{Rates: []float64{alpha}})
+	if !errors.Is(err, ErrInvalidHyperparameter) {
+		t.Errorf("got %v for one rate for two weights, want ErrInvalidHyperparameter", err)
+	}
+}
+
+func TestMultiParameterDistance(t *testing.T) {
+	a := &MultiNanoNeuron{w: []float64{1, 2, 3}, b: 4}
+	if d := MultiParameterDistance(a, &MultiNanoNeuron{w: []float64{1, 2, 3}, b: 4}); d != 0 {
+		t.Errorf("identical models are %v apart", d)
+	}
+	// Every weight counts: 1² + 2² + 2² + 0² is 3².
+	if d := MultiParameterDistance(a, &MultiNanoNeuron{w: []float64{2, 4, 1}, b: 4}); math.Abs(d-3) > 1e-12 {
+		t.Errorf("got %v, want 3", d)
+	}
+	if d := MultiParameterDistance(a, &MultiNanoNeuron{w: []float64{1, 2}, b: 4}); !math.IsNaN(d) {
+		t.Errorf("models of 3 and 2 features are %v apart, want NaN", d)
+	}
+}
+
+func TestPredictMatrix(t *testing.T) {
+	model := &MultiNanoNeuron{w: []float64{2, -1}, b: 3}
+	X := [][]float64{{0, 0}, {1, 2}, {-3, 0.5}}
+	predictions, err := model.PredictMatrix(X)
+	if err != nil {
+		t.Fatalf("PredictMatrix: %v", err)
+	}
+	if len(predictions) != len(X) {
+		t.Fatalf("got %d predictions for %d rows", len(predictions), len(X))
+	}
+	for i, row := range X {
+		if want := model.predict(row); predictions[i] != want {
+			t.Errorf("row %d: predicted %v, want %v", i, predictions[i], want)
+		}
+	}
+
+	ragged := [][]float64{{0, 0}, {1}, {1, 2}}
+	if _, err := model.PredictMatrix(ragged); !errors.Is(err, ErrLengthMismatch) {
+		t.Errorf("got %v for a ragged matrix, want ErrLengthMismatch", err)
+	}
+}
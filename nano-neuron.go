@@ -1,9 +1,13 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"log/slog"
 	"math"
 	"math/rand"
+	"os"
+	"time"
 )
 
 const iterations = 100
@@ -17,12 +21,31 @@ type NanoNeuron struct {
 	// These parameters are something that NanoNeuron is going to "learn" during the training process.
 	w float64
 	b float64
+	// Optional activation function applied on top of the linear output (nil means none).
+	// With a Sigmoid activation NanoNeuron squashes its output into (0, 1).
+	activation Activation
+	// Optional affine transform of the output: scale * y + offset (nil means none).
+	// See WithOutputTransform.
+	output *affine
+}
+
+// Predict is the linear dependency NanoNeuron imitates as a pure function of hypothetical
+// parameters 'w' and 'b', handy for what-if analysis without touching any model.
+func Predict(x, w, b float64) float64 {
+	return x*w + b
 }
 
 // This is the only thing that NanoNeuron can do - imitate linear dependency.
 // It accepts some input 'x' and predicts the output 'y'. No magic here.
 func (n NanoNeuron) predict(x float64) float64 {
-	return x*n.w + n.b
+	y := Predict(x, n.w, n.b)
+	if n.activation != nil {
+		y = n.activation.Activate(y)
+	}
+	if n.output != nil {
+		y = n.output.scale*y + n.output.offset
+	}
+	return y
 }
 
 // Convert Celsius values to Fahrenheit using formula: f = 1.8 * c + 32.
@@ -41,35 +64,71 @@ func celsiusToFahrenheit(c float64) float64 {
 // In real life in most of the cases this data would be rather collected than generated.
 // For example we might have a set of images of hand-drawn numbers and corresponding set
 // of numbers that explain what number is written on each picture.
-func generateDataSets(start float64) ([]float64, []float64) {
+// Real data is also rarely perfect, so Gaussian noise with 'noiseStddev' standard deviation
+// drawn from 'rng' may be added to the labels (0 gives the exact values and 'rng' may be nil).
+// Sometimes it is even plainly wrong: 'outlierFraction' of the labels (chosen by 'rng') are
+// moved away from their values by outlierDeviation to twice as much, in a random direction.
+// 'rng' may only be nil when both 'noiseStddev' and 'outlierFraction' are 0, it panics otherwise.
+func generateDataSets(start, noiseStddev, outlierFraction float64, rng RandSource) ([]float64, []float64) {
+	return generateLabeledDataSets(celsiusToFahrenheit, start, noiseStddev, outlierFraction, rng)
+}
+
+// generateLabeledDataSets is generateDataSets with the labels computed by 'label' instead of
+// celsiusToFahrenheit, i.e. celsiusToKelvin.
+func generateLabeledDataSets(label func(float64) float64, start, noiseStddev, outlierFraction float64, rng RandSource) ([]float64, []float64) {
 	// Generate TRAINING examples.
 	// We will use this data to train our NanoNeuron.
 	// Before our NanoNeuron will grow and will be able to make decisions by its own
 	// we need to teach it what is right and what is wrong using training examples.
 	// xTrain -> [0, 1, 2, ...],
 	// yTrain -> [32, 33.8, 35.6, ...]
+	if rng == nil && (noiseStddev != 0 || outlierFraction > 0) {
+		panic(fmt.Sprintf("generateDataSets: noise %v and outliers %v need a RandSource", noiseStddev, outlierFraction))
+	}
 	xTrain := make([]float64, iterations)
 	yTrain := make([]float64, iterations)
 	var x float64
 	var y float64
 	x = start
 	for i := 0; i < 100; i++ {
-		y = celsiusToFahrenheit(x)
+		y = label(x)
+		if noiseStddev != 0 {
+			y += normFloat64(rng) * noiseStddev
+		}
 		xTrain[i] = x
 		yTrain[i] = y
 		x += 1.0
 	}
+	outliers := int(math.Round(outlierFraction * float64(len(yTrain))))
+	if outliers > 0 {
+		for _, i := range perm(len(yTrain), rng)[:min(outliers, len(yTrain))] {
+			deviation := outlierDeviation * (1 + rng.Float64())
+			if rng.Float64() < 0.5 {
+				deviation = -deviation
+			}
+			yTrain[i] += deviation
+		}
+	}
 	return xTrain, yTrain
 }
 
+// outlierDeviation is the smallest distance of an outlier label from its correct value (in °F).
+const outlierDeviation = 100
+
+// costScale is the factor of the squared error in predictionCost. It is shared by the cost and
+// its derivative in backwardPropagation and SquaredError.Signals, which stay consistent with each
+// other for any value. The derivative of costScale * (y - prediction) ^ 2 is
+// 2 * costScale * (y - prediction), so 1/2 makes the derivative a simple difference.
+const costScale = 0.5
+
 // Calculate the cost (the mistake) between the correct output value of 'y' and 'prediction' that NanoNeuron made.
 func predictionCost(y, prediction float64) float64 {
 	// This is a simple difference between two values.
 	// The closer the values to each other - the smaller the difference.
 	// We're using power of 2 here just to get rid of negative numbers
 	// so that (1 - 2) ^ 2 would be the same as (2 - 1) ^ 2.
-	// Division by 2 is happening just to simplify further backward propagation formula (see below).
-	return math.Pow(y-prediction, 2) / 2 // i.e. -> 235.6
+	// Multiplying by 1/2 (costScale) is happening just to simplify further backward propagation formula (see below).
+	return math.Pow(y-prediction, 2) * costScale // i.e. -> 235.6
 }
 
 // Forward propagation.
@@ -77,17 +136,58 @@ func predictionCost(y, prediction float64) float64 {
 // model predictions for each example from xTrain.
 // Along the way it also calculates the prediction cost (average error our NanoNeuron made while predicting).
 func forwardPropagation(model *NanoNeuron, xTrain, yTrain []float64) ([]float64, float64) {
-	predictions := make([]float64, iterations)
+	predictions := make([]float64, len(xTrain))
+	cost := forwardPropagationInto(model, xTrain, yTrain, predictions)
+	return predictions, cost
+}
+
+// forwardPropagationInto is forwardPropagation writing the predictions into a caller supplied
+// buffer of len(xTrain) values. The training loop reuses one buffer for all its epochs
+// instead of allocating a new slice tens of thousands of times.
+func forwardPropagationInto(model *NanoNeuron, xTrain, yTrain, predictions []float64) float64 {
+	mustMatch("forward propagation", xTrain, yTrain)
+	mustMatch("forward propagation", xTrain, predictions)
+	m := len(xTrain)
 	cost := 0.0
 	var prediction float64
-	for i := 0; i < iterations; i++ {
+	for i := 0; i < m; i++ {
 		prediction = model.predict(xTrain[i])
 		cost += predictionCost(yTrain[i], prediction)
 		predictions[i] = prediction
 	}
 	// We are interested in average cost.
-	cost /= iterations
-	return predictions, cost
+	cost /= float64(m)
+	return cost
+}
+
+// forwardPropagationCompensated is forwardPropagationInto summing up the costs with the
+// Kahan-Babuska (Neumaier) compensated summation. It keeps the rounding errors of the sum in
+// a separate compensation term, so adding up millions of costs of very different sizes
+// loses (almost) no precision. It's a few operations per example slower.
+func forwardPropagationCompensated(model *NanoNeuron, xTrain, yTrain, predictions []float64) float64 {
+	mustMatch("forward propagation", xTrain, yTrain)
+	mustMatch("forward propagation", xTrain, predictions)
+	sum, compensation := 0.0, 0.0
+	for i := range xTrain {
+		predictions[i] = model.predict(xTrain[i])
+		cost := predictionCost(yTrain[i], predictions[i])
+		t := sum + cost
+		if math.Abs(sum) >= math.Abs(cost) {
+			compensation += (sum - t) + cost
+		} else {
+			compensation += (cost - t) + sum
+		}
+		sum = t
+	}
+	return (sum + compensation) / float64(len(xTrain))
+}
+
+// mustMatch panics with ErrLengthMismatch if the examples of 'x' and 'y' don't pair up.
+// Without it a shorter 'y' would fail with a confusing index out of range deep in a loop.
+func mustMatch(where string, x, y []float64) {
+	if len(x) != len(y) {
+		panic(fmt.Errorf("%s: %w: %d vs %d values", where, ErrLengthMismatch, len(x), len(y)))
+	}
 }
 
 // Backward propagation.
@@ -95,92 +195,915 @@ func forwardPropagation(model *NanoNeuron, xTrain, yTrain []float64) ([]float64,
 // The key concept here is derivative which shows what step to take to get closer
 // to the function minimum. Remember, finding the minimum of a cost function is the
 // ultimate goal of training process. The cost function looks like this:
-// (y - prediction) ^ 2 * 1/2, where prediction = x * w + b.
-func backwardPropagation(predictions, xTrain, yTrain []float64) (float64, float64) {
+// (y - prediction) ^ 2 * costScale, where prediction = x * w + b.
+// If the model has an activation function the chain rule adds its derivative to the formula.
+func backwardPropagation(model *NanoNeuron, predictions, xTrain, yTrain []float64) (float64, float64) {
+	mustMatch("backward propagation", xTrain, yTrain)
+	mustMatch("backward propagation", xTrain, predictions)
 	// At the beginning we don't know in which way our parameters 'w' and 'b' need to be changed.
 	// Therefore we're setting up the changing steps for each parameters to 0.
+	m := len(xTrain)
 	dW := 0.0
 	dB := 0.0
-	for i := 0; i < iterations; i++ {
+	for i := 0; i < m; i++ {
+		delta := model.chainRule(xTrain[i], 2*costScale*(yTrain[i]-predictions[i]))
 		// This is derivative of the cost function by 'w' param.
 		// It will show in which direction (positive/negative sign of 'dW') and
 		// how fast (the absolute value of 'dW') the 'w' param needs to be changed.
-		dW += (yTrain[i] - predictions[i]) * xTrain[i]
+		dW += delta * xTrain[i]
 		// This is derivative of the cost function by 'b' param.
 		// It will show in which direction (positive/negative sign of 'dB') and
 		// how fast (the absolute value of 'dB') the 'b' param needs to be changed.
-		dB += yTrain[i] - predictions[i]
+		dB += delta
 	}
 	// We're interested in average deltas for each params.
-	dW /= iterations
-	dB /= iterations
+	dW /= float64(m)
+	dB /= float64(m)
 	return dW, dB
 }
 
+// ApplyUpdate is the gradient descent update rule on its own: it moves the parameters 'w' and 'b'
+// by 'alpha' times the steps 'dW' and 'dB' of backwardPropagation, which already point downhill.
+func ApplyUpdate(w, b, dW, dB, alpha float64) (newW, newB float64) {
+	return w + alpha*dW, b + alpha*dB
+}
+
+// An epoch that leaves the cost above the lowest cost so far by more than this fraction of it
+// is considered to be diverging (see AdaptiveRecovery). The margin keeps the rounding noise of
+// a converged training from being taken for a divergence.
+const recoveryTolerance = 1e-9
+
+// At most this many times the learning rate is halved by AdaptiveRecovery, then it gives up.
+const maxRateHalvings = 64
+
+// TrainOptions holds the optional knobs of the training process.
+// The zero value trains exactly like the original NanoNeuron: a constant learning rate 'alpha'.
+type TrainOptions struct {
+	// Init overrides the current model parameters right before the training, i.e. BiasFromMean.
+	Init Initializer
+	// SkipNonFinite leaves out the examples whose prediction cost is not a finite number
+	// (NaN or infinite) instead of letting a single dirty example turn the whole epoch into NaN.
+	// The cost is then averaged over the remaining examples only and the number of left out
+	// examples is reported in TrainingResult.Skipped.
+	SkipNonFinite bool
+	// AdaptiveRecovery watches every epoch and when it leaves the cost above the lowest cost so far
+	// (see recoveryTolerance), or at NaN, the epoch is rolled back and retried with half the
+	// learning rate. So a too big 'alpha' is fixed on the way instead of ruining the training, even
+	// when it diverges slowly. The rollback restores the parameters and the state of a
+	// StatefulOptimizer. After maxRateHalvings halvings it gives up and stops the training as
+	// Diverged. The cost is measured on all the training examples after every epoch.
+	AdaptiveRecovery bool
+	// CostProvider picks the cost function to minimize at every epoch, which allows curriculum
+	// learning, i.e. starting with AbsoluteError and switching to SquaredError later on.
+	// Nil always minimizes the squared error of predictionCost.
+	CostProvider func(epoch int) CostFunction
+	// Schedule overrides the constant learning rate 'alpha' with a per-epoch rate.
+	Schedule LearningRateSchedule
+	// Optimizer turns the gradients into the parameter updates (nil is the plain gradient descent).
+	// Optimizers keep state, so use a new one for every independent training.
+	Optimizer Optimizer
+	// GradClipNorm clips the L2 norm of the gradient (dW, dB) before every update (0 disables it).
+	GradClipNorm float64
+	// RecordParams stores the (w, b) pair after every epoch in TrainingResult.ParamHistory.
+	// It is handy to animate how NanoNeuron learns but it costs memory on long trainings.
+	RecordParams bool
+	// ParamTolerance stops the training once the parameter update |rate*dW| + |rate*dB|
+	// stays below it for ParamPatience epochs in a row (0 disables the check).
+	// Unlike a cost target this also catches convergence to a non-zero cost plateau.
+	ParamTolerance float64
+	ParamPatience  int
+	// RelTolerance stops the training once the relative cost improvement (prevCost - cost) / prevCost
+	// stays below it for RelPatience epochs in a row (0 disables the check). Unlike an absolute
+	// tolerance it doesn't depend on the scale of the data, so one value fits all data-sets.
+	RelTolerance float64
+	RelPatience  int
+	// StallWindow is the watchdog of a training that got stuck without diverging, i.e. with a
+	// vanishing gradient after a bad initialization: the training is stopped as stalled when the
+	// cost has changed by less than StallEpsilon over the last StallWindow epochs while it is still
+	// above StallTarget (0 disables the watchdog). Train then reports ErrStalled, so the caller
+	// can initialize the model again. Unlike convergence a stall never happens near the target.
+	StallWindow  int
+	StallEpsilon float64
+	StallTarget  float64
+	// HistoryCapacity is how many epochs the histories are preallocated for. By default it is
+	// all the epochs, unless the training may stop early; then it is at most defaultHistoryCapacity
+	// and the histories grow only as far as the training actually goes.
+	HistoryCapacity int
+	// BatchSize splits the training examples into mini-batches of this size (0 means one full batch).
+	// The cost of an epoch is then the average cost of its mini-batches.
+	BatchSize int
+	// AdaptiveBatch grows the mini-batches during the training instead of using a fixed BatchSize.
+	AdaptiveBatch *AdaptiveBatch
+	// AccumSteps sums up the gradients of this many mini-batches before the parameters are
+	// adjusted once, which simulates a bigger batch with the memory of a small one.
+	// The update is the same as a single batch made of the combined mini-batches.
+	AccumSteps int
+	// Autograd computes the gradients with the Value autograd engine instead of the hand-derived
+	// backwardPropagation. It learns the same, only much slower; it's there to show how it works.
+	// It can't be combined with CostProvider or Weights.
+	Autograd bool
+	// Unroll runs the forward and the backward propagation with loops processing 4 examples
+	// per iteration. The results are exactly the same, only the speed may differ (measure it,
+	// the gain depends on the CPU and the compiler). CompensatedSum takes precedence over it.
+	Unroll bool
+	// CompensatedSum adds up the costs of the examples with compensated (Kahan) summation,
+	// which is more accurate for very big data-sets, see forwardPropagationCompensated.
+	CompensatedSum bool
+	// Weights gives every training example its own importance in the cost and the gradient,
+	// i.e. the number of times it occurs in the data-set (see Dedup). The weighted averages are
+	// normalized by the sum of the weights. Weights work with the full batch squared error only:
+	// they can't be combined with BatchSize, AdaptiveBatch, SlowEpochShrink, AccumSteps,
+	// CostProvider, SkipNonFinite, ImportanceSampling or Shuffle.
+	Weights []float64
+	// ImportanceSampling draws the examples of every epoch proportionally to their current
+	// prediction error, so the mini-batches focus on the hard examples (nil goes in order).
+	ImportanceSampling *ImportanceSampling
+	// Shuffle reorders the examples before every epoch, so the mini-batches differ from epoch to
+	// epoch. The order is derived from ShuffleSeed and the epoch (see EpochPermutation), so the
+	// runs with the same seed are reproducible. It can't be combined with Weights.
+	Shuffle     bool
+	ShuffleSeed int64
+	// JitterStd adds Gaussian noise with this standard deviation to the inputs of every epoch
+	// (input jitter), a cheap regularizer against overfitting a small data-set. The noise is
+	// drawn from JitterRand, so a seeded source makes the runs reproducible. Only the training
+	// sees the noise: the reported epoch costs are measured on the jittered inputs, the
+	// predictions of the trained model aren't affected. 0 disables it.
+	JitterStd  float64
+	JitterRand RandSource
+	// WBounds and BBounds keep 'w' and 'b' inside a known range (nil means unbounded).
+	// After every update a parameter that left its range is projected back onto it
+	// (projected gradient descent), i.e. WBounds: &Bounds{Lower: 0, Upper: math.Inf(1)}
+	// makes sure that 'w' never becomes negative.
+	WBounds *Bounds
+	BBounds *Bounds
+	// FreezeW and FreezeB keep 'w' or 'b' at its value, so only the other parameter is trained,
+	// i.e. to fine-tune the bias of a model whose slope is known.
+	FreezeW bool
+	FreezeB bool
+	// Logger receives a structured "training progress" record with the epoch, cost, 'w' and 'b'
+	// every LogEvery epochs (nil disables logging). The logger's handler decides the format
+	// and the destination of the records.
+	Logger   *slog.Logger
+	LogEvery int
+	// MaxEpochDuration guards a shared machine against epochs that hog the CPU on a huge
+	// data-set: every epoch that takes longer logs a "slow epoch" warning on Logger and counts
+	// in TrainingResult.SlowEpochs (0 disables the guard). With SlowEpochShrink every slow
+	// epoch also halves the mini-batch size of the following ones (down to 1 example), so the
+	// model is updated more often and reaches the same cost in fewer of the long epochs.
+	MaxEpochDuration time.Duration
+	SlowEpochShrink  bool
+	// Progress is called after every epoch with its cost and the current parameters.
+	Progress func(epoch int, cost, w, b float64)
+	// Observers are called after every epoch in this order, see TrainObserver.
+	Observers []TrainObserver
+	// Stop is asked after every epoch (after the observers) whether to stop the training.
+	// Any stopping rule can be written as such a predicate, i.e. a target cost:
+	// func(_ int, s TrainState) bool { return s.Cost < 1e-6 }. Several criteria are combined
+	// with AnyOf and AllOf.
+	Stop StopCriterion
+	// Control is called after every epoch (after Stop) and steers the training interactively
+	// with its answer: Continue, Stop or Pause. Pause blocks the training until a value is
+	// received from Resume (or Resume is closed), then it goes on with the next epoch.
+	// Without Resume a Pause is a Continue, so it can't block forever.
+	Control func(epoch int, state TrainState) Control
+	Resume  <-chan struct{}
+	// SnapshotRestarts keeps a copy of the model at the end of every cycle of a Schedule with
+	// warm restarts (WarmRestarts) in TrainingResult.Snapshots, for a snapshot ensemble: the
+	// models of a single training that settled in different minima (see BlendByValidation).
+	SnapshotRestarts bool
+	// KeepBest snapshots the model every time its cost reaches a new minimum after an epoch
+	// and returns the best snapshot in TrainingResult.BestModel. The cost is measured on
+	// XVal/YVal when they are given and on the training data otherwise.
+	KeepBest bool
+	// With the validation data XVal/YVal the best snapshot is always kept and when the training
+	// ends, for whatever reason, the model is restored to it: it's the one that generalizes best.
+	// KeepLast leaves the model at the parameters of the last epoch instead.
+	XVal     []float64
+	YVal     []float64
+	KeepLast bool
+	// AverageTail keeps the running (Polyak-Ruppert) average of 'w' and 'b' over this last fraction
+	// of the epochs, i.e. 0.1 for the last 10%, and returns it in TrainingResult.AveragedModel
+	// (0 disables it). The average smooths out the bouncing of noisy updates around the optimum.
+	// A Trainer averages the tail of its whole epoch budget, an OnlineTrainer has no budget and
+	// doesn't allow it.
+	AverageTail float64
+
+	// epochOffset is the number of epochs trained before, when the training continues an
+	// earlier one (see continuedOptions), so Shuffle goes on with the orders of the next epochs.
+	epochOffset int
+	// totalEpochs is the epoch budget of the whole training this one is a part of, including
+	// the epochOffset epochs, so AverageTail averages the tail of all of it (0 is just this part).
+	totalEpochs int
+}
+
+// Bounds is a closed [Lower, Upper] range of allowed parameter values.
+type Bounds struct {
+	Lower float64
+	Upper float64
+}
+
+// project returns the value of the range closest to 'v'. A nil range allows everything.
+func (r *Bounds) project(v float64) float64 {
+	if r == nil {
+		return v
+	}
+	return math.Max(r.Lower, math.Min(r.Upper, v))
+}
+
+// TrainingResult is everything the teacher remembers about the training process.
+type TrainingResult struct {
+	// InitialCost is the cost of the model before any parameter was adjusted.
+	// With mini-batches CostHistory[0] already includes the first updates of the epoch.
+	InitialCost float64
+	// CostHistory is the cost of every epoch, measured before the parameters were adjusted.
+	CostHistory []float64
+	// BatchCostStd is the standard deviation of the mini-batch costs within every epoch.
+	// It is always 0 for full-batch training; a high value means that the batches are too
+	// small to estimate the cost reliably.
+	BatchCostStd []float64
+	// GradNormHistory is the L2 norm sqrt(dW^2 + dB^2) of the last gradient of every epoch.
+	// It approaches zero as the training converges to the minimum.
+	GradNormHistory []float64
+	// MeanAbsGradHistory is the mean absolute gradient (|dW| + |dB|) / 2 over all the updates of
+	// every epoch. When it gets close to zero long before the cost does, the gradients have
+	// vanished, i.e. in a saturated Sigmoid (see VanishingGradientWarner).
+	MeanAbsGradHistory []float64
+	// BatchSizeHistory is the mini-batch size of every epoch (only with TrainOptions.AdaptiveBatch or SlowEpochShrink).
+	BatchSizeHistory []int
+	// Snapshots are the models at the end of every cycle of the warm restarts (only with
+	// TrainOptions.SnapshotRestarts).
+	Snapshots []*NanoNeuron
+	// ParamHistory is the (w, b) pair after every epoch (only with TrainOptions.RecordParams).
+	ParamHistory [][2]float64
+	// Converged tells if the training stopped early: the parameters or the cost stopped changing
+	// (ParamTolerance, RelTolerance) or an observer, the Stop predicate or Control stopped it.
+	Converged bool
+	// PerfectFit tells if the training stopped because the model fits the training data-set
+	// perfectly: the cost fell to the scale of the rounding errors (see perfectFitCost).
+	// The training is reported as converged then too.
+	PerfectFit bool
+	// Stalled tells if the training was stopped by the StallWindow watchdog.
+	Stalled bool
+	// RateHalvings is how many times the learning rate was halved (only with TrainOptions.AdaptiveRecovery).
+	RateHalvings int
+	// Diverged tells if the AdaptiveRecovery gave up: the epochs kept making the cost worse even
+	// after maxRateHalvings halvings of the learning rate.
+	Diverged bool
+	// Skipped is the number of examples left out over all epochs (only with TrainOptions.SkipNonFinite).
+	Skipped int
+	// SlowEpochs is the number of epochs that took longer than TrainOptions.MaxEpochDuration.
+	SlowEpochs int
+	// DataHash is the SHA-256 of the training data-set (only set by TrainFromConfig, see AuditRun).
+	DataHash string
+	// Final is a copy of the model after the training.
+	Final *NanoNeuron
+	// BestModel is a copy of the model at the epoch with the lowest (validation) cost
+	// and BestCost is that cost (only with TrainOptions.KeepBest or the validation data).
+	BestModel *NanoNeuron
+	BestCost  float64
+	// AveragedModel is a copy of the final model with 'w' and 'b' averaged over the epochs of
+	// the tail of the training (only with TrainOptions.AverageTail; nil if the training
+	// stopped before the tail).
+	AveragedModel *NanoNeuron
+
+	averaged int // the number of epochs averaged into AveragedModel
+}
+
 // Train the model.
 // This is like a "teacher" for our NanoNeuron model:
-// - it will spend some time (epochs) with our yet stupid NanoNeuron model and try to train/teach it,
-// - it will use specific "books" (xTrain and yTrain data-sets) for training,
-// - it will push our kid to learn harder (faster) by using a learning rate parameter 'alpha'
-//   (the harder the push the faster our "nano-kid" will learn but if the teacher will push too hard
-//    the "kid" will have a nervous breakdown and won't be able to learn anything).
-func trainModel(model *NanoNeuron, epochs int, alpha float64, xTrain, yTrain []float64) []float64 {
+//   - it will spend some time (epochs) with our yet stupid NanoNeuron model and try to train/teach it,
+//   - it will use specific "books" (xTrain and yTrain data-sets) for training,
+//   - it will push our kid to learn harder (faster) by using a learning rate parameter 'alpha'
+//     (the harder the push the faster our "nano-kid" will learn but if the teacher will push too hard
+//     the "kid" will have a nervous breakdown and won't be able to learn anything),
+//   - optionally it may follow a learning rate schedule instead of always pushing with the same 'alpha'.
+func trainModel(model *NanoNeuron, epochs int, alpha float64, xTrain, yTrain []float64, opts TrainOptions) *TrainingResult {
+	// There is nothing to learn from no examples: an epoch would only report a made up cost
+	// (and a perfect fit). Train reports such a data-set as ErrEmptyDataSet.
+	if len(xTrain) == 0 {
+		final := *model
+		return &TrainingResult{InitialCost: math.NaN(), Final: &final}
+	}
+
 	// The is the history array of how NanoNeuron learns.
 	// It might have a good or bad "marks" (costs) during the learning process.
-	costHistory := make([]float64, epochs)
-	var predictions []float64
+	capacity := historyCapacity(epochs, &opts)
+	costHistory := make([]float64, 0, capacity)
+	batchCostStd := make([]float64, 0, capacity)
+	gradNormHistory := make([]float64, 0, capacity)
+	meanAbsGradHistory := make([]float64, 0, capacity)
+	var paramHistory [][2]float64
+	if opts.RecordParams {
+		paramHistory = make([][2]float64, 0, capacity)
+	}
 
-	var cost float64
-	var dW, dB float64
+	patience := opts.ParamPatience
+	if patience < 1 {
+		patience = 1
+	}
+	smallUpdates := 0
+	relPatience := max(opts.RelPatience, 1)
+	smallImprovements := 0
+	converged := false
+	stalled := false
+	perfectFit := false
+	perfectCost := perfectFitCost(yTrain)
+
+	var bestModel *NanoNeuron
+	var bestCost float64
+	var snapshots []*NanoNeuron
+	cycles, _ := opts.Schedule.(cycleEnder)
+	restoreBest := opts.XVal != nil && !opts.KeepLast
+	skipped := 0
+
+	// The epochs from averageStart on are averaged for AverageTail.
+	total := epochs
+	if opts.totalEpochs > 0 {
+		total = opts.totalEpochs
+	}
+	averageStart := total - int(math.Ceil(opts.AverageTail*float64(total))) - opts.epochOffset
+	var averageW, averageB float64
+	averaged := 0
+
+	// With AdaptiveRecovery the learning rate is halved every time an epoch made things worse.
+	rateScale := 1.0
+	halvings := 0
+	diverged := false
+	var recoveryBuffer []float64
+	var recoveryCost float64 // the lowest cost after an epoch so far
+	// recoveryCheck measures the cost the recovery compares, it is always on all the training examples.
+	recoveryCheck := func() float64 {
+		cost := forwardPropagationInto(model, xTrain, yTrain, recoveryBuffer)
+		if opts.Weights != nil {
+			cost = weightedCost(yTrain, recoveryBuffer, opts.Weights)
+		}
+		return cost
+	}
+	statefulOptimizer, _ := opts.Optimizer.(StatefulOptimizer)
+	if opts.AdaptiveRecovery {
+		recoveryBuffer = make([]float64, len(xTrain))
+	}
+
+	m := len(xTrain)
+	batchSize := opts.BatchSize
+	if opts.AdaptiveBatch != nil {
+		batchSize = opts.AdaptiveBatch.MaxBatch
+	}
+	if batchSize <= 0 || batchSize > m {
+		batchSize = m
+	}
+	// The predictions of a batch, allocated once and reused in all epochs.
+	buffer := make([]float64, batchSize)
+	var batchSizeHistory []int
+	if opts.AdaptiveBatch != nil || opts.SlowEpochShrink {
+		batchSizeHistory = make([]int, 0, capacity)
+	}
+	if opts.AdaptiveBatch != nil {
+		batchSize = min(opts.AdaptiveBatch.MinBatch, batchSize)
+	}
+	slowEpochs := 0
+	var epochStart time.Time
+
+	accumSteps := opts.AccumSteps
+	if accumSteps < 1 {
+		accumSteps = 1
+	}
+
+	var sampler *importanceSampler
+	if opts.ImportanceSampling != nil {
+		sampler = newImportanceSampler(opts.ImportanceSampling, xTrain, yTrain)
+	}
+	var shuffler *epochShuffler
+	if opts.Shuffle {
+		shuffler = newEpochShuffler(opts.ShuffleSeed, opts.epochOffset, m)
+	}
+	var jitter *inputJitter
+	if opts.JitterStd > 0 {
+		jitter = newInputJitter(opts.JitterStd, opts.JitterRand, m)
+	}
+
+	if opts.Init != nil {
+		opts.Init(model, xTrain, yTrain)
+	}
+
+	// How bad is our NanoNeuron before it has learned anything?
+	initialPredictions, initialCost := forwardPropagation(model, xTrain, yTrain)
+	if opts.CompensatedSum {
+		initialCost = forwardPropagationCompensated(model, xTrain, yTrain, initialPredictions)
+	}
+	if opts.CostProvider != nil {
+		initialCost = opts.CostProvider(0).Cost(yTrain, initialPredictions)
+	}
+	if opts.Weights != nil {
+		initialCost = weightedCost(yTrain, initialPredictions, opts.Weights)
+	}
+	if opts.AdaptiveRecovery {
+		recoveryCost = recoveryCheck()
+	}
+
+	// endEpoch does the bookkeeping after the parameters were adjusted in the epoch: it records
+	// the histories, reports the progress and tells whether the training goes on.
+	endEpoch := func(epoch int, rate float64, stats epochStats) bool {
+		cost, dW, dB := stats.cost, stats.dW, stats.dB
+		skipped += stats.skipped
+		batchCostStd = append(batchCostStd, stats.costStd)
+		costHistory = append(costHistory, cost)
+		if schedule, ok := opts.Schedule.(CostAwareSchedule); ok {
+			schedule.ObserveCost(epoch, cost)
+		}
+
+		if opts.Logger != nil && opts.LogEvery > 0 && epoch%opts.LogEvery == 0 {
+			opts.Logger.Info("training progress",
+				slog.Int("epoch", epoch),
+				slog.Float64("cost", cost),
+				slog.Float64("w", model.w),
+				slog.Float64("b", model.b),
+			)
+		}
+
+		if opts.Progress != nil {
+			opts.Progress(epoch, cost, model.w, model.b)
+		}
+		if len(opts.Observers) > 0 || opts.Stop != nil || opts.Control != nil {
+			stop := false
+			state := TrainState{Cost: cost, W: model.w, B: model.b, DW: dW, DB: dB, MeanAbsGrad: stats.meanAbsGrad, Rate: rate, stop: &stop}
+			for _, observer := range opts.Observers {
+				observer.OnEpochEnd(epoch, state)
+			}
+			if opts.Stop != nil && opts.Stop(epoch, state) {
+				stop = true
+			}
+			if opts.Control != nil {
+				switch opts.Control(epoch, state) {
+				case Stop:
+					stop = true
+				case Pause:
+					if opts.Resume != nil {
+						<-opts.Resume
+					}
+				}
+			}
+			converged = stop
+		}
+
+		gradNormHistory = append(gradNormHistory, math.Hypot(dW, dB))
+		meanAbsGradHistory = append(meanAbsGradHistory, stats.meanAbsGrad)
+		if opts.RecordParams {
+			paramHistory = append(paramHistory, [2]float64{model.w, model.b})
+		}
+
+		if opts.KeepBest || restoreBest {
+			var checkCost float64
+			if opts.XVal != nil {
+				_, checkCost = forwardPropagation(model, opts.XVal, opts.YVal)
+			} else {
+				var predictions []float64
+				predictions, checkCost = forwardPropagation(model, xTrain, yTrain)
+				if opts.Weights != nil {
+					checkCost = weightedCost(yTrain, predictions, opts.Weights)
+				}
+			}
+			if bestModel == nil || checkCost < bestCost {
+				snapshot := *model
+				bestModel, bestCost = &snapshot, checkCost
+			}
+		}
+
+		if opts.SnapshotRestarts && cycles != nil && cycles.endsCycle(epoch) {
+			snapshot := *model
+			snapshots = append(snapshots, &snapshot)
+		}
+
+		if opts.AverageTail > 0 && epoch >= averageStart {
+			averaged++
+			averageW += (model.w - averageW) / float64(averaged)
+			averageB += (model.b - averageB) / float64(averaged)
+		}
+
+		// Is there anything left to learn at all? An exactly linear data-set would keep the
+		// training chasing ever tinier costs down to the rounding errors.
+		if opts.CostProvider == nil && cost <= perfectCost {
+			perfectFit = true
+			converged = true
+		}
+
+		// Have we stopped learning anything new?
+		if opts.ParamTolerance > 0 {
+			if math.Abs(rate*dW)+math.Abs(rate*dB) < opts.ParamTolerance {
+				smallUpdates++
+			} else {
+				smallUpdates = 0
+			}
+			converged = converged || smallUpdates >= patience
+		}
+		if opts.RelTolerance > 0 && len(costHistory) > 1 {
+			prevCost := costHistory[len(costHistory)-2]
+			if prevCost > 0 && (prevCost-cost)/prevCost < opts.RelTolerance {
+				smallImprovements++
+			} else {
+				smallImprovements = 0
+			}
+			converged = converged || smallImprovements >= relPatience
+		}
+		if opts.StallWindow > 0 && len(costHistory) > opts.StallWindow {
+			windowStart := costHistory[len(costHistory)-1-opts.StallWindow]
+			stalled = !converged && cost > opts.StallTarget && math.Abs(windowStart-cost) < opts.StallEpsilon
+		}
+		return !converged && !stalled
+	}
 
 	// Let's start counting epochs.
-	for epoch := 0; epoch < epochs; epoch++ {
+	if opts.plainDescent(batchSize, m) {
+		// The plain gradient descent is Minimize of the parameters 'w' and 'b'. Every step is an epoch.
+		var stats epochStats
+		gradient := func(p []float64) []float64 {
+			model.w, model.b = p[0], p[1]
+			cost := forwardPropagationInto(model, xTrain, yTrain, buffer)
+			dW, dB := backwardPropagation(model, buffer, xTrain, yTrain)
+			stats = epochStats{cost: cost, dW: dW, dB: dB, meanAbsGrad: (math.Abs(dW) + math.Abs(dB)) / 2}
+			// backwardPropagation returns the downhill direction, the gradient is its negation.
+			return []float64{-dW, -dB}
+		}
+		p := Minimize([]float64{model.w, model.b}, gradient, nil, MinimizeOptions{
+			Iterations: epochs,
+			Alpha:      alpha,
+			After: func(epoch int, p []float64) bool {
+				model.w, model.b = p[0], p[1]
+				return endEpoch(epoch, alpha, stats)
+			},
+		})
+		model.w, model.b = p[0], p[1]
+	} else {
+		for epoch := 0; epoch < epochs; epoch++ {
+			rate := alpha
+			if opts.Schedule != nil {
+				rate = opts.Schedule.Rate(epoch)
+			}
+			rate *= rateScale
+
+			var costFunction CostFunction
+			if opts.CostProvider != nil {
+				costFunction = opts.CostProvider(epoch)
+			}
+
+			xEpoch, yEpoch := xTrain, yTrain
+			if sampler != nil {
+				xEpoch, yEpoch = sampler.sample(model, epoch)
+			}
+			if shuffler != nil {
+				xEpoch, yEpoch = shuffler.shuffle(xEpoch, yEpoch, epoch)
+			}
+			if jitter != nil {
+				xEpoch = jitter.apply(xEpoch)
+			}
+
+			before := *model
+			var optimizerBefore OptimizerState
+			if opts.AdaptiveRecovery && statefulOptimizer != nil {
+				optimizerBefore = statefulOptimizer.State()
+			}
+			if opts.MaxEpochDuration > 0 {
+				epochStart = time.Now()
+			}
+			stats := runEpoch(model, rate, costFunction, NewBatchIterator(xEpoch, yEpoch, batchSize, nil), buffer, accumSteps, &opts)
+			if opts.AdaptiveRecovery {
+				// Did this epoch make things worse? Then take the step back and try again more gently.
+				afterCost := recoveryCheck()
+				if !isFinite(afterCost) || afterCost > recoveryCost*(1+recoveryTolerance) {
+					*model = before
+					if statefulOptimizer != nil {
+						// The state was saved by the optimizer itself, it can't be rejected.
+						_ = statefulOptimizer.SetState(optimizerBefore)
+					}
+					if halvings == maxRateHalvings {
+						diverged = true
+						break
+					}
+					rateScale /= 2
+					halvings++
+					epoch--
+					continue
+				}
+				recoveryCost = afterCost
+			}
+			if batchSizeHistory != nil {
+				batchSizeHistory = append(batchSizeHistory, batchSize)
+			}
+			if opts.AdaptiveBatch != nil {
+				batchSize = min(opts.AdaptiveBatch.next(model, batchSize, xTrain, yTrain), m)
+			}
+			if opts.MaxEpochDuration > 0 {
+				if elapsed := time.Since(epochStart); elapsed > opts.MaxEpochDuration {
+					slowEpochs++
+					if opts.Logger != nil {
+						opts.Logger.Warn("slow epoch",
+							slog.Int("epoch", epoch),
+							slog.Duration("elapsed", elapsed),
+							slog.Int("batch_size", batchSize),
+						)
+					}
+					if opts.SlowEpochShrink {
+						batchSize = max(batchSize/2, 1)
+					}
+				}
+			}
+			if !endEpoch(epoch, rate, stats) {
+				break
+			}
+		}
+	}
+
+	if restoreBest && bestModel != nil {
+		*model = *bestModel
+	}
+
+	// Let's return cost history from the function to be able to log or to plot it after training.
+	final := *model
+	result := &TrainingResult{
+		InitialCost:        initialCost,
+		CostHistory:        costHistory,
+		BatchCostStd:       batchCostStd,
+		GradNormHistory:    gradNormHistory,
+		MeanAbsGradHistory: meanAbsGradHistory,
+		Converged:          converged,
+		PerfectFit:         perfectFit,
+		Stalled:            stalled,
+		Skipped:            skipped,
+		SlowEpochs:         slowEpochs,
+		RateHalvings:       halvings,
+		Diverged:           diverged,
+		Final:              &final,
+		BestModel:          bestModel,
+		BestCost:           bestCost,
+		Snapshots:          snapshots,
+	}
+	if opts.RecordParams {
+		result.ParamHistory = paramHistory
+	}
+	if batchSizeHistory != nil {
+		result.BatchSizeHistory = batchSizeHistory
+	}
+	if averaged > 0 {
+		average := final
+		average.w, average.b = averageW, averageB
+		result.AveragedModel = &average
+		result.averaged = averaged
+	}
+	return result
+}
+
+// perfectFitTolerance is the root mean squared error relative to the root mean square of the
+// labels below which a fit counts as perfect: a few thousand machine epsilons (2^-52), far
+// below any real error but safely above the rounding errors of the predictions.
+const perfectFitTolerance = 0x1p-40
+
+// perfectFitCost is the squared error cost below which the model fits the labels 'y' perfectly.
+// Labels that aren't finite numbers (see SkipNonFinite) are left out.
+func perfectFitCost(y []float64) float64 {
+	squares, n := 0.0, 0
+	for _, v := range y {
+		if isFinite(v) {
+			squares += v * v
+			n++
+		}
+	}
+	rms := math.Sqrt(squares / float64(max(n, 1)))
+	return costScale * (perfectFitTolerance * rms) * (perfectFitTolerance * rms)
+}
+
+// plainDescent tells if the training is the plain full-batch gradient descent with the constant
+// learning rate 'alpha', which trainModel runs with Minimize. 'batchSize' is the size of the
+// batches of the 'm' training examples. The options that only watch the training don't matter.
+func (opts *TrainOptions) plainDescent(batchSize, m int) bool {
+	return batchSize == m && opts.AdaptiveBatch == nil && opts.AccumSteps <= 1 &&
+		opts.Schedule == nil && opts.Optimizer == nil && opts.GradClipNorm == 0 &&
+		opts.CostProvider == nil && opts.Weights == nil && !opts.SkipNonFinite && !opts.AdaptiveRecovery &&
+		!opts.Autograd && !opts.Unroll && !opts.CompensatedSum &&
+		opts.ImportanceSampling == nil && !opts.Shuffle && opts.JitterStd == 0 &&
+		opts.WBounds == nil && opts.BBounds == nil && !opts.FreezeW && !opts.FreezeB &&
+		opts.MaxEpochDuration == 0 && !opts.SlowEpochShrink
+}
+
+// defaultHistoryCapacity is the initial capacity of the histories of trainings that may stop early.
+const defaultHistoryCapacity = 1024
+
+// historyCapacity is the initial capacity of the histories of a training of 'epochs' epochs.
+func historyCapacity(epochs int, opts *TrainOptions) int {
+	capacity := epochs
+	if (opts.ParamTolerance > 0 || opts.RelTolerance > 0 || opts.StallWindow > 0 || len(opts.Observers) > 0 || opts.Stop != nil || opts.Control != nil) && capacity > defaultHistoryCapacity {
+		capacity = defaultHistoryCapacity
+	}
+	if opts.HistoryCapacity > 0 && opts.HistoryCapacity < epochs {
+		capacity = opts.HistoryCapacity
+	}
+	return max(capacity, 0)
+}
+
+// epochStats is what a single pass over the training examples has found out.
+type epochStats struct {
+	cost        float64 // average cost of the epoch
+	costStd     float64 // standard deviation of the mini-batch costs
+	skipped     int     // examples left out because of a non-finite cost
+	dW, dB      float64 // the last gradient applied to the parameters
+	meanAbsGrad float64 // mean of (|dW| + |dB|) / 2 over the updates of the epoch
+}
+
+// runEpoch takes our NanoNeuron through all the training examples of 'iterator' once, (mini-)batch
+// by (mini-)batch, adjusting its parameters with the learning rate 'rate' on the way.
+// 'buffer' holds at least the predictions of a batch.
+func runEpoch(model *NanoNeuron, rate float64, costFunction CostFunction, iterator *BatchIterator, buffer []float64, accumSteps int, opts *TrainOptions) epochStats {
+	m := iterator.Len()
+	var dW, dB float64
+	var predictions []float64
+
+	// The gradients of the mini-batches seen since the last update, averaged over all their examples.
+	var gradW, gradB float64
+	accumulated, steps := 0, 0
+	cost := 0.0
+	batchCostSum, batchCostSquares, batches := 0.0, 0.0, 0
+	epochSkipped := 0
+	absGradSum, updates := 0.0, 0
+	for {
+		xBatch, yBatch, ok := iterator.Next()
+		if !ok {
+			break
+		}
+		size := len(xBatch)
+
 		// Forward propagation for all training examples.
 		// Let's save the cost for current iteration.
 		// This will help us to analyse how our model learns.
-		predictions, cost = forwardPropagation(model, xTrain, yTrain)
-		costHistory[epoch] = cost
+		predictions = buffer[:size]
+		var batchCost float64
+		if opts.CompensatedSum {
+			batchCost = forwardPropagationCompensated(model, xBatch, yBatch, predictions)
+		} else if opts.Unroll {
+			batchCost = forwardPropagationUnrolled(model, xBatch, yBatch, predictions)
+		} else {
+			batchCost = forwardPropagationInto(model, xBatch, yBatch, predictions)
+		}
+		if opts.SkipNonFinite {
+			var dropped int
+			xBatch, yBatch, predictions, dropped = finiteExamples(xBatch, yBatch, predictions)
+			epochSkipped += dropped
+			size -= dropped
+			if size > 0 {
+				batchCost = SquaredError{}.Cost(yBatch, predictions)
+			}
+		}
+		if size > 0 {
+			if costFunction != nil {
+				batchCost = costFunction.Cost(yBatch, predictions)
+			}
+			if opts.Weights != nil {
+				batchCost = weightedCost(yBatch, predictions, opts.Weights)
+			}
+			cost += batchCost * (float64(size) / float64(m))
+			batchCostSum += batchCost
+			batchCostSquares += batchCost * batchCost
+			batches++
 
-		// Backward propagation. Let's learn some lessons from the mistakes.
-		// This function returns smalls steps we need to take for params 'w' and 'b'
-		// to make predictions more accurate.
-		dW, dB = backwardPropagation(predictions, xTrain, yTrain)
+			// Backward propagation. Let's learn some lessons from the mistakes.
+			// This function returns smalls steps we need to take for params 'w' and 'b'
+			// to make predictions more accurate.
+			if costFunction != nil {
+				dW, dB = backwardPropagationCost(model, costFunction, predictions, xBatch, yBatch)
+			} else if opts.Autograd {
+				dW, dB = backwardPropagationAutograd(model, xBatch, yBatch)
+			} else if opts.Weights != nil {
+				dW, dB = weightedBackwardPropagation(model, predictions, xBatch, yBatch, opts.Weights)
+			} else if opts.Unroll {
+				dW, dB = backwardPropagationUnrolled(model, predictions, xBatch, yBatch)
+			} else {
+				dW, dB = backwardPropagation(model, predictions, xBatch, yBatch)
+			}
+			if accumulated == 0 {
+				gradW, gradB = dW, dB
+			} else {
+				gradW = (gradW*float64(accumulated) + dW*float64(size)) / float64(accumulated+size)
+				gradB = (gradB*float64(accumulated) + dB*float64(size)) / float64(accumulated+size)
+			}
+			accumulated += size
+		}
+		steps++
+		if (steps < accumSteps && iterator.more()) || accumulated == 0 {
+			continue
+		}
 
 		// Adjust our NanoNeuron parameters to increase accuracy of our model predictions.
-		model.w += alpha * dW
-		model.b += alpha * dB
+		dW, dB = gradW, gradB
+		absGradSum += (math.Abs(dW) + math.Abs(dB)) / 2
+		updates++
+		if opts.GradClipNorm > 0 {
+			dW, dB = clipNorm(dW, dB, opts.GradClipNorm)
+		}
+		stepW, stepB, stepRate := dW, dB, rate
+		if opts.Optimizer != nil {
+			// The steps of an optimizer are already scaled by the learning rate.
+			stepW, stepB = opts.Optimizer.Step(dW, dB, rate)
+			stepRate = 1
+		}
+		w, b := ApplyUpdate(model.w, model.b, stepW, stepB, stepRate)
+		if !opts.FreezeW {
+			model.w = opts.WBounds.project(w)
+		}
+		if !opts.FreezeB {
+			model.b = opts.BBounds.project(b)
+		}
+		accumulated, steps = 0, 0
 	}
-
-	// Let's return cost history from the function to be able to log or to plot it after training.
-	return costHistory
+	if epochSkipped > 0 {
+		// Average over the examples that were really counted.
+		cost *= float64(m) / float64(m-epochSkipped)
+	}
+	stats := epochStats{cost: cost, dW: dW, dB: dB, skipped: epochSkipped}
+	if updates > 0 {
+		stats.meanAbsGrad = absGradSum / float64(updates)
+	}
+	if batches > 1 {
+		batchMean := batchCostSum / float64(batches)
+		stats.costStd = math.Sqrt(math.Max(0, batchCostSquares/float64(batches)-batchMean*batchMean))
+	}
+	return stats
 }
 
+// The seed of the -deterministic mode.
+const deterministicSeed = 1
+
 // ===========================================================================================
 // Now let's use the functions we have created above.
 
 func main() {
+	replMode := flag.Bool("repl", false, "after the training read Celsius temperatures from stdin and predict them")
+	verbose := flag.Bool("verbose", false, "print a table of the training progress")
+	every := flag.Int("every", 5000, "print every n-th epoch in -verbose mode")
+	deterministic := flag.Bool("deterministic", false, "start from a fixed seed, so every run is exactly the same (for benchmarking)")
+	unitsFlag := flag.String("units", "fahrenheit", "teach NanoNeuron to convert Celsius to fahrenheit or kelvin")
+	optimizerFlag := flag.String("optimizer", "sgd", "train with sgd, momentum, adam, rmsprop or adagrad (the adaptive ones may want a larger -alpha)")
+	epochsFlag := flag.Int("epochs", demoEpochs, "train for this many epochs (or $"+envEpochs+")")
+	alphaFlag := flag.Float64("alpha", demoAlpha, "the learning rate (or $"+envAlpha+")")
+	seedFlag := flag.Int64("seed", deterministicSeed, "start from this seed, like -deterministic (or $"+envSeed+")")
+	flag.Parse()
+	// The hyperparameters come from the environment (see ConfigFromEnv), the flags override them.
+	cfg, err := demoConfig(flag.CommandLine, TrainingConfig{Epochs: *epochsFlag, Alpha: *alphaFlag, Seed: *seedFlag})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	units, err := selectUnits(*unitsFlag)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	optimizer, err := selectOptimizer(*optimizerFlag)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	epochs, alpha := cfg.Epochs, cfg.Alpha
+	if err := validateTrainOptions(epochs, alpha, TrainOptions{}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	_, seeded := os.LookupEnv(envSeed)
+	flag.Visit(func(f *flag.Flag) { seeded = seeded || f.Name == "seed" })
+
 	// Let's create our NanoNeuron model instance.
 	// At this moment NanoNeuron doesn't know what values should be set for parameters 'w' and 'b'.
 	// So let's set up 'w' and 'b' randomly.
 	var w = rand.Float64() // i.e. -> 0.9492
 	var b = rand.Float64() // i.e. -> 0.4570
-	nanoNeuron := &NanoNeuron{w, b}
+	nanoNeuron := &NanoNeuron{w: w, b: b}
+	if *deterministic || seeded {
+		// The initialization is the only random thing, the training itself runs sequentially.
+		nanoNeuron = NewNanoNeuron(NewRandSource(cfg.Seed))
+	}
 
 	// Generate training and test data-sets.
-	xTrain, yTrain := generateDataSets(0.0)
-	xTest, yTest := generateDataSets(0.5)
+	xTrain, yTrain := generateLabeledDataSets(units.label, 0.0, 0, 0, nil)
+	xTest, yTest := generateLabeledDataSets(units.label, 0.5, 0, 0, nil)
 
 	// Let's train the model with small (0.0005) steps during the 70000 epochs.
-	// You can play with these parameters, they are being defined empirically.
-	const epochs = 70000
-	const alpha = 0.0005
-	trainingCostHistory := trainModel(nanoNeuron, epochs, alpha, xTrain, yTrain)
+	// You can play with these parameters (-alpha, -epochs), they are being defined empirically.
+	options := TrainOptions{Optimizer: optimizer}
+	if *verbose {
+		if *every < 1 {
+			*every = 1
+		}
+		fmt.Println(progressHeader())
+		options.Progress = func(epoch int, cost, w, b float64) {
+			if epoch%*every == 0 || epoch == epochs-1 {
+				fmt.Println(progressRow(epoch, cost, w, b))
+			}
+		}
+	}
+	trainingResult := trainModel(nanoNeuron, epochs, alpha, xTrain, yTrain, options)
 
 	// Let's check how the cost function was changing during the training.
 	// We're expecting that the cost after the training should be much lower than before.
 	// This would mean that NanoNeuron got smarter. The opposite is also possible.
-	fmt.Println("Cost before the training:", trainingCostHistory[0])       // i.e. -> 4694.3335043
-	fmt.Println("Cost after the training:", trainingCostHistory[epochs-1]) // i.e. -> 0.0000024
+	fmt.Println("Cost before the training:", trainingResult.InitialCost) // i.e. -> 4694.3335043
+	fmt.Println("Cost after the training:", trainingResult.FinalCost())  // i.e. -> 0.0000024
 
 	// Let's take a look at NanoNeuron parameters to see what it has learned.
 	// We expect that NanoNeuron parameters 'w' and 'b' to be similar to ones we have in
@@ -197,9 +1120,17 @@ func main() {
 	// we can call it "smart" and ask him some questions. This was the ultimate goal of whole training process.
 	const tempInCelsius = 70
 	customPrediction := nanoNeuron.predict(tempInCelsius)
-	fmt.Println("NanoNeuron \"thinks\" that", tempInCelsius, "°C in Fahrenheit is:", customPrediction) // -> 158.0002
-	fmt.Println("Correct answer is:", celsiusToFahrenheit(tempInCelsius))                              // -> 158
+	fmt.Println("NanoNeuron \"thinks\" that", tempInCelsius, "°C in", units.name, "is:", customPrediction) // -> 158.0002
+	fmt.Println("Correct answer is:", units.label(tempInCelsius))                                          // -> 158
 
 	// So close! As all the humans our NanoNeuron is good but not ideal :)
 	// Happy learning to you!
+
+	// Still curious? Ask NanoNeuron your own questions.
+	if *replMode {
+		if err := repl(nanoNeuron, units, os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
 }
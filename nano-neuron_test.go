@@ -0,0 +1,677 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"os"
+	"os/exec"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRecordParamsTrajectory(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	model := NewNanoNeuron(NewRandSource(deterministicSeed))
+	result := trainModel(model, 70000, 0.0005, x, y, TrainOptions{RecordParams: true})
+	trajectory := result.ParamHistory
+	if len(trajectory) != len(result.CostHistory) {
+		t.Fatalf("%d recorded parameters for %d epochs", len(trajectory), len(result.CostHistory))
+	}
+	if last := trajectory[len(trajectory)-1]; last != [2]float64{model.w, model.b} {
+		t.Errorf("the last entry %v isn't the trained model (%v, %v)", last, model.w, model.b)
+	}
+	distance := func(p [2]float64) float64 { return math.Hypot(p[0]-1.8, p[1]-32) }
+	for _, epoch := range []int{0, 100, 1000, 10000, len(trajectory) - 1} {
+		if epoch > 0 && distance(trajectory[epoch]) >= distance(trajectory[epoch/10]) {
+			t.Errorf("epoch %d: %v isn't closer to (1.8, 32) than %v at epoch %d", epoch, trajectory[epoch], trajectory[epoch/10], epoch/10)
+		}
+	}
+	if d := distance(trajectory[len(trajectory)-1]); d > 0.01 {
+		t.Errorf("the trajectory ends %v away from (1.8, 32)", d)
+	}
+
+	if result := trainModel(NewNanoNeuron(NewRandSource(1)), 10, 0.0005, x, y, TrainOptions{}); result.ParamHistory != nil {
+		t.Error("the parameters were recorded without RecordParams")
+	}
+}
+
+func TestParamToleranceStopsNegligibleUpdates(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	const epochs, tolerance, patience = 70000, 1e-5, 5
+	model := NewNanoNeuron(NewRandSource(deterministicSeed))
+	result := trainModel(model, epochs, 0.0005, x, y, TrainOptions{ParamTolerance: tolerance, ParamPatience: patience, RecordParams: true})
+	trained := len(result.CostHistory)
+	if !result.Converged || trained == epochs {
+		t.Fatalf("trained %d of %d epochs, want a stop for the small updates", trained, epochs)
+	}
+	update := func(epoch int) float64 {
+		before, after := result.ParamHistory[epoch-1], result.ParamHistory[epoch]
+		return math.Abs(after[0]-before[0]) + math.Abs(after[1]-before[1])
+	}
+	for epoch := trained - patience; epoch < trained; epoch++ {
+		if u := update(epoch); u >= tolerance {
+			t.Errorf("epoch %d: update %v isn't negligible", epoch, u)
+		}
+	}
+	if u := update(trained - patience - 1); u < tolerance {
+		t.Errorf("epoch %d: update %v was negligible already, the training should have stopped earlier", trained-patience-1, u)
+	}
+}
+
+func TestAccumStepsEqualsTheCombinedBatch(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	combined := NewNanoNeuron(NewRandSource(1))
+	trainModel(combined, 100, 0.0005, x, y, TrainOptions{})
+	accumulated := NewNanoNeuron(NewRandSource(1))
+	trainModel(accumulated, 100, 0.0005, x, y, TrainOptions{BatchSize: len(x) / 2, AccumSteps: 2})
+	if math.Abs(accumulated.w-combined.w) > 1e-12 || math.Abs(accumulated.b-combined.b) > 1e-10 {
+		t.Errorf("accumulated w=%v b=%v, the combined batch w=%v b=%v", accumulated.w, accumulated.b, combined.w, combined.b)
+	}
+	separate := NewNanoNeuron(NewRandSource(1))
+	trainModel(separate, 100, 0.0005, x, y, TrainOptions{BatchSize: len(x) / 2})
+	if separate.w == combined.w {
+		t.Error("the mini-batches without accumulation trained the same model, the test proves nothing")
+	}
+}
+
+func TestInitialCostIsBeforeAnyUpdate(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	model := NewNanoNeuron(NewRandSource(1))
+	_, want := forwardPropagation(model, x, y)
+	result := trainModel(model, 10, 0.0005, x, y, TrainOptions{BatchSize: 10})
+	if result.InitialCost != want {
+		t.Errorf("initial cost %v, want %v of the untrained model", result.InitialCost, want)
+	}
+	if result.CostHistory[0] >= want {
+		t.Errorf("the cost of the first epoch %v already includes updates, want it below %v", result.CostHistory[0], want)
+	}
+}
+
+func TestWBoundsKeepsWNonNegative(t *testing.T) {
+	// The labels fall with x, so the gradient pushes 'w' below zero.
+	x, y := make([]float64, 100), make([]float64, 100)
+	for i := range x {
+		x[i] = float64(i)
+		y[i] = 100 - 2*x[i]
+	}
+	model := NewNanoNeuron(NewRandSource(1))
+	bounds := &Bounds{Lower: 0, Upper: math.Inf(1)}
+	result := trainModel(model, 5000, 0.0005, x, y, TrainOptions{WBounds: bounds, RecordParams: true})
+	for epoch, params := range result.ParamHistory {
+		if params[0] < 0 {
+			t.Fatalf("epoch %d: w = %v", epoch, params[0])
+		}
+	}
+	if model.w != 0 {
+		t.Errorf("w = %v, want it held at the bound 0", model.w)
+	}
+
+	free := NewNanoNeuron(NewRandSource(1))
+	trainModel(free, 5000, 0.0005, x, y, TrainOptions{})
+	if free.w >= 0 {
+		t.Errorf("without bounds w = %v, want the gradients to make it negative", free.w)
+	}
+}
+
+func TestFreezeWTrainsOnlyTheBias(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	model := &NanoNeuron{w: 1.8, b: 0}
+	// Alone the bias isn't slowed down by the big inputs, it can take much bigger steps.
+	result := trainModel(model, 1000, 0.1, x, y, TrainOptions{FreezeW: true, RecordParams: true})
+	for epoch, params := range result.ParamHistory {
+		if params[0] != 1.8 {
+			t.Fatalf("epoch %d: w = %v, want it frozen at 1.8", epoch, params[0])
+		}
+	}
+	if math.Abs(model.b-32) > 1e-6 {
+		t.Errorf("b = %v, want it trained to 32", model.b)
+	}
+
+	frozenB := &NanoNeuron{w: 0, b: 32}
+	trainModel(frozenB, 1000, 0.0005, x, y, TrainOptions{FreezeB: true})
+	if frozenB.b != 32 || math.Abs(frozenB.w-1.8) > 1e-6 {
+		t.Errorf("with b frozen trained w=%v b=%v, want w=1.8 b=32", frozenB.w, frozenB.b)
+	}
+}
+
+// recordingHandler is a slog.Handler keeping all the records it handles.
+type recordingHandler struct {
+	records *[]slog.Record
+}
+
+func (h recordingHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h recordingHandler) Handle(_ context.Context, r slog.Record) error {
+	*h.records = append(*h.records, r)
+	return nil
+}
+
+func (h recordingHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h recordingHandler) WithGroup(string) slog.Handler { return h }
+
+func TestLoggerLogsEveryNEpochs(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	var records []slog.Record
+	model := NewNanoNeuron(NewRandSource(1))
+	result := trainModel(model, 100, 0.0005, x, y, TrainOptions{Logger: slog.New(recordingHandler{&records}), LogEvery: 25})
+	if len(records) != 4 {
+		t.Fatalf("got %d records, want 4", len(records))
+	}
+	for i, record := range records {
+		attrs := map[string]slog.Value{}
+		record.Attrs(func(a slog.Attr) bool {
+			attrs[a.Key] = a.Value
+			return true
+		})
+		epoch := int(attrs["epoch"].Int64())
+		if epoch != 25*i {
+			t.Errorf("record %d is of epoch %d, want %d", i, epoch, 25*i)
+		}
+		if cost := attrs["cost"].Float64(); cost != result.CostHistory[epoch] {
+			t.Errorf("epoch %d: logged cost %v, want %v", epoch, cost, result.CostHistory[epoch])
+		}
+		for _, key := range []string{"w", "b"} {
+			if _, ok := attrs[key]; !ok {
+				t.Errorf("epoch %d: %s is missing", epoch, key)
+			}
+		}
+	}
+}
+
+func TestGenerateDataSetsNoise(t *testing.T) {
+	const noise = 5
+	x, y := generateDataSets(0, noise, 0, NewRandSource(1))
+	clean, exact := generateDataSets(0, 0, 0, nil)
+	residuals := make([]float64, len(y))
+	for i := range y {
+		if x[i] != clean[i] {
+			t.Fatalf("input %d is %v, want %v", i, x[i], clean[i])
+		}
+		residuals[i] = y[i] - celsiusToFahrenheit(x[i])
+		if exact[i] != celsiusToFahrenheit(clean[i]) {
+			t.Fatalf("without noise label %d is %v, want %v", i, exact[i], celsiusToFahrenheit(clean[i]))
+		}
+	}
+	// 3 standard errors of the estimates from 100 examples.
+	residualMean := mean(residuals)
+	if math.Abs(residualMean) > 3*noise/10.0 {
+		t.Errorf("mean residual %v, want close to 0", residualMean)
+	}
+	if std := stdDev(residuals, residualMean); math.Abs(std-noise) > 3*noise/math.Sqrt(200) {
+		t.Errorf("residual std %v, want close to %v", std, noise)
+	}
+}
+
+func TestKeepBestBeatsTheFinalModelOfANoisyTraining(t *testing.T) {
+	// Single-example steps on noisy labels keep bouncing around the optimum.
+	x, y := generateDataSets(0, 10, 0, NewRandSource(1))
+	model := NewNanoNeuron(NewRandSource(1))
+	result := trainModel(model, 3000, 0.0005, x, y, TrainOptions{BatchSize: 1, KeepBest: true})
+	if result.BestModel == nil {
+		t.Fatal("no best model was kept")
+	}
+	_, bestCost := forwardPropagation(result.BestModel, x, y)
+	_, finalCost := forwardPropagation(model, x, y)
+	if bestCost != result.BestCost {
+		t.Errorf("best cost %v, but the best model costs %v", result.BestCost, bestCost)
+	}
+	if !(bestCost < finalCost) {
+		t.Errorf("the best model costs %v, the final one %v, want the best one lower", bestCost, finalCost)
+	}
+}
+
+func TestValidationDataRestoresTheBestModel(t *testing.T) {
+	// The training labels are shifted from the validation ones, so the training passes by the
+	// validation optimum and ends up generalizing worse than it did on its way.
+	xVal, yVal := generateDataSets(0, 0, 0, nil)
+	x, y := generateDataSets(0, 0, 0, nil)
+	for i := range y {
+		y[i] += 5
+	}
+	opts := TrainOptions{XVal: xVal, YVal: yVal, RecordParams: true}
+	model := &NanoNeuron{w: 1.8, b: 32}
+	result := trainModel(model, 500, 0.0005, x, y, opts)
+	best, bestCost := 0, math.Inf(1)
+	for epoch, p := range result.ParamHistory {
+		if _, cost := forwardPropagation(&NanoNeuron{w: p[0], b: p[1]}, xVal, yVal); cost < bestCost {
+			best, bestCost = epoch, cost
+		}
+	}
+	if best == len(result.ParamHistory)-1 {
+		t.Fatal("the last epoch is the best one, the test proves nothing")
+	}
+	if p := result.ParamHistory[best]; model.w != p[0] || model.b != p[1] {
+		t.Errorf("restored w=%v b=%v, want the best epoch %d's %v", model.w, model.b, best, p)
+	}
+	if *result.Final != *model {
+		t.Errorf("the final model %v isn't the restored one %v", result.Final, model)
+	}
+
+	opts.KeepLast = true
+	last := &NanoNeuron{w: 1.8, b: 32}
+	trainModel(last, 500, 0.0005, x, y, opts)
+	_, restoredCost := forwardPropagation(model, xVal, yVal)
+	_, lastCost := forwardPropagation(last, xVal, yVal)
+	if restoredCost != result.BestCost || !(restoredCost < lastCost) {
+		t.Errorf("the restored model costs %v (best %v), the last one %v", restoredCost, result.BestCost, lastCost)
+	}
+}
+
+func TestAverageTailSmoothsANoisyTraining(t *testing.T) {
+	// Single-example steps on noisy labels keep bouncing around the optimum.
+	x, y := generateDataSets(0, 10, 0, NewRandSource(1))
+	const epochs = 3000
+	model := NewNanoNeuron(NewRandSource(1))
+	result := trainModel(model, epochs, 0.0001, x, y, TrainOptions{BatchSize: 1, AverageTail: 0.2, RecordParams: true})
+	if result.AveragedModel == nil || result.Final == nil {
+		t.Fatal("the averaged and the final model must both be returned")
+	}
+	var w, b float64
+	tail := result.ParamHistory[epochs-600:]
+	for _, p := range tail {
+		w += p[0] / float64(len(tail))
+		b += p[1] / float64(len(tail))
+	}
+	averaged := result.AveragedModel
+	if math.Abs(averaged.w-w) > 1e-9 || math.Abs(averaged.b-b) > 1e-9 {
+		t.Errorf("averaged w=%v b=%v, the mean of the last %d epochs is w=%v b=%v", averaged.w, averaged.b, len(tail), w, b)
+	}
+	if averaged.w == result.Final.w || averaged.b == result.Final.b {
+		t.Errorf("the averaged model %v is the final one %v", averaged, result.Final)
+	}
+	if result := trainModel(NewNanoNeuron(NewRandSource(1)), 10, 0.0005, x, y, TrainOptions{}); result.AveragedModel != nil {
+		t.Error("the parameters were averaged without AverageTail")
+	}
+}
+
+func TestPropagationRejectsMismatchedLengths(t *testing.T) {
+	model := &NanoNeuron{w: 1.8, b: 32}
+	x, y := []float64{1, 2, 3}, []float64{33.8, 35.6}
+	for name, propagate := range map[string]func(){
+		"forward":  func() { forwardPropagation(model, x, y) },
+		"backward": func() { backwardPropagation(model, []float64{0, 0, 0}, x, y) },
+		"train":    func() { trainModel(model, 1, 0.0005, x, y, TrainOptions{}) },
+	} {
+		func() {
+			defer func() {
+				err, _ := recover().(error)
+				if !errors.Is(err, ErrLengthMismatch) {
+					t.Errorf("%s: got %v, want a panic with ErrLengthMismatch", name, err)
+				}
+			}()
+			propagate()
+		}()
+	}
+	if _, err := Train(model, x, y, 1, 0.0005, TrainOptions{}); !errors.Is(err, ErrLengthMismatch) {
+		t.Errorf("Train: got %v, want ErrLengthMismatch", err)
+	}
+}
+
+func TestBatchCostStd(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	small := trainModel(NewNanoNeuron(NewRandSource(1)), 20, 0.0001, x, y, TrainOptions{BatchSize: 10})
+	full := trainModel(NewNanoNeuron(NewRandSource(1)), 20, 0.0005, x, y, TrainOptions{})
+	if len(small.BatchCostStd) != 20 || len(full.BatchCostStd) != 20 {
+		t.Fatalf("got %d and %d deviations for 20 epochs", len(small.BatchCostStd), len(full.BatchCostStd))
+	}
+	for epoch := range 20 {
+		if !(small.BatchCostStd[epoch] > 0) {
+			t.Errorf("epoch %d: batches of 10 vary by %v, want more than 0", epoch, small.BatchCostStd[epoch])
+		}
+		if full.BatchCostStd[epoch] != 0 {
+			t.Errorf("epoch %d: the full batch varies by %v, want 0", epoch, full.BatchCostStd[epoch])
+		}
+	}
+}
+
+func TestGradNormHistoryDecreases(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	result := trainModel(NewNanoNeuron(NewRandSource(deterministicSeed)), 70000, 0.0005, x, y, TrainOptions{})
+	norms := result.GradNormHistory
+	if len(norms) != len(result.CostHistory) {
+		t.Fatalf("%d gradient norms for %d epochs", len(norms), len(result.CostHistory))
+	}
+	// The descent zig-zags along the steep direction, so it only decreases from window to window.
+	const window = 1000
+	for start := window; start+window <= len(norms); start += window {
+		if slices.Max(norms[start:start+window]) > slices.Max(norms[start-window:start]) {
+			t.Fatalf("the gradient norm grew in the epochs %d to %d", start, start+window)
+		}
+	}
+	if last := norms[len(norms)-1]; last > 1e-6*norms[0] {
+		t.Errorf("the gradient norm went from %v down to %v only, want close to zero", norms[0], last)
+	}
+}
+
+func TestCostProviderSwitchesTheGradient(t *testing.T) {
+	// The model starts far below the labels: the absolute error moves 'b' by exactly 'alpha'
+	// per epoch, the squared error by the (much bigger) residual.
+	x, y := generateDataSets(0, 0, 0, nil)
+	const alpha, switchEpoch = 0.0005, 5
+	model := &NanoNeuron{}
+	result := trainModel(model, 10, alpha, x, y, TrainOptions{
+		RecordParams: true,
+		CostProvider: func(epoch int) CostFunction {
+			if epoch < switchEpoch {
+				return AbsoluteError{}
+			}
+			return SquaredError{}
+		},
+	})
+	b := 0.0
+	for epoch, params := range result.ParamHistory {
+		step := params[1] - b
+		b = params[1]
+		if epoch < switchEpoch {
+			if math.Abs(step-alpha) > 1e-12 {
+				t.Errorf("epoch %d: absolute error moved b by %v, want %v", epoch, step, alpha)
+			}
+		} else if math.Abs(step) < 10*alpha {
+			t.Errorf("epoch %d: squared error moved b by only %v", epoch, step)
+		}
+	}
+}
+
+func TestSkipNonFiniteSurvivesAPoisonedExample(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	y[3] = math.Inf(1)
+	model := NewNanoNeuron(NewRandSource(deterministicSeed))
+	const epochs = 1000
+	result := trainModel(model, epochs, 0.0005, x, y, TrainOptions{SkipNonFinite: true})
+	if result.Skipped != epochs {
+		t.Errorf("skipped %d examples, want the poisoned one in each of the %d epochs", result.Skipped, epochs)
+	}
+	if !isFinite(model.w) || !isFinite(model.b) {
+		t.Fatalf("got w=%v b=%v", model.w, model.b)
+	}
+	if cost := result.FinalCost(); !(cost < result.CostHistory[0]/10) {
+		t.Errorf("the cost went from %v to %v", result.CostHistory[0], cost)
+	}
+}
+
+func TestForwardPropagationIntoAReusedBuffer(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	buffer := make([]float64, len(x))
+	for _, model := range []*NanoNeuron{{w: 1, b: 2}, {w: 1.8, b: 32}, {w: -3, b: 0.5, activation: Sigmoid{}}} {
+		want, wantCost := forwardPropagation(model, x, y)
+		if cost := forwardPropagationInto(model, x, y, buffer); cost != wantCost || !slices.Equal(buffer, want) {
+			t.Errorf("%v: the reused buffer gives cost %v, want %v", model, cost, wantCost)
+		}
+	}
+	model := &NanoNeuron{w: 1.8, b: 32}
+	if allocs := testing.AllocsPerRun(100, func() { forwardPropagationInto(model, x, y, buffer) }); allocs != 0 {
+		t.Errorf("%v allocations per forward propagation into a buffer", allocs)
+	}
+}
+
+func BenchmarkForwardPropagation(b *testing.B) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	model := &NanoNeuron{w: 1.8, b: 32}
+	b.ReportAllocs()
+	for range b.N {
+		forwardPropagation(model, x, y)
+	}
+}
+
+func BenchmarkForwardPropagationInto(b *testing.B) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	model := &NanoNeuron{w: 1.8, b: 32}
+	buffer := make([]float64, len(x))
+	b.ReportAllocs()
+	for range b.N {
+		forwardPropagationInto(model, x, y, buffer)
+	}
+}
+
+func TestHistoriesEndAtTheEarlyStop(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	model := NewNanoNeuron(NewRandSource(deterministicSeed))
+	result := trainModel(model, 70000, 0.0005, x, y, TrainOptions{ParamTolerance: 1, ParamPatience: 5})
+	if n := len(result.CostHistory); n == 70000 || len(result.GradNormHistory) != n {
+		t.Errorf("%d costs and %d gradient norms after the early stop", n, len(result.GradNormHistory))
+	}
+	if cap(result.CostHistory) > defaultHistoryCapacity {
+		t.Errorf("the cost history was preallocated for %d epochs", cap(result.CostHistory))
+	}
+
+	full := trainModel(NewNanoNeuron(NewRandSource(deterministicSeed)), 3000, 0.0005, x, y, TrainOptions{})
+	if len(full.CostHistory) != 3000 || cap(full.CostHistory) != 3000 {
+		t.Errorf("a full training has a history of %d (capacity %d), want 3000", len(full.CostHistory), cap(full.CostHistory))
+	}
+}
+
+func BenchmarkTrainModel(b *testing.B) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	b.ReportAllocs()
+	for range b.N {
+		trainModel(NewNanoNeuron(NewRandSource(deterministicSeed)), 10000, 0.0005, x, y, TrainOptions{})
+	}
+}
+
+func TestBackwardPropagationIsTheGradientOfTheCost(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	const h = 1e-6
+	costAt := func(model NanoNeuron, dw, db float64) float64 {
+		model.w += dw
+		model.b += db
+		_, cost := forwardPropagation(&model, x, y)
+		return cost
+	}
+	for _, model := range []NanoNeuron{{w: 0.3, b: 0.7}, {w: 1.8, b: 30}, {w: 0.01, b: -1, activation: Sigmoid{}}} {
+		predictions, _ := forwardPropagation(&model, x, y)
+		dW, dB := backwardPropagation(&model, predictions, x, y)
+		// backwardPropagation points downhill, so it's the negative derivative of the cost.
+		numericW := -(costAt(model, h, 0) - costAt(model, -h, 0)) / (2 * h)
+		numericB := -(costAt(model, 0, h) - costAt(model, 0, -h)) / (2 * h)
+		if math.Abs(dW-numericW) > 1e-4*math.Max(1, math.Abs(numericW)) || math.Abs(dB-numericB) > 1e-4*math.Max(1, math.Abs(numericB)) {
+			t.Errorf("%v: gradient (%v, %v), the finite differences of the cost (%v, %v)", &model, dW, dB, numericW, numericB)
+		}
+	}
+}
+
+func TestApplyUpdate(t *testing.T) {
+	for _, c := range []struct {
+		w, b, dW, dB, alpha float64
+		wantW, wantB        float64
+	}{
+		{w: 1, b: 2, dW: 0.5, dB: -4, alpha: 0.25, wantW: 1.125, wantB: 1},
+		{w: -1, b: 0, dW: -2, dB: -8, alpha: 0.5, wantW: -2, wantB: -4},
+		{w: 1.8, b: 32, dW: 100, dB: -100, alpha: 0, wantW: 1.8, wantB: 32},
+	} {
+		if w, b := ApplyUpdate(c.w, c.b, c.dW, c.dB, c.alpha); w != c.wantW || b != c.wantB {
+			t.Errorf("ApplyUpdate(%v, %v, %v, %v, %v) = (%v, %v), want (%v, %v)", c.w, c.b, c.dW, c.dB, c.alpha, w, b, c.wantW, c.wantB)
+		}
+	}
+}
+
+func TestGenerateDataSetsOutliers(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0.1, NewRandSource(0))
+	outliers := 0
+	for i := range y {
+		switch deviation := math.Abs(y[i] - celsiusToFahrenheit(x[i])); {
+		case deviation >= outlierDeviation:
+			outliers++
+		case deviation != 0:
+			t.Errorf("label %d is off by %v, neither exact nor an outlier", i, deviation)
+		}
+	}
+	if outliers != 10 {
+		t.Errorf("%d outliers, want 10%% of %d", outliers, len(y))
+	}
+
+	// The least squares fit is pulled towards the outliers, the Huber cost caps their pull.
+	squared, err := FitClosedForm(x, y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	huber := *squared
+	trainModel(&huber, 20000, 0.001, x, y, TrainOptions{CostProvider: func(int) CostFunction { return Huber{Delta: 1} }})
+	off := func(model *NanoNeuron) float64 { return math.Hypot(model.w-1.8, model.b-32) }
+	if squaredOff, huberOff := off(squared), off(&huber); !(huberOff < squaredOff/2) {
+		t.Errorf("the squared error fit %v is %v off, the Huber fit %v is %v off", squared, squaredOff, &huber, huberOff)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("outliers without a RandSource didn't panic")
+		}
+	}()
+	generateDataSets(0, 0, 0.1, nil)
+}
+
+func TestRelToleranceStopsAlikeOnAnyScale(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	scaled := make([]float64, len(y))
+	for i := range y {
+		scaled[i] = y[i] * 1e6
+	}
+	opts := TrainOptions{RelTolerance: 1e-3, RelPatience: 10}
+	small := trainModel(&NanoNeuron{}, 70000, 0.0005, x, y, opts)
+	big := trainModel(&NanoNeuron{}, 70000, 0.0005, x, scaled, opts)
+	if !small.Converged || !big.Converged {
+		t.Fatalf("converged %v and %v, want both to stop early", small.Converged, big.Converged)
+	}
+	if s, b := len(small.CostHistory), len(big.CostHistory); abs(s-b) > 1 {
+		t.Errorf("stopped after %d epochs, on the data a million times bigger after %d", s, b)
+	}
+}
+
+func TestPredictAgreesWithTheMethod(t *testing.T) {
+	for _, model := range []NanoNeuron{{w: 1.8, b: 32}, {w: -0.3, b: 7.25}, {}} {
+		for _, x := range []float64{-40, 0, 0.1, 37, 1e6} {
+			if got, want := Predict(x, model.w, model.b), model.predict(x); got != want {
+				t.Errorf("Predict(%v, %v, %v) = %v, the model predicts %v", x, model.w, model.b, got, want)
+			}
+		}
+	}
+}
+
+func TestDeterministicModeRepeatsTheRun(t *testing.T) {
+	if os.Getenv("NN_TEST_MAIN") == "1" {
+		os.Args = []string{"nano-neuron", "-deterministic", "-verbose", "-every", "100"}
+		main()
+		os.Exit(0)
+	}
+	run := func() []byte {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestDeterministicModeRepeatsTheRun$")
+		cmd.Env = append(os.Environ(), "NN_TEST_MAIN=1")
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("the demo failed: %v", err)
+		}
+		return out
+	}
+	first, second := run(), run()
+	if !bytes.Contains(first, []byte("Cost after the training:")) {
+		t.Fatalf("unexpected demo output:\n%s", first)
+	}
+	if !bytes.Equal(first, second) {
+		t.Errorf("two deterministic runs differ:\n%s\n%s", first, second)
+	}
+
+	x, y := generateDataSets(0, 0, 0, nil)
+	histories := make([][]uint64, 2)
+	for i := range histories {
+		result := trainModel(NewNanoNeuron(NewRandSource(deterministicSeed)), 1000, 0.0005, x, y, TrainOptions{})
+		for _, cost := range result.CostHistory {
+			histories[i] = append(histories[i], math.Float64bits(cost))
+		}
+	}
+	if !slices.Equal(histories[0], histories[1]) {
+		t.Error("two deterministic trainings have different cost histories")
+	}
+}
+
+func TestCompensatedSumIsMoreAccurate(t *testing.T) {
+	// One huge cost followed by many small ones: every small cost is less than half a unit in
+	// the last place of the running sum, so the naive summation drops them all.
+	const small = 1000
+	x := make([]float64, small+1)
+	y := make([]float64, small+1)
+	y[0] = 1e9
+	for i := 1; i <= small; i++ {
+		y[i] = 1
+	}
+	model := &NanoNeuron{w: 0, b: 0}
+	exact := (costScale*1e18 + costScale*small) / (small + 1)
+
+	predictions := make([]float64, len(x))
+	naive := forwardPropagationInto(model, x, y, predictions)
+	compensated := forwardPropagationCompensated(model, x, y, predictions)
+	naiveError, compensatedError := math.Abs(naive-exact), math.Abs(compensated-exact)
+	if compensatedError >= naiveError {
+		t.Errorf("compensated cost %v is off by %v, the naive %v only by %v", compensated, compensatedError, naive, naiveError)
+	}
+	if compensatedError > 0.1 {
+		t.Errorf("compensated cost %v is off by %v", compensated, compensatedError)
+	}
+
+	result := trainModel(model, 1, 0, x, y, TrainOptions{CompensatedSum: true})
+	if result.InitialCost != compensated {
+		t.Errorf("the training reported the initial cost %v, want the compensated %v", result.InitialCost, compensated)
+	}
+}
+
+func TestTrainingStopsAtAPerfectFit(t *testing.T) {
+	// The Celsius data is exactly linear, in hundreds of degrees it can be learned fast.
+	x, y := generateDataSets(0, 0, 0, nil)
+	for i := range x {
+		x[i] /= 100
+	}
+	model := NewNanoNeuron(NewRandSource(1))
+	result := trainModel(model, 70000, 1, x, y, TrainOptions{})
+	epochs := len(result.CostHistory)
+	if !result.PerfectFit || !result.Converged || epochs == 70000 {
+		t.Fatalf("trained %d epochs (perfect fit=%v, converged=%v), want a perfect fit", epochs, result.PerfectFit, result.Converged)
+	}
+	if math.Abs(model.w-180) > 1e-9 || math.Abs(model.b-32) > 1e-9 {
+		t.Errorf("the perfect fit is %v, want w=180 b=32", model)
+	}
+
+	// Noisy labels can't be fit perfectly.
+	x, y = generateDataSets(0, 1, 0, NewRandSource(2))
+	for i := range x {
+		x[i] /= 100
+	}
+	if result := trainModel(NewNanoNeuron(NewRandSource(1)), 2000, 1, x, y, TrainOptions{}); result.PerfectFit || len(result.CostHistory) != 2000 {
+		t.Errorf("the noisy data stopped after %d epochs as a perfect fit", len(result.CostHistory))
+	}
+}
+
+func TestSlowEpochsWarnAndShrinkTheBatches(t *testing.T) {
+	// A big data-set can't make it through an epoch in a nanosecond.
+	const m = 200000
+	x, y := make([]float64, m), make([]float64, m)
+	for i := range x {
+		x[i] = float64(i) / m
+		y[i] = celsiusToFahrenheit(x[i])
+	}
+	var records []slog.Record
+	result, err := Train(NewNanoNeuron(NewRandSource(1)), x, y, 4, 0.1, TrainOptions{
+		MaxEpochDuration: time.Nanosecond,
+		SlowEpochShrink:  true,
+		Logger:           slog.New(recordingHandler{&records}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.SlowEpochs != 4 || len(records) != 4 || records[0].Level != slog.LevelWarn || records[0].Message != "slow epoch" {
+		t.Fatalf("%d slow epochs logged %d records, want 4 warnings", result.SlowEpochs, len(records))
+	}
+	if want := []int{m, m / 2, m / 4, m / 8}; !slices.Equal(result.BatchSizeHistory, want) {
+		t.Errorf("got the batch sizes %v, want %v", result.BatchSizeHistory, want)
+	}
+
+	result, err = Train(NewNanoNeuron(NewRandSource(1)), x, y, 4, 0.1, TrainOptions{MaxEpochDuration: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.SlowEpochs != 0 || result.BatchSizeHistory != nil {
+		t.Errorf("fast epochs were %d slow with the batch sizes %v", result.SlowEpochs, result.BatchSizeHistory)
+	}
+}
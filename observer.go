@@ -0,0 +1,147 @@
+package main
+
+import "log/slog"
+
+// TrainState is what the training loop knows at the end of an epoch.
+type TrainState struct {
+	Cost float64 // the cost of the epoch
+	W    float64 // the parameters after the epoch
+	B    float64
+	DW   float64 // the last gradient of the epoch
+	DB   float64
+	// MeanAbsGrad is the mean absolute gradient (|dW| + |dB|) / 2 over the updates of the epoch.
+	MeanAbsGrad float64
+	Rate        float64 // the learning rate of the epoch
+
+	stop *bool
+}
+
+// Stop ends the training after this epoch (see EarlyStopper).
+func (s TrainState) Stop() {
+	*s.stop = true
+}
+
+// Control is the answer of TrainOptions.Control about how to go on after an epoch.
+type Control int
+
+const (
+	// Continue goes on with the next epoch.
+	Continue Control = iota
+	// Stop ends the training after this epoch, like TrainState.Stop.
+	Stop
+	// Pause waits for TrainOptions.Resume before the next epoch.
+	Pause
+)
+
+// TrainObserver watches the training. All the observers in TrainOptions.Observers are
+// called at the end of every epoch in the order they are registered, so small hooks like
+// the ones below can be composed instead of writing one big callback.
+type TrainObserver interface {
+	OnEpochEnd(epoch int, state TrainState)
+}
+
+// ObserverFunc turns a plain function into a TrainObserver.
+type ObserverFunc func(epoch int, state TrainState)
+
+// OnEpochEnd implements TrainObserver.
+func (f ObserverFunc) OnEpochEnd(epoch int, state TrainState) { f(epoch, state) }
+
+// LogObserver writes a structured "training progress" record every Every epochs to Logger.
+type LogObserver struct {
+	Logger *slog.Logger
+	Every  int
+}
+
+// OnEpochEnd implements TrainObserver.
+func (o LogObserver) OnEpochEnd(epoch int, state TrainState) {
+	if o.Every > 0 && epoch%o.Every == 0 {
+		o.Logger.Info("training progress",
+			slog.Int("epoch", epoch),
+			slog.Float64("cost", state.Cost),
+			slog.Float64("w", state.W),
+			slog.Float64("b", state.B),
+		)
+	}
+}
+
+// CostRecorder collects the cost of every epoch it sees.
+type CostRecorder struct {
+	Costs []float64
+}
+
+// OnEpochEnd implements TrainObserver.
+func (r *CostRecorder) OnEpochEnd(_ int, state TrainState) {
+	r.Costs = append(r.Costs, state.Cost)
+}
+
+// EarlyStopper stops the training as soon as the cost reaches TargetCost,
+// the training result then reports it as converged.
+type EarlyStopper struct {
+	TargetCost float64
+}
+
+// OnEpochEnd implements TrainObserver.
+func (s EarlyStopper) OnEpochEnd(_ int, state TrainState) {
+	if state.Cost <= s.TargetCost {
+		state.Stop()
+	}
+}
+
+// R2Stopper stops the training as soon as the coefficient of determination R² of the epoch
+// reaches TargetR2, i.e. "stop at R² >= 0.99". Unlike the TargetCost of EarlyStopper it doesn't
+// depend on the scale of the data: R² = 1 - cost / (costScale * variance of the labels).
+// Create it with NewR2Stopper, which measures the variance of the training labels.
+type R2Stopper struct {
+	TargetR2 float64
+
+	variance float64
+}
+
+// NewR2Stopper creates an R2Stopper for a training on the labels 'yTrain'.
+// With constant labels R² is undefined and the training stops only at a zero cost.
+func NewR2Stopper(yTrain []float64, targetR2 float64) R2Stopper {
+	s := stdDev(yTrain, mean(yTrain))
+	return R2Stopper{TargetR2: targetR2, variance: s * s}
+}
+
+// OnEpochEnd implements TrainObserver.
+func (s R2Stopper) OnEpochEnd(_ int, state TrainState) {
+	if state.Cost <= (1-s.TargetR2)*costScale*s.variance {
+		state.Stop()
+	}
+}
+
+// VanishingGradientWarner warns about vanished gradients: the mean absolute gradient of the
+// epochs (TrainState.MeanAbsGrad) stays below Threshold for Patience epochs in a row while the
+// cost is still above MinCost. That happens i.e. with a Sigmoid or a Tanh saturated by badly
+// scaled inputs, where the training crawls on without learning anything.
+// Every such streak logs one warning on Logger (nil only counts them) and increments Warnings.
+type VanishingGradientWarner struct {
+	Threshold float64
+	Patience  int
+	MinCost   float64
+	Logger    *slog.Logger
+	Warnings  int
+
+	streak int
+}
+
+// OnEpochEnd implements TrainObserver.
+func (v *VanishingGradientWarner) OnEpochEnd(epoch int, state TrainState) {
+	if state.MeanAbsGrad >= v.Threshold || state.Cost <= v.MinCost {
+		v.streak = 0
+		return
+	}
+	v.streak++
+	if v.streak != max(v.Patience, 1) {
+		return
+	}
+	v.Warnings++
+	if v.Logger != nil {
+		v.Logger.Warn("vanishing gradient",
+			slog.Int("epoch", epoch),
+			slog.Float64("cost", state.Cost),
+			slog.Float64("mean_abs_grad", state.MeanAbsGrad),
+		)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"log/slog"
+	"slices"
+	"testing"
+)
+
+func TestObserversAreCalledInRegistrationOrder(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	var calls []string
+	record := func(name string) TrainObserver {
+		return ObserverFunc(func(int, TrainState) {
+			calls = append(calls, name)
+		})
+	}
+	costs := &CostRecorder{}
+	result := trainModel(NewNanoNeuron(NewRandSource(1)), 3, 0.0005, x, y, TrainOptions{
+		Observers: []TrainObserver{record("first"), costs, record("second")},
+	})
+	if want := []string{"first", "second", "first", "second", "first", "second"}; !slices.Equal(calls, want) {
+		t.Errorf("got the calls %v, want %v", calls, want)
+	}
+	if !slices.Equal(costs.Costs, result.CostHistory) {
+		t.Errorf("recorded the costs %v, want %v", costs.Costs, result.CostHistory)
+	}
+}
+
+func TestEarlyStopperStopsAtTheTargetCost(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	const target = 10
+	result := trainModel(NewNanoNeuron(NewRandSource(1)), 70000, 0.0005, x, y, TrainOptions{
+		Observers: []TrainObserver{EarlyStopper{TargetCost: target}},
+	})
+	history := result.CostHistory
+	if !result.Converged || history[len(history)-1] > target || history[len(history)-2] <= target {
+		t.Errorf("stopped after %d epochs at cost %v, converged %v", len(history), result.FinalCost(), result.Converged)
+	}
+}
+
+func TestR2StopperStopsAtTheTargetR2(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	const target = 0.99
+	var r2 []float64
+	recordR2 := ObserverFunc(func(_ int, state TrainState) {
+		// The cost of an epoch is measured with the parameters before its update.
+		before := &NanoNeuron{w: state.W - state.Rate*state.DW, b: state.B - state.Rate*state.DB}
+		r2 = append(r2, Evaluate(before, x, y).R2)
+	})
+	result := trainModel(&NanoNeuron{w: 0.1, b: 0.1}, 70000, 0.0005, x, y, TrainOptions{
+		Observers: []TrainObserver{recordR2, NewR2Stopper(y, target)},
+	})
+	n := len(result.CostHistory)
+	if !result.Converged || n == 70000 {
+		t.Fatalf("trained %d epochs, converged %v, want a stop at R² %v", n, result.Converged, target)
+	}
+	if r2[n-1] < target-1e-9 || r2[n-2] >= target {
+		t.Errorf("stopped at R² %v after %v, want the first epoch reaching %v", r2[n-1], r2[n-2], target)
+	}
+}
+
+func TestVanishingGradientWarnerCatchesASaturatedSigmoid(t *testing.T) {
+	// The class is 1 above x = 50, but the badly scaled inputs drive the sigmoid deep into its flat tail.
+	x, y := make([]float64, 100), make([]float64, 100)
+	for i := range x {
+		x[i] = float64(i)
+		if i > 50 {
+			y[i] = 1
+		}
+	}
+	var records []slog.Record
+	warner := &VanishingGradientWarner{Threshold: 1e-6, Patience: 10, MinCost: 0.01, Logger: slog.New(recordingHandler{&records})}
+	result := trainModel(&NanoNeuron{w: 0, b: -60, activation: Sigmoid{}}, 100, 0.5, x, y, TrainOptions{Observers: []TrainObserver{warner}})
+	if warner.Warnings != 1 || len(records) != 1 {
+		t.Fatalf("got %d warnings and %d records, want one for the single streak", warner.Warnings, len(records))
+	}
+	if records[0].Level != slog.LevelWarn {
+		t.Errorf("logged at %v, want %v", records[0].Level, slog.LevelWarn)
+	}
+	if len(result.MeanAbsGradHistory) != 100 || result.MeanAbsGradHistory[99] >= 1e-6 {
+		t.Errorf("mean absolute gradients %v, want 100 vanishing ones", result.MeanAbsGradHistory)
+	}
+
+	// Well scaled inputs keep the sigmoid in its steep middle.
+	scaled := make([]float64, len(x))
+	for i := range x {
+		scaled[i] = (x[i] - 50) / 10
+	}
+	healthy := &VanishingGradientWarner{Threshold: 1e-6, Patience: 10, MinCost: 0.01}
+	trainModel(&NanoNeuron{activation: Sigmoid{}}, 100, 0.5, scaled, y, TrainOptions{Observers: []TrainObserver{healthy}})
+	if healthy.Warnings != 0 {
+		t.Errorf("the well scaled training got %d warnings", healthy.Warnings)
+	}
+}
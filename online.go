@@ -0,0 +1,79 @@
+package main
+
+import "fmt"
+
+// OnlineTrainer keeps teaching one model in a long-running service that receives new labeled
+// examples over time. Examples are appended to Data and Train can be called again and again;
+// every call continues from where the previous one stopped: the model keeps its parameters and
+// the learning rate schedule continues counting epochs instead of starting over.
+type OnlineTrainer struct {
+	Model   *NanoNeuron
+	Alpha   float64
+	Options TrainOptions
+	Data    DataSet
+
+	epochs int // epochs trained so far
+}
+
+// Append adds a new labeled example that will be used by the following Train calls.
+func (t *OnlineTrainer) Append(x, y float64) {
+	t.Data.Append(x, y)
+}
+
+// Epochs is the total number of epochs trained so far.
+func (t *OnlineTrainer) Epochs() int {
+	return t.epochs
+}
+
+// Train continues the training for 'epochs' more epochs on all the examples collected so far.
+// AverageTail is an ErrInvalidHyperparameter: an online training has no end to average the tail of.
+func (t *OnlineTrainer) Train(epochs int) (*TrainingResult, error) {
+	if t.Options.AverageTail != 0 {
+		return nil, fmt.Errorf("%w: AverageTail %v, an online training has no tail", ErrInvalidHyperparameter, t.Options.AverageTail)
+	}
+	result, err := Train(t.Model, t.Data.X, t.Data.Y, epochs, t.Alpha, continuedOptions(t.Options, t.epochs))
+	if result != nil {
+		t.epochs += len(result.CostHistory)
+	}
+	return result, err
+}
+
+// offsetSchedule shifts the epochs of a schedule, so it continues where a previous training stopped.
+type offsetSchedule struct {
+	schedule LearningRateSchedule
+	offset   int
+}
+
+func (s offsetSchedule) Rate(epoch int) float64 {
+	return s.schedule.Rate(epoch + s.offset)
+}
+
+func (s offsetSchedule) endsCycle(epoch int) bool {
+	schedule, ok := s.schedule.(cycleEnder)
+	return ok && schedule.endsCycle(epoch+s.offset)
+}
+
+func (s offsetSchedule) ObserveCost(epoch int, cost float64) {
+	if schedule, ok := s.schedule.(CostAwareSchedule); ok {
+		schedule.ObserveCost(epoch+s.offset, cost)
+	}
+}
+
+// ForwardIncremental updates the average cost 'prevCost' of 'prevN' examples with the new
+// examples only, instead of recomputing the cost of all of them, for a streaming evaluation.
+// The result is the cost forwardPropagation would report for all the prevN + len(newX) examples
+// (as long as the model hasn't changed in the meantime).
+func ForwardIncremental(model *NanoNeuron, newX, newY []float64, prevCost float64, prevN int) float64 {
+	mustMatch("incremental forward propagation", newX, newY)
+	if len(newX) == 0 {
+		return prevCost
+	}
+	sum := 0.0
+	for i, x := range newX {
+		sum += predictionCost(newY[i], model.predict(x))
+	}
+	if prevN == 0 {
+		return sum / float64(len(newX))
+	}
+	return (prevCost*float64(prevN) + sum) / float64(prevN+len(newX))
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestOnlineTrainerLearnsTheAppendedExamples(t *testing.T) {
+	trainer := &OnlineTrainer{Model: NewNanoNeuron(NewRandSource(1)), Alpha: 0.0005,
+		Options: TrainOptions{Optimizer: NewMomentum(0.5)}}
+	for c := range 10 {
+		trainer.Append(float64(c), celsiusToFahrenheit(float64(c)))
+	}
+	if _, err := trainer.Train(2000); err != nil {
+		t.Fatalf("Train: %v", err)
+	}
+
+	var newX, newY []float64
+	for c := 60; c < 70; c++ {
+		newX = append(newX, float64(c))
+		newY = append(newY, celsiusToFahrenheit(float64(c)))
+		trainer.Append(newX[len(newX)-1], newY[len(newY)-1])
+	}
+	_, before := forwardPropagation(trainer.Model, newX, newY)
+	if _, err := trainer.Train(2000); err != nil {
+		t.Fatalf("Train: %v", err)
+	}
+	_, after := forwardPropagation(trainer.Model, newX, newY)
+	if !(after < before/10) {
+		t.Errorf("the cost of the new examples went from %v to %v", before, after)
+	}
+	if trainer.Epochs() != 4000 || trainer.Data.Len() != 20 {
+		t.Errorf("trained %d epochs on %d examples, want 4000 on 20", trainer.Epochs(), trainer.Data.Len())
+	}
+}
+
+func TestForwardIncrementalEqualsTheFullCost(t *testing.T) {
+	x, y := generateDataSets(0, 2, 0, NewRandSource(1))
+	model := &NanoNeuron{w: 1.7, b: 30}
+	cost, n := 0.0, 0
+	for _, end := range []int{0, 1, 10, 10, 57, len(x)} {
+		cost = ForwardIncremental(model, x[n:end], y[n:end], cost, n)
+		n = end
+		if n == 0 {
+			continue
+		}
+		if _, full := forwardPropagation(model, x[:n], y[:n]); math.Abs(cost-full) > 1e-12*full {
+			t.Errorf("after %d examples the incremental cost is %v, the full one %v", n, cost, full)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// Optimizer decides how the gradient moves the parameters. The plain gradient descent
+// (no Optimizer) simply steps by rate * gradient; optimizers may remember the previous
+// gradients and use them to take smarter steps.
+type Optimizer interface {
+	// Step returns the updates of 'w' and 'b' for the gradient (dW, dB) of backwardPropagation
+	// (already pointing downhill) and the learning rate of the current epoch.
+	Step(dW, dB, rate float64) (stepW, stepB float64)
+}
+
+// OptimizerState is the memory of an optimizer between its steps, i.e. the velocity of Momentum,
+// so a training can be saved (see SaveCheckpoint) and resumed exactly where it stopped.
+// The zero value is the state of the plain gradient descent, which remembers nothing.
+type OptimizerState struct {
+	Kind    string    `json:"kind"`    // which optimizer the state belongs to, i.e. "momentum"
+	Moments []float64 `json:"moments"` // the remembered values, their meaning depends on the optimizer
+}
+
+// StatefulOptimizer is an Optimizer whose memory can be saved and restored.
+type StatefulOptimizer interface {
+	Optimizer
+	State() OptimizerState
+	SetState(state OptimizerState) error
+}
+
+// Momentum is gradient descent with momentum: the updates keep a velocity that accumulates
+// the past gradients, v = Beta * v + gradient, and the parameters move by rate * v.
+// It rolls faster down long shallow slopes and damps the zig-zagging of noisy gradients.
+type Momentum struct {
+	Beta float64 // how much of the velocity is kept between the steps, i.e. 0.9
+	// MaxVelocityNorm clips the L2 norm of the velocity after every update (0 disables it).
+	// Clipping the gradient alone isn't enough with a high Beta: the velocity sums up
+	// to 1 / (1 - Beta) clipped gradients and can still blow up.
+	MaxVelocityNorm float64
+
+	vW, vB float64
+}
+
+// NewMomentum creates a momentum optimizer at rest.
+func NewMomentum(beta float64) *Momentum {
+	return &Momentum{Beta: beta}
+}
+
+// Step implements Optimizer.
+func (o *Momentum) Step(dW, dB, rate float64) (float64, float64) {
+	o.vW = o.Beta*o.vW + dW
+	o.vB = o.Beta*o.vB + dB
+	if o.MaxVelocityNorm > 0 {
+		o.vW, o.vB = clipNorm(o.vW, o.vB, o.MaxVelocityNorm)
+	}
+	return rate * o.vW, rate * o.vB
+}
+
+// Velocity is the current velocity of 'w' and 'b'.
+func (o *Momentum) Velocity() (vW, vB float64) {
+	return o.vW, o.vB
+}
+
+// State implements StatefulOptimizer.
+func (o *Momentum) State() OptimizerState {
+	return OptimizerState{Kind: "momentum", Moments: []float64{o.vW, o.vB}}
+}
+
+// SetState implements StatefulOptimizer.
+func (o *Momentum) SetState(state OptimizerState) error {
+	if err := checkState(state, "momentum", 2); err != nil {
+		return err
+	}
+	o.vW, o.vB = state.Moments[0], state.Moments[1]
+	return nil
+}
+
+// Adam adapts the step of every parameter: it keeps the running average of the gradients (the
+// first moment) and of their squares (the second moment), corrects both for starting at zero and
+// moves by rate * first / (sqrt(second) + Epsilon). The steps are about 'rate' long whatever the
+// scale of the gradient, so Adam usually wants a larger rate than the plain gradient descent.
+type Adam struct {
+	Beta1   float64 // the decay of the first moment, i.e. 0.9
+	Beta2   float64 // the decay of the second moment, i.e. 0.999
+	Epsilon float64 // keeps the division finite while the second moment is zero
+
+	mW, mB, vW, vB float64
+	t              int
+}
+
+// NewAdam creates an Adam optimizer with no history.
+func NewAdam(beta1, beta2 float64) *Adam {
+	return &Adam{Beta1: beta1, Beta2: beta2, Epsilon: 1e-8}
+}
+
+// Step implements Optimizer.
+func (o *Adam) Step(dW, dB, rate float64) (float64, float64) {
+	o.t++
+	o.mW = o.Beta1*o.mW + (1-o.Beta1)*dW
+	o.mB = o.Beta1*o.mB + (1-o.Beta1)*dB
+	o.vW = o.Beta2*o.vW + (1-o.Beta2)*dW*dW
+	o.vB = o.Beta2*o.vB + (1-o.Beta2)*dB*dB
+	correction1 := 1 - math.Pow(o.Beta1, float64(o.t))
+	correction2 := 1 - math.Pow(o.Beta2, float64(o.t))
+	stepW := rate * (o.mW / correction1) / (math.Sqrt(o.vW/correction2) + o.Epsilon)
+	stepB := rate * (o.mB / correction1) / (math.Sqrt(o.vB/correction2) + o.Epsilon)
+	return stepW, stepB
+}
+
+// State implements StatefulOptimizer. The step count is kept as the last moment.
+func (o *Adam) State() OptimizerState {
+	return OptimizerState{Kind: "adam", Moments: []float64{o.mW, o.mB, o.vW, o.vB, float64(o.t)}}
+}
+
+// SetState implements StatefulOptimizer.
+func (o *Adam) SetState(state OptimizerState) error {
+	if err := checkState(state, "adam", 5); err != nil {
+		return err
+	}
+	o.mW, o.mB, o.vW, o.vB = state.Moments[0], state.Moments[1], state.Moments[2], state.Moments[3]
+	o.t = int(state.Moments[4])
+	return nil
+}
+
+// RMSProp divides the gradient by the root of the running average of its squares,
+// s = Rho * s + (1 - Rho) * gradient², and moves by rate * gradient / (sqrt(s) + Epsilon).
+type RMSProp struct {
+	Rho     float64 // the decay of the average, i.e. 0.9
+	Epsilon float64 // keeps the division finite while the average is zero
+
+	sW, sB float64
+}
+
+// NewRMSProp creates an RMSProp optimizer with no history.
+func NewRMSProp(rho float64) *RMSProp {
+	return &RMSProp{Rho: rho, Epsilon: 1e-8}
+}
+
+// Step implements Optimizer.
+func (o *RMSProp) Step(dW, dB, rate float64) (float64, float64) {
+	o.sW = o.Rho*o.sW + (1-o.Rho)*dW*dW
+	o.sB = o.Rho*o.sB + (1-o.Rho)*dB*dB
+	return rate * dW / (math.Sqrt(o.sW) + o.Epsilon), rate * dB / (math.Sqrt(o.sB) + o.Epsilon)
+}
+
+// State implements StatefulOptimizer.
+func (o *RMSProp) State() OptimizerState {
+	return OptimizerState{Kind: "rmsprop", Moments: []float64{o.sW, o.sB}}
+}
+
+// SetState implements StatefulOptimizer.
+func (o *RMSProp) SetState(state OptimizerState) error {
+	if err := checkState(state, "rmsprop", 2); err != nil {
+		return err
+	}
+	o.sW, o.sB = state.Moments[0], state.Moments[1]
+	return nil
+}
+
+// Adagrad divides the gradient by the root of the sum of all the squared gradients so far,
+// so the steps of a parameter shrink the more it has already moved.
+type Adagrad struct {
+	Epsilon float64 // keeps the division finite while the sum is zero
+
+	sW, sB float64
+}
+
+// NewAdagrad creates an Adagrad optimizer with no history.
+func NewAdagrad() *Adagrad {
+	return &Adagrad{Epsilon: 1e-8}
+}
+
+// Step implements Optimizer.
+func (o *Adagrad) Step(dW, dB, rate float64) (float64, float64) {
+	o.sW += dW * dW
+	o.sB += dB * dB
+	return rate * dW / (math.Sqrt(o.sW) + o.Epsilon), rate * dB / (math.Sqrt(o.sB) + o.Epsilon)
+}
+
+// State implements StatefulOptimizer.
+func (o *Adagrad) State() OptimizerState {
+	return OptimizerState{Kind: "adagrad", Moments: []float64{o.sW, o.sB}}
+}
+
+// SetState implements StatefulOptimizer.
+func (o *Adagrad) SetState(state OptimizerState) error {
+	if err := checkState(state, "adagrad", 2); err != nil {
+		return err
+	}
+	o.sW, o.sB = state.Moments[0], state.Moments[1]
+	return nil
+}
+
+// selectOptimizer maps the value of the -optimizer flag to the optimizer of the demo,
+// "sgd" is the plain gradient descent (nil).
+func selectOptimizer(flagValue string) (Optimizer, error) {
+	switch flagValue {
+	case "sgd":
+		return nil, nil
+	case "momentum":
+		return NewMomentum(0.9), nil
+	case "adam":
+		return NewAdam(0.9, 0.999), nil
+	case "rmsprop":
+		return NewRMSProp(0.9), nil
+	case "adagrad":
+		return NewAdagrad(), nil
+	}
+	return nil, fmt.Errorf("unknown optimizer %q, want sgd, momentum, adam, rmsprop or adagrad", flagValue)
+}
+
+// checkState verifies that 'state' belongs to the 'kind' optimizer with 'moments' values.
+func checkState(state OptimizerState, kind string, moments int) error {
+	if state.Kind != kind {
+		return fmt.Errorf("optimizer state: %q state given to a %s optimizer", state.Kind, kind)
+	}
+	if len(state.Moments) != moments {
+		return fmt.Errorf("optimizer state: %s needs %d moments, got %d", kind, moments, len(state.Moments))
+	}
+	return nil
+}
+
+// clipNorm scales the (a, b) vector down to the L2 norm 'limit' if it is longer.
+func clipNorm(a, b, limit float64) (float64, float64) {
+	norm := math.Hypot(a, b)
+	if norm > limit {
+		a, b = a*limit/norm, b*limit/norm
+	}
+	return a, b
+}
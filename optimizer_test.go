@@ -0,0 +1,63 @@
+package main
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMaxVelocityNormBoundsTheVelocity(t *testing.T) {
+	x, y := generateDataSets(0, 5, 0, NewRandSource(2))
+	const maxNorm = 10
+	peak := func(momentum *Momentum) float64 {
+		highest := 0.0
+		trainModel(NewNanoNeuron(NewRandSource(1)), 500, 0.00001, x, y, TrainOptions{
+			Optimizer:    momentum,
+			GradClipNorm: 5,
+			BatchSize:    10,
+			Progress: func(int, float64, float64, float64) {
+				highest = math.Max(highest, math.Hypot(momentum.Velocity()))
+			},
+		})
+		return highest
+	}
+	if unclipped := peak(NewMomentum(0.99)); !(unclipped > 2*maxNorm) {
+		t.Fatalf("the velocity peaked at %v even without clipping", unclipped)
+	}
+	if clipped := peak(&Momentum{Beta: 0.99, MaxVelocityNorm: maxNorm}); clipped > maxNorm*(1+1e-12) {
+		t.Errorf("the clipped velocity peaked at %v, above %v", clipped, float64(maxNorm))
+	}
+}
+
+func TestSelectOptimizer(t *testing.T) {
+	for flag, want := range map[string]Optimizer{
+		"sgd":      nil,
+		"momentum": &Momentum{},
+		"adam":     &Adam{},
+		"rmsprop":  &RMSProp{},
+		"adagrad":  &Adagrad{},
+	} {
+		got, err := selectOptimizer(flag)
+		if err != nil {
+			t.Fatalf("%s: %v", flag, err)
+		}
+		if reflect.TypeOf(got) != reflect.TypeOf(want) {
+			t.Errorf("%s: got %T, want %T", flag, got, want)
+		}
+	}
+	if _, err := selectOptimizer("lbfgs"); err == nil || !strings.Contains(err.Error(), "adagrad") {
+		t.Errorf("the unknown optimizer gave %v, want an error listing the valid ones", err)
+	}
+
+	// Every adaptive optimizer fits the demo, given a learning rate to match.
+	x, y := generateDataSets(0, 0, 0, nil)
+	for flag, alpha := range map[string]float64{"adam": 0.5, "rmsprop": 0.01, "adagrad": 5} {
+		optimizer, _ := selectOptimizer(flag)
+		model := NewNanoNeuron(NewRandSource(1))
+		result := trainModel(model, 5000, alpha, x, y, TrainOptions{Optimizer: optimizer})
+		if !(result.FinalCost() < result.InitialCost/100) {
+			t.Errorf("%s: the cost went from %v to %v", flag, result.InitialCost, result.FinalCost())
+		}
+	}
+}
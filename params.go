@@ -0,0 +1,21 @@
+package main
+
+import "fmt"
+
+// Parameters returns the trainable parameters of the model as a flat vector [w, b], so generic
+// optimizer and serialization code can work on any model without knowing its fields.
+// The vector is a copy: changing it doesn't change the model, see SetParameters.
+// The activation and the output transform aren't trained, so they aren't part of it.
+func (n *NanoNeuron) Parameters() []float64 {
+	return []float64{n.w, n.b}
+}
+
+// SetParameters sets the trainable parameters from a vector laid out like the one of Parameters.
+// A vector of a different length is rejected with ErrLengthMismatch and leaves the model alone.
+func (n *NanoNeuron) SetParameters(p []float64) error {
+	if len(p) != 2 {
+		return fmt.Errorf("%w: the model has 2 parameters, got %d", ErrLengthMismatch, len(p))
+	}
+	n.w, n.b = p[0], p[1]
+	return nil
+}
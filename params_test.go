@@ -0,0 +1,36 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestParametersRoundTrip(t *testing.T) {
+	model := &NanoNeuron{w: 1.8, b: 32, activation: Sigmoid{}}
+	p := model.Parameters()
+	if !slices.Equal(p, []float64{1.8, 32}) {
+		t.Fatalf("got the parameters %v, want [1.8 32]", p)
+	}
+	p[0] = 5
+	if model.w != 1.8 {
+		t.Error("changing the returned vector changed the model")
+	}
+
+	other := &NanoNeuron{activation: Sigmoid{}}
+	if err := other.SetParameters(model.Parameters()); err != nil {
+		t.Fatal(err)
+	}
+	if *other != *model {
+		t.Errorf("the round trip gave %v, want %v", other, model)
+	}
+
+	for _, p := range [][]float64{nil, {1}, {1, 2, 3}} {
+		if err := other.SetParameters(p); !errors.Is(err, ErrLengthMismatch) {
+			t.Errorf("SetParameters(%v) returned %v, want ErrLengthMismatch", p, err)
+		}
+	}
+	if *other != *model {
+		t.Errorf("the rejected vectors changed the model to %v", other)
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+	"slices"
+	"strings"
+)
+
+// The size of the learning curves chart in pixels.
+const (
+	plotWidth  = 800
+	plotHeight = 500
+	plotMargin = 40
+	fontScale  = 2 // pixels per font dot
+)
+
+// The colors of the curves, reused in this order when there are more curves.
+var plotPalette = []color.RGBA{
+	{R: 0x1f, G: 0x77, B: 0xb4, A: 0xff},
+	{R: 0xff, G: 0x7f, B: 0x0e, A: 0xff},
+	{R: 0x2c, G: 0xa0, B: 0x2c, A: 0xff},
+	{R: 0xd6, G: 0x27, B: 0x28, A: 0xff},
+	{R: 0x94, G: 0x67, B: 0xbd, A: 0xff},
+	{R: 0x8c, G: 0x56, B: 0x4b, A: 0xff},
+	{R: 0xe3, G: 0x77, B: 0xc2, A: 0xff},
+	{R: 0x7f, G: 0x7f, B: 0x7f, A: 0xff},
+}
+
+// PlotLearningCurves draws several labeled cost histories into one PNG chart at 'path',
+// i.e. to compare how different training configurations learn. The epochs are on the
+// horizontal axis, the costs on a logarithmic vertical axis, so both the fast start and the
+// slow end of the training can be seen. Shorter curves simply end earlier; the costs that
+// can't be drawn on a logarithmic axis (zero, negative, NaN, infinite) leave gaps.
+// A legend in the top-right corner names the curves in alphabetical order.
+func PlotLearningCurves(curves map[string][]float64, path string) error {
+	if len(curves) == 0 {
+		return errors.New("plot learning curves: no curves")
+	}
+	labels := make([]string, 0, len(curves))
+	epochs := 0
+	minLog, maxLog := math.Inf(1), math.Inf(-1)
+	for label, history := range curves {
+		labels = append(labels, label)
+		epochs = max(epochs, len(history))
+		for _, cost := range history {
+			if cost > 0 && isFinite(cost) {
+				minLog = math.Min(minLog, math.Log10(cost))
+				maxLog = math.Max(maxLog, math.Log10(cost))
+			}
+		}
+	}
+	if math.IsInf(minLog, 1) {
+		return errors.New("plot learning curves: no positive finite costs to plot")
+	}
+	slices.Sort(labels)
+	if maxLog == minLog {
+		minLog, maxLog = minLog-1, maxLog+1
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, plotWidth, plotHeight))
+	fillRect(img, img.Bounds(), color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff})
+	black := color.RGBA{A: 0xff}
+	left, right, top, bottom := plotMargin, plotWidth-plotMargin, plotMargin, plotHeight-plotMargin
+	drawLine(img, left, top, left, bottom, black)
+	drawLine(img, left, bottom, right, bottom, black)
+
+	toX := func(epoch int) int {
+		if epochs < 2 {
+			return left
+		}
+		return left + epoch*(right-left)/(epochs-1)
+	}
+	toY := func(cost float64) int {
+		return bottom - int(math.Round((math.Log10(cost)-minLog)/(maxLog-minLog)*float64(bottom-top)))
+	}
+	for i, label := range labels {
+		c := plotPalette[i%len(plotPalette)]
+		previous := false
+		var px, py int
+		for epoch, cost := range curves[label] {
+			if !(cost > 0 && isFinite(cost)) {
+				previous = false
+				continue
+			}
+			x, y := toX(epoch), toY(cost)
+			if previous {
+				drawLine(img, px, py, x, y, c)
+			} else {
+				img.Set(x, y, c)
+			}
+			px, py, previous = x, y, true
+		}
+	}
+	drawText(img, left+4, 8, fmt.Sprintf("cost %.3g - %.3g, %d epochs", math.Pow(10, minLog), math.Pow(10, maxLog), epochs), black)
+	drawLegend(img, labels, right)
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("plot learning curves: %w", err)
+	}
+	if err := png.Encode(file, img); err != nil {
+		file.Close()
+		return fmt.Errorf("plot learning curves: %w", err)
+	}
+	return file.Close()
+}
+
+// drawLegend draws a color swatch and the label of every curve under each other, right-aligned to 'right'.
+func drawLegend(img *image.RGBA, labels []string, right int) {
+	lineHeight := 7 * fontScale
+	width := 0
+	for _, label := range labels {
+		width = max(width, textWidth(label))
+	}
+	x := right - width - 24
+	for i, label := range labels {
+		y := plotMargin + 8 + i*lineHeight
+		c := plotPalette[i%len(plotPalette)]
+		fillRect(img, image.Rect(x, y+2*fontScale, x+16, y+3*fontScale), c)
+		drawText(img, x+22, y, label, color.RGBA{A: 0xff})
+	}
+}
+
+func fillRect(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+}
+
+// drawLine draws a straight line from (x0, y0) to (x1, y1) with Bresenham's algorithm.
+func drawLine(img *image.RGBA, x0, y0, x1, y1 int, c color.RGBA) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	e := dx + dy
+	for {
+		img.SetRGBA(x0, y0, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// font is a tiny 3x5 dots font, so the chart needs no font files. Every glyph is 5 rows of
+// 3 dots. Letters are drawn uppercase and characters without a glyph are drawn as spaces.
+var font = map[rune]string{
+	'A': "010101111101101", 'B': "110101110101110", 'C': "011100100100011", 'D': "110101101101110",
+	'E': "111100110100111", 'F': "111100110100100", 'G': "011100101101011", 'H': "101101111101101",
+	'I': "111010010010111", 'J': "001001001101010", 'K': "101101110101101", 'L': "100100100100111",
+	'M': "101111111101101", 'N': "110101101101101", 'O': "010101101101010", 'P': "110101110100100",
+	'Q': "010101101110011", 'R': "110101110101101", 'S': "011100010001110", 'T': "111010010010010",
+	'U': "101101101101111", 'V': "101101101101010", 'W': "101101111111101", 'X': "101101010101101",
+	'Y': "101101010010010", 'Z': "111001010100111",
+	'0': "111101101101111", '1': "010110010010111", '2': "110001010100111", '3': "110001010001110",
+	'4': "101101111001001", '5': "111100110001110", '6': "011100111101111", '7': "111001010010010",
+	'8': "111101111101111", '9': "111101111001110",
+	'-': "000000111000000", '_': "000000000000111", '.': "000000000000010", '=': "000111000111000",
+	':': "000010000010000", '/': "001001010100100", '(': "010100100100010", ')': "010001001001010",
+	',': "000000000010100", '+': "000010111010000",
+}
+
+// textWidth is the width of 'text' drawn by drawText in pixels.
+func textWidth(text string) int {
+	return len([]rune(text)) * 4 * fontScale
+}
+
+// drawText draws 'text' with its top-left corner at (x, y).
+func drawText(img *image.RGBA, x, y int, text string, c color.RGBA) {
+	for _, r := range strings.ToUpper(text) {
+		for dot, on := range font[r] {
+			if on != '1' {
+				continue
+			}
+			dx, dy := x+(dot%3)*fontScale, y+(dot/3)*fontScale
+			fillRect(img, image.Rect(dx, dy, dx+fontScale, dy+fontScale), c)
+		}
+		x += 4 * fontScale
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPlotLearningCurvesWritesAPNG(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "curves.png")
+	curves := map[string][]float64{
+		"sgd":      {100, 50, 25, 12, 6, 3, 1.5},
+		"adam":     {100, 10, 1, 0.1},
+		"diverged": {100, 1000, math.Inf(1), math.NaN()},
+	}
+	if err := PlotLearningCurves(curves, path); err != nil {
+		t.Fatalf("PlotLearningCurves: %v", err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatalf("not a valid PNG: %v", err)
+	}
+	if size := img.Bounds().Size(); size.X != plotWidth || size.Y != plotHeight {
+		t.Errorf("the chart is %v, want %dx%d", size, plotWidth, plotHeight)
+	}
+}
+
+func TestPlotLearningCurvesRejectsNothingToPlot(t *testing.T) {
+	dir := t.TempDir()
+	for name, curves := range map[string]map[string][]float64{
+		"empty":         {},
+		"only NaN cost": {"nan": {math.NaN()}},
+	} {
+		path := filepath.Join(dir, "curves.png")
+		if err := PlotLearningCurves(curves, path); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+		if _, err := os.Stat(path); err == nil {
+			t.Errorf("%s: a chart was written anyway", name)
+		}
+	}
+}
+
+func TestDrawLineReachesTheEndInEveryDirection(t *testing.T) {
+	red := color.RGBA{R: 0xff, A: 0xff}
+	for _, end := range [][2]int{{30, 20}, {20, 30}, {10, 20}, {0, 25}, {20, 0}, {40, 27}, {3, 38}, {20, 20}} {
+		img := image.NewRGBA(image.Rect(0, 0, 41, 41))
+		drawLine(img, 20, 20, end[0], end[1], red)
+		if img.RGBAAt(end[0], end[1]) != red {
+			t.Errorf("the line to %v doesn't reach it", end)
+		}
+	}
+}
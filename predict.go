@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"iter"
+	"math"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// PredictBatch returns the predictions for all the inputs in 'xs'.
+func (n *NanoNeuron) PredictBatch(xs []float64) []float64 {
+	predictions := make([]float64, len(xs))
+	for i, x := range xs {
+		predictions[i] = n.predict(x)
+	}
+	return predictions
+}
+
+// ParallelThreshold is the number of inputs below which PredictBatchParallel and
+// ForwardPropagation work sequentially, because starting the goroutines would cost more than it saves.
+var ParallelThreshold = 4096
+
+// parallelChunks counts the chunks PredictBatchParallel has handed to goroutines, so the
+// tests can tell the parallel path from the sequential one.
+var parallelChunks atomic.Int64
+
+// PredictBatchParallel is PredictBatch split across 'workers' goroutines.
+// Every worker predicts its own contiguous chunk of the inputs, so the output keeps the order of 'xs'.
+// Small inputs (or workers < 2) are predicted sequentially.
+func (n *NanoNeuron) PredictBatchParallel(xs []float64, workers int) []float64 {
+	if workers < 2 || len(xs) < ParallelThreshold {
+		return n.PredictBatch(xs)
+	}
+	predictions := make([]float64, len(xs))
+	chunk := (len(xs) + workers - 1) / workers
+	var wg sync.WaitGroup
+	for start := 0; start < len(xs); start += chunk {
+		end := min(start+chunk, len(xs))
+		wg.Add(1)
+		parallelChunks.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := start; i < end; i++ {
+				predictions[i] = n.predict(xs[i])
+			}
+		}()
+	}
+	wg.Wait()
+	return predictions
+}
+
+// ForwardPropagation is forwardPropagation with the predictions computed by 'workers' goroutines
+// (see PredictBatchParallel), for big data-sets. The cost is summed up in the order of the
+// examples, so the result is exactly the same as the one of the sequential forward propagation.
+func ForwardPropagation(model *NanoNeuron, xTrain, yTrain []float64, workers int) ([]float64, float64) {
+	mustMatch("forward propagation", xTrain, yTrain)
+	predictions := model.PredictBatchParallel(xTrain, workers)
+	cost := 0.0
+	for i, prediction := range predictions {
+		cost += predictionCost(yTrain[i], prediction)
+	}
+	return predictions, cost / float64(len(xTrain))
+}
+
+// PredictSeq lazily yields (index, prediction) pairs for the inputs in 'xs'.
+// Unlike PredictBatch nothing is allocated, so huge input sets can be consumed with constant memory:
+//
+//	for i, y := range model.PredictSeq(xs) { ... }
+func (n *NanoNeuron) PredictSeq(xs []float64) iter.Seq2[int, float64] {
+	return func(yield func(int, float64) bool) {
+		for i, x := range xs {
+			if !yield(i, n.predict(x)) {
+				return
+			}
+		}
+	}
+}
+
+// PredictWithGradient returns the prediction for 'x' together with its derivative d(prediction)/dx.
+// For the plain linear model the derivative is just 'w', with an activation the chain rule
+// makes it activation'(w * x + b) * w.
+func (n *NanoNeuron) PredictWithGradient(x float64) (y, dydx float64) {
+	dydx = n.w
+	if n.activation != nil {
+		dydx *= n.activation.Derivative(x*n.w + n.b)
+	}
+	if n.output != nil {
+		dydx *= n.output.scale
+	}
+	return n.predict(x), dydx
+}
+
+// Sensitivity returns how much the prediction for 'x' changes with each parameter, the partial
+// derivatives d(prediction)/dw and d(prediction)/db: x and 1 for the plain linear model, both
+// scaled by activation'(w * x + b) and the output transform scale otherwise. A big dYdW means
+// that the prediction at 'x' leans on the slope more than on the bias.
+func (n *NanoNeuron) Sensitivity(x float64) (dYdW, dYdB float64) {
+	dYdW, dYdB = x, 1
+	scale := 1.0
+	if n.activation != nil {
+		scale = n.activation.Derivative(x*n.w + n.b)
+	}
+	if n.output != nil {
+		scale *= n.output.scale
+	}
+	return dYdW * scale, dYdB * scale
+}
+
+// PredictWithConfidence returns the prediction for 'x' together with a confidence in [0, 1] based
+// on the training inputs 'trainX': inside their range the model interpolates and the confidence is 1,
+// outside it extrapolates and the confidence decays as exp(-distance / width), where distance is how
+// far 'x' is past the nearest end of the range and width is the width of the range (1 if all the
+// inputs are equal). Without training inputs, or for a NaN 'x', the confidence is 0.
+func (n *NanoNeuron) PredictWithConfidence(x float64, trainX []float64) (prediction, confidence float64) {
+	prediction = n.predict(x)
+	if len(trainX) == 0 || math.IsNaN(x) {
+		return prediction, 0
+	}
+	lo, hi := slices.Min(trainX), slices.Max(trainX)
+	width := hi - lo
+	if width == 0 {
+		width = 1
+	}
+	distance := max(lo-x, x-hi, 0)
+	return prediction, math.Exp(-distance / width)
+}
+
+// PredictClamped returns the prediction for 'x' clamped into the plausible [lo, hi] range,
+// i.e. to keep a slightly imperfect model from predicting temperatures below absolute zero.
+func (n *NanoNeuron) PredictClamped(x, lo, hi float64) float64 {
+	return (&Bounds{Lower: lo, Upper: hi}).project(n.predict(x))
+}
+
+// PredictSafe is the guarded prediction for serving endpoints: instead of silently turning
+// garbage into garbage it returns ErrInvalidInput when 'x', the model parameters or the
+// prediction itself (i.e. a finite 'x' overflowing a huge 'w') are NaN or infinite.
+func (n *NanoNeuron) PredictSafe(x float64) (float64, error) {
+	if !isFinite(x) {
+		return 0, fmt.Errorf("%w: x is %v", ErrInvalidInput, x)
+	}
+	if !isFinite(n.w) || !isFinite(n.b) {
+		return 0, fmt.Errorf("%w: model parameters are w=%v b=%v", ErrInvalidInput, n.w, n.b)
+	}
+	prediction := n.predict(x)
+	if !isFinite(prediction) {
+		return 0, fmt.Errorf("%w: prediction for x=%v is %v", ErrInvalidInput, x, prediction)
+	}
+	return prediction, nil
+}
+
+// PredictInverse runs the model backwards: it returns the 'x' that is predicted as 'y',
+// i.e. Celsius for Fahrenheit, (y - b) / w for the plain model.
+// ErrNotInvertible is returned when 'w' (or the output transform scale) is zero and
+// for models with an activation.
+func (n *NanoNeuron) PredictInverse(y float64) (float64, error) {
+	if n.activation != nil {
+		return 0, fmt.Errorf("%w: activation %v", ErrNotInvertible, n.activation)
+	}
+	if n.output != nil {
+		if n.output.scale == 0 {
+			return 0, fmt.Errorf("%w: output transform scale is zero", ErrNotInvertible)
+		}
+		y = (y - n.output.offset) / n.output.scale
+	}
+	if n.w == 0 {
+		return 0, fmt.Errorf("%w: w is zero", ErrNotInvertible)
+	}
+	return (y - n.b) / n.w, nil
+}
+
+// PredictInterval returns the prediction for 'x' plus and minus 'z' residual standard deviations
+// (see ResidualStats). With normally distributed residuals z = 1.96 makes a 95% interval.
+func (n *NanoNeuron) PredictInterval(x float64, residualStd float64, z float64) (lo, hi float64) {
+	prediction := n.predict(x)
+	margin := z * residualStd
+	return prediction - margin, prediction + margin
+}
+
+// InputGradient is the derivative d(cost)/dx of the prediction cost of the example (x, y)
+// by the input, i.e. for teaching adversarial examples: which way to nudge 'x' to make the
+// model most wrong. The chain rule goes from the cost through the prediction (see
+// PredictWithGradient) down to 'x': -2 * costScale * (y - prediction) * d(prediction)/dx.
+func (n *NanoNeuron) InputGradient(x, y float64) float64 {
+	prediction, dydx := n.PredictWithGradient(x)
+	return -2 * costScale * (y - prediction) * dydx
+}
+
+// PredictWith predicts 'x' with 'act' applied to the linear output instead of the model's own
+// activation, for this call only (nil means no activation). It's handy to compare activations
+// on a trained model without setting up new models. The output transform is still applied.
+func (n *NanoNeuron) PredictWith(x float64, act Activation) float64 {
+	with := *n
+	with.activation = act
+	return with.predict(x)
+}
+
+// PredictWithState returns the prediction for 'x' and whether the activation was in its active
+// region for it: whether its derivative at w * x + b is nonzero, so the example would pass any
+// gradient back. A ReLU (LeakyReLU(0)) driven below zero is inactive; a neuron that is inactive
+// for all the inputs is dead and can't learn anymore. A model without an activation is always active.
+func (n *NanoNeuron) PredictWithState(x float64) (y float64, active bool) {
+	active = n.activation == nil || n.activation.Derivative(x*n.w+n.b) != 0
+	return n.predict(x), active
+}
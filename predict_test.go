@@ -0,0 +1,299 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func TestPredictSeqMatchesPredictBatch(t *testing.T) {
+	model := &NanoNeuron{w: 1.8, b: 32}
+	xs := []float64{-40, 0, 37, 100}
+	var got []float64
+	for i, prediction := range model.PredictSeq(xs) {
+		if i != len(got) {
+			t.Fatalf("index %d after %d predictions", i, len(got))
+		}
+		got = append(got, prediction)
+	}
+	if want := model.PredictBatch(xs); !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	seen := 0
+	for range model.PredictSeq(xs) {
+		seen++
+		if seen == 2 {
+			break
+		}
+	}
+	if seen != 2 {
+		t.Errorf("saw %d predictions, want to stop after 2", seen)
+	}
+}
+
+func TestPredictWithGradientMatchesFiniteDifferences(t *testing.T) {
+	const h = 1e-6
+	for name, model := range map[string]*NanoNeuron{
+		"linear":  {w: 1.8, b: 32},
+		"sigmoid": {w: 0.5, b: -2, activation: Sigmoid{}},
+	} {
+		for _, x := range []float64{-10, -1, 0, 4, 25} {
+			y, dydx := model.PredictWithGradient(x)
+			if y != model.predict(x) {
+				t.Errorf("%s at %v: predicted %v, want %v", name, x, y, model.predict(x))
+			}
+			numeric := (model.predict(x+h) - model.predict(x-h)) / (2 * h)
+			if math.Abs(dydx-numeric) > 1e-6 {
+				t.Errorf("%s at %v: derivative %v, finite differences %v", name, x, dydx, numeric)
+			}
+		}
+	}
+	if _, dydx := (&NanoNeuron{w: 1.8, b: 32}).PredictWithGradient(7); dydx != 1.8 {
+		t.Errorf("the linear derivative is %v, want w", dydx)
+	}
+}
+
+func TestSensitivityMatchesFiniteDifferences(t *testing.T) {
+	const h = 1e-6
+	for name, model := range map[string]*NanoNeuron{
+		"linear":    {w: 1.8, b: 32},
+		"sigmoid":   {w: 0.5, b: -2, activation: Sigmoid{}},
+		"transform": (&NanoNeuron{w: 0.3, b: 1, activation: Tanh{}}).WithOutputTransform(2, 5),
+	} {
+		for _, x := range []float64{-10, -1, 0, 4, 25} {
+			dYdW, dYdB := model.Sensitivity(x)
+			perturbed := func(dw, db float64) float64 {
+				m := *model
+				m.w += dw
+				m.b += db
+				return m.predict(x)
+			}
+			if numeric := (perturbed(h, 0) - perturbed(-h, 0)) / (2 * h); math.Abs(dYdW-numeric) > 1e-6*math.Max(1, math.Abs(x)) {
+				t.Errorf("%s at %v: dy/dw %v, finite differences %v", name, x, dYdW, numeric)
+			}
+			if numeric := (perturbed(0, h) - perturbed(0, -h)) / (2 * h); math.Abs(dYdB-numeric) > 1e-6 {
+				t.Errorf("%s at %v: dy/db %v, finite differences %v", name, x, dYdB, numeric)
+			}
+		}
+	}
+	if dYdW, dYdB := (&NanoNeuron{w: 1.8, b: 32}).Sensitivity(7); dYdW != 7 || dYdB != 1 {
+		t.Errorf("the linear sensitivities are %v and %v, want x and 1", dYdW, dYdB)
+	}
+}
+
+func TestPredictBatchParallelKeepsTheOrder(t *testing.T) {
+	model := &NanoNeuron{w: 1.8, b: 32, activation: Sigmoid{}}
+	for _, n := range []int{0, 10, ParallelThreshold, 3*ParallelThreshold + 7} {
+		xs := make([]float64, n)
+		for i := range xs {
+			xs[i] = float64(i%200) - 100
+		}
+		want := model.PredictBatch(xs)
+		for _, workers := range []int{0, 1, 3, 8} {
+			if got := model.PredictBatchParallel(xs, workers); !slices.Equal(got, want) {
+				t.Errorf("%d inputs on %d workers differ from the sequential predictions", n, workers)
+			}
+		}
+	}
+}
+
+func benchmarkPredictBatch(b *testing.B, predict func(model *NanoNeuron, xs []float64) []float64) {
+	model := &NanoNeuron{w: 1.8, b: 32, activation: Sigmoid{}}
+	xs := make([]float64, 1<<20)
+	for i := range xs {
+		xs[i] = float64(i%200) - 100
+	}
+	b.ResetTimer()
+	for range b.N {
+		predict(model, xs)
+	}
+}
+
+func BenchmarkPredictBatch(b *testing.B) {
+	benchmarkPredictBatch(b, (*NanoNeuron).PredictBatch)
+}
+
+func BenchmarkPredictBatchParallel(b *testing.B) {
+	benchmarkPredictBatch(b, func(model *NanoNeuron, xs []float64) []float64 {
+		return model.PredictBatchParallel(xs, runtime.GOMAXPROCS(0))
+	})
+}
+
+func TestPredictClamped(t *testing.T) {
+	model := &NanoNeuron{w: 1.8, b: 32}
+	for _, test := range []struct{ x, want float64 }{
+		{-300, -459.67},
+		{20, 68},
+		{1000, 1000},
+	} {
+		if got := model.PredictClamped(test.x, -459.67, 1000); got != test.want {
+			t.Errorf("PredictClamped(%v) = %v, want %v", test.x, got, test.want)
+		}
+	}
+}
+
+func TestPredictWithConfidenceDecaysOutsideTheTrainingRange(t *testing.T) {
+	model := &NanoNeuron{w: 1.8, b: 32}
+	trainX, _ := generateDataSets(0, 0, 0, nil)
+	for _, x := range []float64{0, 20, 50, 99} {
+		prediction, confidence := model.PredictWithConfidence(x, trainX)
+		if prediction != model.predict(x) || confidence < 0.99 {
+			t.Errorf("in range %v: got %v with confidence %v, want %v with high confidence", x, prediction, confidence, model.predict(x))
+		}
+	}
+	_, near := model.PredictWithConfidence(150, trainX)
+	_, far := model.PredictWithConfidence(1000, trainX)
+	_, below := model.PredictWithConfidence(-1000, trainX)
+	if !(far < near && near < 1) || far > 0.01 || below > 0.01 {
+		t.Errorf("out of range confidences near %v, far %v and far below %v, want decaying toward 0", near, far, below)
+	}
+	if _, confidence := model.PredictWithConfidence(20, nil); confidence != 0 {
+		t.Errorf("without training inputs the confidence is %v, want 0", confidence)
+	}
+}
+
+func TestPredictSafeRejectsNonFiniteValues(t *testing.T) {
+	model := &NanoNeuron{w: 1.8, b: 32}
+	if got, err := model.PredictSafe(100); err != nil || got != 212 {
+		t.Errorf("PredictSafe(100) = %v, %v", got, err)
+	}
+	for _, x := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if _, err := model.PredictSafe(x); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("PredictSafe(%v): got %v, want ErrInvalidInput", x, err)
+		}
+	}
+	for _, broken := range []*NanoNeuron{{w: math.NaN(), b: 32}, {w: 1.8, b: math.Inf(1)}} {
+		if _, err := broken.PredictSafe(1); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("%v: got %v, want ErrInvalidInput", broken, err)
+		}
+	}
+}
+
+func TestPredictSafeRejectsOverflowingPredictions(t *testing.T) {
+	model := &NanoNeuron{w: math.MaxFloat64, b: 32}
+	if got, err := model.PredictSafe(10); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("PredictSafe(10) = %v, %v, want ErrInvalidInput", got, err)
+	}
+}
+
+func TestPredictInverseOfTheTrainedModel(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	model := NewNanoNeuron(NewRandSource(deterministicSeed))
+	trainModel(model, 70000, 0.0005, x, y, TrainOptions{})
+	c, err := model.PredictInverse(158)
+	if err != nil {
+		t.Fatalf("PredictInverse: %v", err)
+	}
+	if math.Abs(c-70) > 0.01 {
+		t.Errorf("158 °F is %v °C, want about 70", c)
+	}
+	for _, model := range []*NanoNeuron{{w: 0, b: 32}, {w: 1, activation: Sigmoid{}}, {w: 1, output: &affine{}}} {
+		if _, err := model.PredictInverse(158); !errors.Is(err, ErrNotInvertible) {
+			t.Errorf("%v: got %v, want ErrNotInvertible", model, err)
+		}
+	}
+}
+
+func TestPredictIntervalIsSymmetricAndWidensWithZ(t *testing.T) {
+	model := &NanoNeuron{w: 1.8, b: 32}
+	const x, std = 20, 2.5
+	prediction := model.predict(x)
+	previous := 0.0
+	for _, z := range []float64{0, 1, 1.96, 3} {
+		lo, hi := model.PredictInterval(x, std, z)
+		if math.Abs((prediction-lo)-(hi-prediction)) > 1e-12 {
+			t.Errorf("z=%v: [%v, %v] isn't centered on %v", z, lo, hi, prediction)
+		}
+		if width := hi - lo; math.Abs(width-2*z*std) > 1e-12 || (z > 0 && width <= previous) {
+			t.Errorf("z=%v: the interval is %v wide, was %v", z, width, previous)
+		} else {
+			previous = width
+		}
+	}
+}
+
+func TestParallelThresholdPicksThePath(t *testing.T) {
+	x, y := generateDataSets(0, 1, 0, NewRandSource(1))
+	model := &NanoNeuron{w: 1.7, b: 30}
+	defer func(threshold int) { ParallelThreshold = threshold }(ParallelThreshold)
+	for _, test := range []struct {
+		threshold int
+		parallel  bool
+	}{
+		{len(x) + 1, false},
+		{len(x), true},
+	} {
+		ParallelThreshold = test.threshold
+		before := parallelChunks.Load()
+		predictions, cost := ForwardPropagation(model, x, y, 4)
+		if parallel := parallelChunks.Load() > before; parallel != test.parallel {
+			t.Errorf("threshold %d for %d examples: parallel is %v", test.threshold, len(x), parallel)
+		}
+		wantPredictions, wantCost := forwardPropagation(model, x, y)
+		if cost != wantCost || !slices.Equal(predictions, wantPredictions) {
+			t.Errorf("threshold %d: the cost %v differs from the sequential %v", test.threshold, cost, wantCost)
+		}
+	}
+}
+
+func TestInputGradientMatchesFiniteDifferences(t *testing.T) {
+	const h = 1e-6
+	for name, model := range map[string]*NanoNeuron{
+		"linear":  {w: 1.8, b: 32},
+		"sigmoid": {w: 0.5, b: -2, activation: Sigmoid{}},
+		"output":  {w: 0.5, b: -2, activation: Sigmoid{}, output: &affine{scale: 100, offset: 10}},
+	} {
+		for _, example := range [][2]float64{{-10, 0}, {0, 50}, {4, 1}, {25, 100}} {
+			x, y := example[0], example[1]
+			numeric := (predictionCost(y, model.predict(x+h)) - predictionCost(y, model.predict(x-h))) / (2 * h)
+			if got := model.InputGradient(x, y); math.Abs(got-numeric) > 1e-5*math.Max(1, math.Abs(numeric)) {
+				t.Errorf("%s at (%v, %v): gradient %v, finite differences %v", name, x, y, got, numeric)
+			}
+		}
+	}
+}
+
+func TestPredictWithAppliesTheActivationForOneCall(t *testing.T) {
+	model := &NanoNeuron{w: 1.8, b: 32}
+	for _, x := range []float64{-40, -17.8, 0, 37} {
+		linear := model.PredictWith(x, nil)
+		if linear != model.predict(x) {
+			t.Errorf("x=%v: without an activation predicted %v, want the linear %v", x, linear, model.predict(x))
+		}
+		sigmoid := model.PredictWith(x, Sigmoid{})
+		if want := 1 / (1 + math.Exp(-linear)); math.Abs(sigmoid-want) > 1e-12 {
+			t.Errorf("x=%v: with the sigmoid predicted %v, want %v", x, sigmoid, want)
+		}
+	}
+	if model.activation != nil {
+		t.Errorf("the model kept the activation %v", model.activation)
+	}
+	withOwn := &NanoNeuron{w: 1, b: -5, activation: LeakyReLU(0)}
+	if got := withOwn.PredictWith(2, nil); got != -3 {
+		t.Errorf("replacing the ReLU predicted %v, want -3", got)
+	}
+}
+
+func TestPredictWithStateReportsTheDeadReLURegion(t *testing.T) {
+	model := &NanoNeuron{w: 2, b: -10, activation: LeakyReLU(0)}
+	for _, c := range []struct {
+		x      float64
+		y      float64
+		active bool
+	}{
+		{x: -3, y: 0, active: false},
+		{x: 4, y: 0, active: false},
+		{x: 5, y: 0, active: true},
+		{x: 8, y: 6, active: true},
+	} {
+		if y, active := model.PredictWithState(c.x); y != c.y || active != c.active {
+			t.Errorf("x=%v: got (%v, %v), want (%v, %v)", c.x, y, active, c.y, c.active)
+		}
+	}
+	if _, active := (&NanoNeuron{w: 1.8, b: 32}).PredictWithState(-1000); !active {
+		t.Error("the linear model is inactive")
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+)
+
+// RandSource is the only thing NanoNeuron needs from a random number generator:
+// uniformly distributed numbers in [0, 1). Any generator can be plugged in
+// (math/rand, crypto/rand based, a fixed sequence for examples, ...).
+type RandSource interface {
+	Float64() float64
+}
+
+// NewRandSource returns the default RandSource: a math/rand generator seeded with 'seed'.
+func NewRandSource(seed int64) RandSource {
+	return rand.New(rand.NewSource(seed))
+}
+
+// NewNanoNeuron creates a model with 'w' and 'b' randomly set up from 'rng'.
+func NewNanoNeuron(rng RandSource) *NanoNeuron {
+	n := &NanoNeuron{}
+	n.Reset(rng)
+	return n
+}
+
+// Reset randomly sets up 'w' and 'b' again from 'rng', exactly like NewNanoNeuron does, so
+// a model can be reused for repeated experiments. The activation and the output transform
+// are kept. With a generator seeded the same way the model starts from the same parameters.
+func (n *NanoNeuron) Reset(rng RandSource) {
+	n.w = rng.Float64()
+	n.b = rng.Float64()
+}
+
+// normFloat64 draws a standard normally distributed number from 'rng' (Box-Muller transform).
+func normFloat64(rng RandSource) float64 {
+	// 1 - Float64 is in (0, 1], so the logarithm stays finite.
+	u1, u2 := 1-rng.Float64(), rng.Float64()
+	return math.Sqrt(-2*math.Log(u1)) * math.Cos(2*math.Pi*u2)
+}
+
+// perm is a random permutation of the integers [0, n) drawn from 'rng' (Fisher-Yates shuffle).
+func perm(n int, rng RandSource) []int {
+	p := make([]int, n)
+	for i := range p {
+		p[i] = i
+	}
+	for i := n - 1; i > 0; i-- {
+		j := min(int(rng.Float64()*float64(i+1)), i)
+		p[i], p[j] = p[j], p[i]
+	}
+	return p
+}
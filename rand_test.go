@@ -0,0 +1,79 @@
+package main
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+// sequence is a fake RandSource returning its numbers in turn, over and over.
+type sequence struct {
+	values []float64
+	next   int
+}
+
+func (s *sequence) Float64() float64 {
+	v := s.values[s.next%len(s.values)]
+	s.next++
+	return v
+}
+
+func TestNewNanoNeuronFromAFixedSequence(t *testing.T) {
+	model := NewNanoNeuron(&sequence{values: []float64{0.25, 0.75}})
+	if model.w != 0.25 || model.b != 0.75 {
+		t.Errorf("got w=%v b=%v, want w=0.25 b=0.75", model.w, model.b)
+	}
+}
+
+func TestResetFromAFixedSequence(t *testing.T) {
+	model := &NanoNeuron{w: 1.8, b: 32, activation: Sigmoid{}}
+	model.Reset(&sequence{values: []float64{0.5, 0.125}})
+	if model.w != 0.5 || model.b != 0.125 {
+		t.Errorf("after Reset got w=%v b=%v, want w=0.5 b=0.125", model.w, model.b)
+	}
+	if model.activation != (Sigmoid{}) {
+		t.Errorf("Reset replaced the activation with %v", model.activation)
+	}
+}
+
+func TestGenerateDataSetsFromAFixedSequence(t *testing.T) {
+	// 1 - 0.75 and 0.5 make the Box-Muller transform sqrt(-2 ln 0.25) * cos(π).
+	_, y := generateDataSets(0, 2, 0, &sequence{values: []float64{0.75, 0.5}})
+	noise := -math.Sqrt(-2 * math.Log(0.25))
+	for i, label := range y {
+		if want := celsiusToFahrenheit(float64(i)) + 2*noise; math.Abs(label-want) > 1e-12 {
+			t.Fatalf("label %d is %v, want %v", i, label, want)
+		}
+	}
+}
+
+func TestResetThenRetrainReproducesTheTraining(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	model := NewNanoNeuron(NewRandSource(3))
+	model.activation = LeakyReLU(0.1)
+	trainModel(model, 2000, 0.0005, x, y, TrainOptions{})
+	trained := *model
+
+	model.Reset(NewRandSource(3))
+	if model.activation == nil {
+		t.Fatal("Reset dropped the activation")
+	}
+	trainModel(model, 2000, 0.0005, x, y, TrainOptions{})
+	if model.w != trained.w || model.b != trained.b {
+		t.Errorf("retrained to %v, the first training got %v", model, &trained)
+	}
+}
+
+func TestPerm(t *testing.T) {
+	// Float64 always returning 0 swaps every element with the first one.
+	if got, want := perm(4, &sequence{values: []float64{0}}), []int{1, 2, 3, 0}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	p := perm(100, NewRandSource(1))
+	sorted := slices.Sorted(slices.Values(p))
+	for i, v := range sorted {
+		if v != i {
+			t.Fatalf("%v isn't a permutation of [0, 100)", p)
+		}
+	}
+}
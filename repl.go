@@ -0,0 +1,32 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// repl lets us ask the trained NanoNeuron questions interactively.
+// Every line read from 'r' is a temperature in Celsius; the model prediction and the correct
+// value in the 'units' are written to 'w'. Invalid lines are reported and the loop goes on.
+// Blank lines are ignored, and the loop ends at the end of the input.
+func repl(model *NanoNeuron, units demoUnits, r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	fmt.Fprint(w, "°C> ")
+	for scanner.Scan() {
+		text := strings.TrimSpace(scanner.Text())
+		if text != "" {
+			c, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				fmt.Fprintf(w, "invalid temperature %q: please type a number\n", text)
+			} else {
+				fmt.Fprintf(w, "NanoNeuron thinks: %v %s, correct answer: %v %s\n", model.predict(c), units.symbol, units.label(c), units.symbol)
+			}
+		}
+		fmt.Fprint(w, "°C> ")
+	}
+	fmt.Fprintln(w)
+	return scanner.Err()
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReplAnswersAndSkipsInvalidLines(t *testing.T) {
+	model := &NanoNeuron{w: 2, b: 30}
+	var out strings.Builder
+	units, _ := selectUnits("fahrenheit")
+	if err := repl(model, units, strings.NewReader("100\n\n  warm \n-40\n"), &out); err != nil {
+		t.Fatalf("repl: %v", err)
+	}
+	want := "°C> NanoNeuron thinks: 230 °F, correct answer: 212 °F\n" +
+		"°C> °C> invalid temperature \"warm\": please type a number\n" +
+		"°C> NanoNeuron thinks: -50 °F, correct answer: -40 °F\n" +
+		"°C> \n"
+	if got := out.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
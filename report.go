@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// FinalCost is the cost of the last epoch of the training (NaN when no epoch was run).
+func (r *TrainingResult) FinalCost() float64 {
+	if len(r.CostHistory) == 0 {
+		return math.NaN()
+	}
+	return r.CostHistory[len(r.CostHistory)-1]
+}
+
+// BestEpoch finds the epoch with the lowest cost in the history, which with a noisy training
+// is not necessarily the last one. The first epoch wins a tie and NaN costs are ignored.
+// It returns -1 and NaN when the history is empty or all NaN.
+func (r *TrainingResult) BestEpoch() (epoch int, cost float64) {
+	epoch, cost = -1, math.NaN()
+	for i, c := range r.CostHistory {
+		if !math.IsNaN(c) && (epoch < 0 || c < cost) {
+			epoch, cost = i, c
+		}
+	}
+	return epoch, cost
+}
+
+// status describes in a few words how the training ended. The flags of the result come first:
+// AdaptiveRecovery may give up while the last cost is still finite.
+func (r *TrainingResult) status() string {
+	switch {
+	case r.Diverged:
+		return "diverged"
+	case r.PerfectFit:
+		return "perfect fit"
+	case !isFinite(r.FinalCost()):
+		return "diverged"
+	case r.Stalled:
+		return "stalled"
+	case r.Converged:
+		return "converged"
+	}
+	return "finished all epochs"
+}
+
+// Summary is a human-readable training report: initial and final cost, the improvement factor
+// (initial / final cost), how many epochs were run and how the training ended.
+func (r *TrainingResult) Summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Initial cost:      %g\n", r.InitialCost)
+	fmt.Fprintf(&b, "Final cost:        %g\n", r.FinalCost())
+	fmt.Fprintf(&b, "Improvement:       %gx\n", r.InitialCost/r.FinalCost())
+	fmt.Fprintf(&b, "Epochs run:        %d\n", len(r.CostHistory))
+	fmt.Fprintf(&b, "Status:            %s\n", r.status())
+	return b.String()
+}
+
+// Markdown is the training report for pasting into issues or docs: the learned parameters,
+// the final metrics and a sparkline of the cost curve.
+func (r *TrainingResult) Markdown() string {
+	var b strings.Builder
+	b.WriteString("# Training report\n\n")
+	if r.Final != nil {
+		b.WriteString("## Parameters\n\n")
+		b.WriteString("| Parameter | Value |\n|---|---|\n")
+		fmt.Fprintf(&b, "| w | %g |\n", r.Final.w)
+		fmt.Fprintf(&b, "| b | %g |\n\n", r.Final.b)
+	}
+	bestEpoch, bestCost := r.BestEpoch()
+	b.WriteString("## Metrics\n\n")
+	b.WriteString("| Metric | Value |\n|---|---|\n")
+	fmt.Fprintf(&b, "| Initial cost | %g |\n", r.InitialCost)
+	fmt.Fprintf(&b, "| Final cost | %g |\n", r.FinalCost())
+	fmt.Fprintf(&b, "| Best cost | %g (epoch %d) |\n", bestCost, bestEpoch)
+	fmt.Fprintf(&b, "| Epochs run | %d |\n", len(r.CostHistory))
+	fmt.Fprintf(&b, "| Status | %s |\n\n", r.status())
+	b.WriteString("## Cost curve\n\n")
+	b.WriteString("```\n" + sparkline(r.CostHistory, sparklineWidth) + "\n```\n")
+	return b.String()
+}
+
+// The number of characters of the Markdown cost sparkline.
+const sparklineWidth = 60
+
+// sparklineLevels are the ASCII characters of the sparkline from the lowest to the highest cost.
+const sparklineLevels = "_.-~=+*#"
+
+// sparkline draws the history with one character per (averaged) group of epochs, at most 'width'.
+// The costs are drawn on a logarithmic scale and the groups without a positive finite cost as spaces.
+func sparkline(history []float64, width int) string {
+	columns := min(len(history), width)
+	values := make([]float64, columns)
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for c := range values {
+		group := history[c*len(history)/columns : (c+1)*len(history)/columns]
+		sum, n := 0.0, 0
+		for _, cost := range group {
+			if cost > 0 && isFinite(cost) {
+				sum += cost
+				n++
+			}
+		}
+		values[c] = math.NaN()
+		if n > 0 {
+			values[c] = math.Log10(sum / float64(n))
+			lo, hi = math.Min(lo, values[c]), math.Max(hi, values[c])
+		}
+	}
+	var b strings.Builder
+	for _, v := range values {
+		switch {
+		case math.IsNaN(v):
+			b.WriteByte(' ')
+		case hi == lo:
+			b.WriteByte(sparklineLevels[0])
+		default:
+			level := int((v - lo) / (hi - lo) * float64(len(sparklineLevels)-1))
+			b.WriteByte(sparklineLevels[level])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestSummaryOfAKnownRun(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	model := NewNanoNeuron(NewRandSource(deterministicSeed))
+	result := trainModel(model, 1000, 0.0005, x, y, TrainOptions{})
+	summary := result.Summary()
+	for _, want := range []string{
+		fmt.Sprintf("Initial cost:      %g\n", result.InitialCost),
+		fmt.Sprintf("Final cost:        %g\n", result.FinalCost()),
+		fmt.Sprintf("Improvement:       %gx\n", result.InitialCost/result.FinalCost()),
+		"Epochs run:        1000\n",
+		"Status:            finished all epochs\n",
+	} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("the summary\n%s\nis missing %q", summary, want)
+		}
+	}
+}
+
+func TestStatusPrefersTheResultFlags(t *testing.T) {
+	for _, test := range []struct {
+		result *TrainingResult
+		want   string
+	}{
+		{&TrainingResult{CostHistory: []float64{5, 3}, Diverged: true}, "diverged"},
+		{&TrainingResult{CostHistory: []float64{5, 0}, PerfectFit: true, Converged: true}, "perfect fit"},
+		{&TrainingResult{CostHistory: []float64{5, math.Inf(1)}}, "diverged"},
+		{&TrainingResult{CostHistory: []float64{5, 3}, Stalled: true}, "stalled"},
+		{&TrainingResult{CostHistory: []float64{5, 3}, Converged: true}, "converged"},
+		{&TrainingResult{CostHistory: []float64{5, 3}}, "finished all epochs"},
+	} {
+		if got := test.result.status(); got != test.want {
+			t.Errorf("%+v: status %q, want %q", test.result, got, test.want)
+		}
+	}
+}
+
+func TestBestEpoch(t *testing.T) {
+	tests := []struct {
+		history []float64
+		epoch   int
+		cost    float64
+	}{
+		{[]float64{9, 5, 2, 3, 2, 4}, 2, 2},
+		{[]float64{math.NaN(), 7, math.NaN(), 8}, 1, 7},
+		{[]float64{math.NaN()}, -1, math.NaN()},
+		{nil, -1, math.NaN()},
+	}
+	for _, test := range tests {
+		epoch, cost := (&TrainingResult{CostHistory: test.history}).BestEpoch()
+		if epoch != test.epoch || !(cost == test.cost || math.IsNaN(cost) && math.IsNaN(test.cost)) {
+			t.Errorf("BestEpoch of %v = %d, %v, want %d, %v", test.history, epoch, cost, test.epoch, test.cost)
+		}
+	}
+}
+
+func TestMarkdownReport(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	model := NewNanoNeuron(NewRandSource(deterministicSeed))
+	result := trainModel(model, 1000, 0.0005, x, y, TrainOptions{})
+	markdown := result.Markdown()
+	for _, want := range []string{
+		"# Training report\n",
+		"## Parameters\n",
+		fmt.Sprintf("| w | %g |\n", model.w),
+		"## Metrics\n",
+		fmt.Sprintf("| Final cost | %g |\n", result.FinalCost()),
+		"## Cost curve\n",
+	} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("the report\n%s\nis missing %q", markdown, want)
+		}
+	}
+	_, curve, _ := strings.Cut(markdown, "```\n")
+	curve, _, _ = strings.Cut(curve, "\n")
+	if len(curve) != sparklineWidth || curve[0] != sparklineLevels[len(sparklineLevels)-1] || curve[len(curve)-1] != sparklineLevels[0] {
+		t.Errorf("the falling cost curve is drawn as %q", curve)
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+// ImportanceSampling makes the mini-batches focus on the hard examples: instead of going
+// through the examples in order, every epoch draws them (with replacement) with a probability
+// proportional to their current absolute prediction error |y - prediction|.
+// The probabilities are recomputed every Every epochs (0 or 1 means every epoch).
+// The cost of an epoch is then measured on the drawn examples.
+type ImportanceSampling struct {
+	// Rand is the source of the draws, so runs can be reproduced.
+	Rand  RandSource
+	Every int
+}
+
+// importanceSampler draws the examples of the epochs of one training.
+type importanceSampler struct {
+	config     *ImportanceSampling
+	xTrain     []float64
+	yTrain     []float64
+	cumulative []float64 // cumulative sampling weights of the examples
+	xEpoch     []float64
+	yEpoch     []float64
+}
+
+func newImportanceSampler(config *ImportanceSampling, xTrain, yTrain []float64) *importanceSampler {
+	m := len(xTrain)
+	return &importanceSampler{
+		config:     config,
+		xTrain:     xTrain,
+		yTrain:     yTrain,
+		cumulative: make([]float64, m),
+		xEpoch:     make([]float64, m),
+		yEpoch:     make([]float64, m),
+	}
+}
+
+// sample returns the examples to train 'epoch' on.
+func (s *importanceSampler) sample(model *NanoNeuron, epoch int) (xEpoch, yEpoch []float64) {
+	every := max(s.config.Every, 1)
+	if epoch%every == 0 {
+		s.reweight(model)
+	}
+	total := s.cumulative[len(s.cumulative)-1]
+	for i := range s.xEpoch {
+		j := sort.SearchFloat64s(s.cumulative, s.config.Rand.Float64()*total)
+		// Guard against Float64 rounding the draw up to the very total.
+		j = min(j, len(s.cumulative)-1)
+		s.xEpoch[i], s.yEpoch[i] = s.xTrain[j], s.yTrain[j]
+	}
+	return s.xEpoch, s.yEpoch
+}
+
+// reweight recomputes the sampling weights from the current errors of the model.
+func (s *importanceSampler) reweight(model *NanoNeuron) {
+	total := 0.0
+	for i, x := range s.xTrain {
+		weight := math.Abs(s.yTrain[i] - model.predict(x))
+		if !isFinite(weight) {
+			weight = 0
+		}
+		total += weight
+		s.cumulative[i] = total
+	}
+	if total == 0 {
+		// A perfect (or hopeless) model: fall back to the uniform sampling.
+		for i := range s.cumulative {
+			s.cumulative[i] = float64(i + 1)
+		}
+	}
+}
+
+// EpochPermutation is the order in which the 'n' training examples go through 'epoch' when
+// shuffling with 'seed' (see TrainOptions.Shuffle). It depends on nothing but its arguments, so
+// a run with the same seed repeats exactly, while every epoch is shuffled differently.
+func EpochPermutation(seed int64, epoch, n int) []int {
+	return perm(n, NewRandSource(epochSeed(seed, epoch)))
+}
+
+// epochSeed mixes 'seed' and 'epoch' into the seed of the generator of one epoch (SplitMix64),
+// so that neighbouring epochs and neighbouring seeds get unrelated generators.
+func epochSeed(seed int64, epoch int) int64 {
+	z := uint64(seed) + uint64(epoch+1)*0x9e3779b97f4a7c15
+	z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+	z = (z ^ z>>27) * 0x94d049bb133111eb
+	return int64(z ^ z>>31)
+}
+
+// epochShuffler reorders the examples of the epochs of one training. The epochs are counted
+// from 'offset', the epochs trained before when the training continues an earlier one.
+type epochShuffler struct {
+	seed   int64
+	offset int
+	xEpoch []float64
+	yEpoch []float64
+}
+
+func newEpochShuffler(seed int64, offset, m int) *epochShuffler {
+	return &epochShuffler{seed: seed, offset: offset, xEpoch: make([]float64, m), yEpoch: make([]float64, m)}
+}
+
+// shuffle returns the examples 'x' and 'y' in the order of 'epoch' (of this training).
+func (s *epochShuffler) shuffle(x, y []float64, epoch int) (xEpoch, yEpoch []float64) {
+	for i, j := range EpochPermutation(s.seed, s.offset+epoch, len(x)) {
+		s.xEpoch[i], s.yEpoch[i] = x[j], y[j]
+	}
+	return s.xEpoch, s.yEpoch
+}
+
+// inputJitter adds Gaussian noise to the inputs of an epoch (see TrainOptions.JitterStd).
+type inputJitter struct {
+	std    float64
+	rng    RandSource
+	xEpoch []float64
+}
+
+func newInputJitter(std float64, rng RandSource, m int) *inputJitter {
+	return &inputJitter{std: std, rng: rng, xEpoch: make([]float64, m)}
+}
+
+// apply returns a noisy copy of the inputs 'x', the inputs themselves are left alone.
+func (j *inputJitter) apply(x []float64) []float64 {
+	for i, v := range x {
+		j.xEpoch[i] = v + normFloat64(j.rng)*j.std
+	}
+	return j.xEpoch[:len(x)]
+}
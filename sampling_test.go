@@ -0,0 +1,101 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestImportanceSamplingPrefersHighErrors(t *testing.T) {
+	// The model fits the first example perfectly, the errors of the others are 1, 2 and 7.
+	model := &NanoNeuron{w: 1}
+	x := []float64{0, 1, 2, 3}
+	y := []float64{0, 2, 4, 10}
+	sampler := newImportanceSampler(&ImportanceSampling{Rand: NewRandSource(1), Every: 10}, x, y)
+	counts := make(map[float64]int)
+	const epochs = 2500
+	for epoch := range epochs {
+		xs, _ := sampler.sample(model, epoch)
+		for _, x := range xs {
+			counts[x]++
+		}
+	}
+	if counts[0] != 0 {
+		t.Errorf("the perfectly fit example was drawn %d times", counts[0])
+	}
+	if !(counts[1] < counts[2] && counts[2] < counts[3]) {
+		t.Errorf("the examples of errors 1, 2 and 7 were drawn %d, %d and %d times", counts[1], counts[2], counts[3])
+	}
+	// 7 out of 10 draws are expected for the worst example.
+	if share := float64(counts[3]) / (epochs * 4); share < 0.68 || share > 0.72 {
+		t.Errorf("the worst example got %.3f of the draws, want about 0.7", share)
+	}
+}
+
+func TestEpochPermutationIsReproducibleButDiffersByEpoch(t *testing.T) {
+	const seed, epochs, n = 7, 5, 20
+	for epoch := range epochs {
+		p := EpochPermutation(seed, epoch, n)
+		if again := EpochPermutation(seed, epoch, n); !slices.Equal(p, again) {
+			t.Errorf("epoch %d: %v, then %v with the same seed", epoch, p, again)
+		}
+		for i, v := range slices.Sorted(slices.Values(p)) {
+			if v != i {
+				t.Fatalf("epoch %d: %v isn't a permutation of [0, %d)", epoch, p, n)
+			}
+		}
+		for earlier := range epoch {
+			if slices.Equal(p, EpochPermutation(seed, earlier, n)) {
+				t.Errorf("epochs %d and %d are shuffled the same: %v", earlier, epoch, p)
+			}
+		}
+	}
+
+	x, y := generateDataSets(0, 0, 0, nil)
+	train := func(seed int64) *NanoNeuron {
+		model := &NanoNeuron{w: 0.5, b: 0.5}
+		trainModel(model, 50, 0.0005, x, y, TrainOptions{BatchSize: 10, Shuffle: true, ShuffleSeed: seed})
+		return model
+	}
+	first, second, other := train(seed), train(seed), train(seed+1)
+	if *first != *second {
+		t.Errorf("two runs with the same seed trained %v and %v", first, second)
+	}
+	if *first == *other {
+		t.Error("a different seed trained the same model, the shuffling has no effect")
+	}
+}
+
+func TestInputJitter(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	xTest, yTest := generateDataSets(0.5, 0, 0, nil)
+	train := func(opts TrainOptions) (*NanoNeuron, *TrainingResult) {
+		model := NewNanoNeuron(NewRandSource(1))
+		result, err := Train(model, x, y, 70000, 0.0005, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return model, result
+	}
+
+	baseline, baselineResult := train(TrainOptions{})
+	if model, result := train(TrainOptions{JitterRand: NewRandSource(2)}); *model != *baseline || !slices.Equal(result.CostHistory, baselineResult.CostHistory) {
+		t.Errorf("no jitter trained %v, want %v", model, baseline)
+	}
+
+	jittered, result := train(TrainOptions{JitterStd: 0.5, JitterRand: NewRandSource(2)})
+	if !(result.FinalCost() > baselineResult.FinalCost()) {
+		t.Errorf("the training cost with jitter %v isn't above %v", result.FinalCost(), baselineResult.FinalCost())
+	}
+	baselineTest := Evaluate(baseline, xTest, yTest).RMSE
+	if rmse := Evaluate(jittered, xTest, yTest).RMSE; rmse > baselineTest+0.1 {
+		t.Errorf("the test RMSE with jitter is %v, without %v", rmse, baselineTest)
+	}
+	again, _ := train(TrainOptions{JitterStd: 0.5, JitterRand: NewRandSource(2)})
+	if *again != *jittered {
+		t.Errorf("the same seed trained %v and %v", again, jittered)
+	}
+
+	if _, err := Train(NewNanoNeuron(NewRandSource(1)), x, y, 10, 0.0005, TrainOptions{JitterStd: 0.5}); err == nil {
+		t.Error("jitter without a random source was accepted")
+	}
+}
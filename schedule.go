@@ -0,0 +1,166 @@
+package main
+
+import "math"
+
+// LearningRateSchedule decides which learning rate 'alpha' the teacher uses at every epoch.
+// Instead of pushing our NanoNeuron equally hard during the whole training
+// we can push harder at some moments and softer at others.
+type LearningRateSchedule interface {
+	Rate(epoch int) float64
+}
+
+// Cyclical is the triangular cyclical learning rate policy.
+// The rate linearly ramps up from BaseLR to MaxLR during StepSize epochs and then
+// linearly ramps back down to BaseLR during the next StepSize epochs, over and over again.
+// The periodic bigger steps help to jump out of shallow minima.
+type Cyclical struct {
+	BaseLR   float64
+	MaxLR    float64
+	StepSize int // half-cycle length in epochs
+}
+
+// Rate implements LearningRateSchedule.
+func (c Cyclical) Rate(epoch int) float64 {
+	if c.StepSize <= 0 {
+		return c.BaseLR
+	}
+	cycle := math.Floor(1 + float64(epoch)/float64(2*c.StepSize))
+	x := math.Abs(float64(epoch)/float64(c.StepSize) - 2*cycle + 1)
+	return c.BaseLR + (c.MaxLR-c.BaseLR)*math.Max(0, 1-x)
+}
+
+// CosineAnnealing decays the rate from MaxLR to MinLR along a half cosine over Epochs epochs,
+// which starts and ends the decay gently. With Restart > 0 the rate jumps back to MaxLR every
+// Restart epochs (warm restarts) and every cycle decays over Restart epochs instead.
+// After a decay without restarts the rate stays at MinLR.
+type CosineAnnealing struct {
+	MaxLR   float64
+	MinLR   float64
+	Epochs  int
+	Restart int
+}
+
+// Rate implements LearningRateSchedule.
+func (c CosineAnnealing) Rate(epoch int) float64 {
+	period, t := c.Epochs, epoch
+	if c.Restart > 0 {
+		period, t = c.Restart, epoch%c.Restart
+	}
+	if period <= 0 || t >= period {
+		return c.MinLR
+	}
+	return c.MinLR + (c.MaxLR-c.MinLR)*(1+math.Cos(math.Pi*float64(t)/float64(period)))/2
+}
+
+// WarmRestarts is the stochastic gradient descent with warm restarts (SGDR): the rate decays
+// from MaxLR to MinLR along a half cosine over a cycle and then restarts at MaxLR. The first
+// cycle lasts Period epochs and every next one Multiplier times as long as the one before
+// (rounded, 1 or less keeps them all Period long, like CosineAnnealing with Restart).
+// With TrainOptions.SnapshotRestarts the training keeps the model at the end of every cycle,
+// where the rate is the lowest, for a snapshot ensemble.
+type WarmRestarts struct {
+	MaxLR      float64
+	MinLR      float64
+	Period     int
+	Multiplier float64
+}
+
+// cycle locates 'epoch' in its cycle: 't' epochs after the restart of a 'period' epochs long cycle.
+func (w WarmRestarts) cycle(epoch int) (t, period int) {
+	t, period = epoch, w.Period
+	for t >= period {
+		t -= period
+		if w.Multiplier > 1 {
+			period = int(math.Round(float64(period) * w.Multiplier))
+		}
+	}
+	return t, period
+}
+
+// Rate implements LearningRateSchedule.
+func (w WarmRestarts) Rate(epoch int) float64 {
+	if w.Period <= 0 {
+		return w.MinLR
+	}
+	t, period := w.cycle(epoch)
+	return w.MinLR + (w.MaxLR-w.MinLR)*(1+math.Cos(math.Pi*float64(t)/float64(period)))/2
+}
+
+// endsCycle tells if 'epoch' is the last epoch of a cycle, the next one restarts.
+func (w WarmRestarts) endsCycle(epoch int) bool {
+	if w.Period <= 0 {
+		return false
+	}
+	t, period := w.cycle(epoch)
+	return t == period-1
+}
+
+// cycleEnder is implemented by the schedules with warm restarts, see TrainOptions.SnapshotRestarts.
+type cycleEnder interface {
+	endsCycle(epoch int) bool
+}
+
+// ConstantRate is the simplest schedule: the same learning rate at every epoch.
+type ConstantRate float64
+
+// Rate implements LearningRateSchedule.
+func (c ConstantRate) Rate(int) float64 { return float64(c) }
+
+// CostAwareSchedule is a LearningRateSchedule that adapts to the training progress.
+// The training loop reports the cost of every epoch to it right after measuring it.
+type CostAwareSchedule interface {
+	LearningRateSchedule
+	ObserveCost(epoch int, cost float64)
+}
+
+// ReduceOnPlateau wraps another schedule and cuts its rate by Factor every time the cost
+// hasn't improved for Patience epochs. After a cut it waits Cooldown epochs before it starts
+// counting again, to give the smaller steps a chance to make progress.
+// A struct literal works as well as NewReduceOnPlateau, it starts with the full rate.
+type ReduceOnPlateau struct {
+	Schedule LearningRateSchedule
+	Factor   float64
+	Patience int
+	Cooldown int
+
+	cuts     int // the rate is scaled by Factor^cuts
+	best     float64
+	wait     int
+	cooldown int
+	started  bool
+}
+
+// NewReduceOnPlateau creates a ReduceOnPlateau around 'schedule'.
+func NewReduceOnPlateau(schedule LearningRateSchedule, factor float64, patience, cooldown int) *ReduceOnPlateau {
+	return &ReduceOnPlateau{
+		Schedule: schedule,
+		Factor:   factor,
+		Patience: patience,
+		Cooldown: cooldown,
+	}
+}
+
+// Rate implements LearningRateSchedule.
+func (r *ReduceOnPlateau) Rate(epoch int) float64 {
+	return r.Schedule.Rate(epoch) * math.Pow(r.Factor, float64(r.cuts))
+}
+
+// ObserveCost implements CostAwareSchedule.
+func (r *ReduceOnPlateau) ObserveCost(epoch int, cost float64) {
+	if !r.started || cost < r.best {
+		r.best = cost
+		r.wait = 0
+		r.started = true
+		return
+	}
+	if r.cooldown > 0 {
+		r.cooldown--
+		return
+	}
+	r.wait++
+	if r.wait >= r.Patience {
+		r.cuts++
+		r.wait = 0
+		r.cooldown = r.Cooldown
+	}
+}
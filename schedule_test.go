@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestCyclicalIsATriangleWave(t *testing.T) {
+	c := Cyclical{BaseLR: 0.001, MaxLR: 0.005, StepSize: 4}
+	// Up in 4 epochs, down in 4 more, twice.
+	want := []float64{0.001, 0.002, 0.003, 0.004, 0.005, 0.004, 0.003, 0.002,
+		0.001, 0.002, 0.003, 0.004, 0.005, 0.004, 0.003, 0.002, 0.001}
+	for epoch, rate := range want {
+		if got := c.Rate(epoch); math.Abs(got-rate) > 1e-15 {
+			t.Errorf("epoch %d: rate %v, want %v", epoch, got, rate)
+		}
+	}
+	if got := (Cyclical{BaseLR: 0.001, MaxLR: 0.005}).Rate(3); got != 0.001 {
+		t.Errorf("without a step size the rate is %v, want the base rate", got)
+	}
+}
+
+func TestReduceOnPlateauCutsTheRateAfterPatience(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		schedule *ReduceOnPlateau
+	}{
+		{"constructor", NewReduceOnPlateau(ConstantRate(1), 0.5, 3, 2)},
+		{"struct literal", &ReduceOnPlateau{Schedule: ConstantRate(1), Factor: 0.5, Patience: 3, Cooldown: 2}},
+	} {
+		r := test.schedule
+		// The cost improves for 5 epochs and then plateaus for good.
+		costs := []float64{10, 9, 8, 7, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6}
+		// 3 epochs of patience, then 2 of cooldown before 3 more of patience.
+		want := []float64{1, 1, 1, 1, 1, 1, 1, 1, 0.5, 0.5, 0.5, 0.5, 0.5, 0.25}
+		for epoch, cost := range costs {
+			if got := r.Rate(epoch); got != want[epoch] {
+				t.Errorf("%s: epoch %d: rate %v, want %v", test.name, epoch, got, want[epoch])
+			}
+			r.ObserveCost(epoch, cost)
+		}
+	}
+}
+
+func TestReduceOnPlateauKeepsTheRateWhileImproving(t *testing.T) {
+	r := NewReduceOnPlateau(ConstantRate(0.1), 0.5, 2, 0)
+	for epoch := range 20 {
+		r.ObserveCost(epoch, 1/float64(epoch+1))
+	}
+	if got := r.Rate(20); got != 0.1 {
+		t.Errorf("rate %v, want 0.1", got)
+	}
+}
+
+func TestCosineAnnealingFollowsTheCosine(t *testing.T) {
+	c := CosineAnnealing{MaxLR: 0.1, MinLR: 0.01, Epochs: 100}
+	for _, test := range []struct {
+		epoch int
+		want  float64
+	}{
+		{0, 0.1},
+		{25, 0.01 + 0.09*(1+math.Cos(math.Pi/4))/2},
+		{50, 0.055},
+		{100, 0.01},
+		{1000, 0.01},
+	} {
+		if got := c.Rate(test.epoch); math.Abs(got-test.want) > 1e-15 {
+			t.Errorf("epoch %d: rate %v, want %v", test.epoch, got, test.want)
+		}
+	}
+	for epoch := 1; epoch <= 100; epoch++ {
+		if c.Rate(epoch) > c.Rate(epoch-1) {
+			t.Fatalf("the rate grows at epoch %d", epoch)
+		}
+	}
+}
+
+func TestCosineAnnealingRestarts(t *testing.T) {
+	for _, test := range []struct {
+		schedule CosineAnnealing
+		restarts []int
+	}{
+		{CosineAnnealing{MaxLR: 0.1, MinLR: 0.01, Restart: 10}, []int{10, 20, 30, 40, 50, 60, 70}},
+		{CosineAnnealing{MaxLR: 0.1, MinLR: 0.01, Restart: 25}, []int{25, 50}},
+	} {
+		var restarts []int
+		for epoch := 1; epoch < 75; epoch++ {
+			if test.schedule.Rate(epoch) > test.schedule.Rate(epoch-1) {
+				restarts = append(restarts, epoch)
+				if rate := test.schedule.Rate(epoch); rate != test.schedule.MaxLR {
+					t.Errorf("%+v: restarted at %v, want MaxLR", test.schedule, rate)
+				}
+			}
+
+		}
+		if !slices.Equal(restarts, test.restarts) {
+			t.Errorf("%+v: restarted at %v, want %v", test.schedule, restarts, test.restarts)
+		}
+	}
+}
+
+func TestWarmRestartsSnapshotTheCycles(t *testing.T) {
+	schedule := WarmRestarts{MaxLR: 0.0005, MinLR: 0.0001, Period: 10, Multiplier: 2}
+	var restarts []int
+	for epoch := 1; epoch < 100; epoch++ {
+		if schedule.Rate(epoch) > schedule.Rate(epoch-1) {
+			restarts = append(restarts, epoch)
+			if rate := schedule.Rate(epoch); rate != schedule.MaxLR {
+				t.Errorf("restarted at %v, want MaxLR", rate)
+			}
+		}
+	}
+	// The cycles last 10, 20, 40 and 80 epochs.
+	if want := []int{10, 30, 70}; !slices.Equal(restarts, want) {
+		t.Errorf("restarted at the epochs %v, want %v", restarts, want)
+	}
+
+	x, y := generateDataSets(0, 0, 0, nil)
+	result, err := Train(NewNanoNeuron(NewRandSource(1)), x, y, 75, 0, TrainOptions{Schedule: schedule, SnapshotRestarts: true, RecordParams: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Snapshots) != 3 {
+		t.Fatalf("got %d snapshots, want 3", len(result.Snapshots))
+	}
+	for i, epoch := range []int{9, 29, 69} {
+		if got, want := result.Snapshots[i].Parameters(), result.ParamHistory[epoch]; got[0] != want[0] || got[1] != want[1] {
+			t.Errorf("snapshot %d is %v, want the parameters %v of epoch %d", i, got, want, epoch)
+		}
+	}
+
+	if _, err := Train(NewNanoNeuron(NewRandSource(1)), x, y, 10, 0.0005, TrainOptions{SnapshotRestarts: true}); !errors.Is(err, ErrInvalidHyperparameter) {
+		t.Errorf("snapshots without restarts returned %v, want ErrInvalidHyperparameter", err)
+	}
+}
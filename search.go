@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"sync"
+)
+
+// GridSearch trains one fresh NanoNeuron per learning rate in 'alphas' and returns
+// the final training cost reached with every one of them.
+// The models are trained concurrently with LearningRateSweep, so the same call always
+// produces the same results, and the alphas are checked likewise.
+func GridSearch(x, y []float64, alphas []float64, epochs int) (map[float64]float64, error) {
+	results, err := LearningRateSweep(x, y, alphas, epochs)
+	if err != nil {
+		return nil, err
+	}
+	costs := make(map[float64]float64, len(results))
+	for alpha, result := range results {
+		_, costs[alpha] = forwardPropagation(result.Final, x, y)
+	}
+	return costs, nil
+}
+
+// sweepSeed seeds the initialization of the models of a LearningRateSweep, see alphaSeed.
+const sweepSeed = 1
+
+// alphaSeed is the seed of the initialization of the model swept with 'alpha': sweepSeed mixed
+// with the bits of 'alpha' (SplitMix64, see epochSeed), so every rate gets its own draw.
+func alphaSeed(alpha float64) int64 {
+	return epochSeed(sweepSeed, int(math.Float64bits(alpha)))
+}
+
+// LearningRateSweep trains one fresh NanoNeuron per learning rate in 'alphas' and returns
+// the full training result of every one of them, to see which rates converge, which diverge
+// (their FinalCost is NaN or infinite) and how fast. The models are trained concurrently,
+// one goroutine per alpha. Every model is initialized independently, from a random source
+// seeded by its alpha (see alphaSeed), so the results don't depend on the order of 'alphas'.
+// The results are keyed by the alpha, so an alpha in the list twice or a NaN alpha (which
+// can't be looked up) is an ErrInvalidHyperparameter.
+func LearningRateSweep(x, y []float64, alphas []float64, epochs int) (map[float64]*TrainingResult, error) {
+	for i, alpha := range alphas {
+		if math.IsNaN(alpha) {
+			return nil, fmt.Errorf("learning rate sweep: %w: alpha is NaN", ErrInvalidHyperparameter)
+		}
+		if slices.Contains(alphas[:i], alpha) {
+			return nil, fmt.Errorf("learning rate sweep: %w: alpha %v is in the list twice", ErrInvalidHyperparameter, alpha)
+		}
+	}
+	results := make([]*TrainingResult, len(alphas))
+	var wg sync.WaitGroup
+	for i, alpha := range alphas {
+		wg.Add(1)
+		go func(i int, alpha float64) {
+			defer wg.Done()
+			model := NewNanoNeuron(NewRandSource(alphaSeed(alpha)))
+			results[i] = trainModel(model, epochs, alpha, x, y, TrainOptions{})
+		}(i, alpha)
+	}
+	wg.Wait()
+
+	sweep := make(map[float64]*TrainingResult, len(alphas))
+	for i, alpha := range alphas {
+		sweep[alpha] = results[i]
+	}
+	return sweep, nil
+}
+
+// BestFromSweep picks the final model of a LearningRateSweep with the lowest cost on the
+// held-out test data-set 'xTest', 'yTest' and returns it with its learning rate. Selecting on
+// data none of the models was trained on keeps the choice honest. Equal costs go to the
+// smaller alpha and diverged models (NaN or infinite cost) are never picked; without any usable model
+// the alpha is NaN and the model nil.
+func BestFromSweep(results map[float64]*TrainingResult, xTest, yTest []float64) (alpha float64, model *NanoNeuron) {
+	alpha, bestCost := math.NaN(), math.NaN()
+	for _, a := range slices.Sorted(maps.Keys(results)) {
+		_, cost := forwardPropagation(results[a].Final, xTest, yTest)
+		if isFinite(cost) && (model == nil || cost < bestCost) {
+			alpha, model, bestCost = a, results[a].Final, cost
+		}
+	}
+	return alpha, model
+}
+
+// Costs closer than this (relatively) are considered equal when comparing models.
+const costTieTolerance = 1e-12
+
+// BetterModel returns the model with the lower cost on 'x' and 'y'.
+// Ties (costs equal up to costTieTolerance) go to the simpler model, the one with the smaller
+// L2 norm of its parameters, and then to 'a', so the choice is always deterministic.
+// A model with a NaN cost (a diverged one) always loses.
+func BetterModel(a, b *NanoNeuron, x, y []float64) *NanoNeuron {
+	_, costA := forwardPropagation(a, x, y)
+	_, costB := forwardPropagation(b, x, y)
+	if math.IsNaN(costA) != math.IsNaN(costB) {
+		if math.IsNaN(costA) {
+			return b
+		}
+		return a
+	}
+	if math.Abs(costA-costB) > costTieTolerance*math.Max(math.Abs(costA), math.Abs(costB)) {
+		if costB < costA {
+			return b
+		}
+		return a
+	}
+	if math.Hypot(b.w, b.b) < math.Hypot(a.w, a.b) {
+		return b
+	}
+	return a
+}
+
+// CostLandscape samples the cost surface over the (w, b) plane for plotting the bowl shape
+// the gradient descent rolls down. The result holds steps x steps costs: row i is for
+// w = wRange[0] + i * (wRange[1] - wRange[0]) / (steps - 1) and column j is for the same
+// spacing of 'b' over bRange, so both ranges are sampled including their ends.
+func CostLandscape(x, y []float64, wRange, bRange [2]float64, steps int) [][]float64 {
+	if steps < 1 {
+		return nil
+	}
+	at := func(r [2]float64, i int) float64 {
+		if steps == 1 {
+			return r[0]
+		}
+		return r[0] + (r[1]-r[0])*float64(i)/float64(steps-1)
+	}
+	mustMatch("cost landscape", x, y)
+	grid := make([][]float64, steps)
+	for i := range grid {
+		grid[i] = make([]float64, steps)
+		w := at(wRange, i)
+		for j := range grid[i] {
+			b := at(bRange, j)
+			cost := 0.0
+			for k := range x {
+				cost += predictionCost(y[k], Predict(x[k], w, b))
+			}
+			grid[i][j] = cost / float64(len(x))
+		}
+	}
+	return grid
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestGridSearch(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	alphas := []float64{0.00001, 0.0001, 0.0003, 0.0005}
+	costs, err := GridSearch(x, y, alphas, 20000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(costs) != len(alphas) {
+		t.Fatalf("got %d costs, want %d", len(costs), len(alphas))
+	}
+	best := alphas[0]
+	for _, alpha := range alphas {
+		if costs[alpha] < costs[best] {
+			best = alpha
+		}
+	}
+	if best != 0.0005 {
+		t.Errorf("the lowest cost %v is reached with alpha %v, want the biggest stable alpha 0.0005 (costs %v)", costs[best], best, costs)
+	}
+	again, _ := GridSearch(x, y, alphas, 20000)
+	for _, alpha := range alphas {
+		if again[alpha] != costs[alpha] {
+			t.Errorf("alpha %v: cost %v, then %v", alpha, costs[alpha], again[alpha])
+		}
+	}
+}
+
+func TestBetterModelPrefersTheSimplerOfEqualCosts(t *testing.T) {
+	// Both models are off by 2 on both examples, the first one has the smaller norm.
+	x, y := []float64{-1, 3}, []float64{0, 0}
+	simpler := &NanoNeuron{w: 1, b: -1}
+	bigger := &NanoNeuron{w: 0, b: 2}
+	for _, order := range [][2]*NanoNeuron{{simpler, bigger}, {bigger, simpler}} {
+		if got := BetterModel(order[0], order[1], x, y); got != simpler {
+			t.Errorf("got %v, want the simpler %v", got, simpler)
+		}
+	}
+
+	better := &NanoNeuron{w: 0, b: 1.5}
+	if got := BetterModel(simpler, better, x, y); got != better {
+		t.Errorf("got %v, want the one of the lower cost %v", got, better)
+	}
+	diverged := &NanoNeuron{w: math.NaN()}
+	if got := BetterModel(diverged, bigger, x, y); got != bigger {
+		t.Errorf("got %v, want the diverged model to lose", got)
+	}
+}
+
+func TestCostLandscapeBottomsOutAtTheCelsiusFormula(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	wRange, bRange := [2]float64{0, 4}, [2]float64{0, 64}
+	const steps = 41
+	grid := CostLandscape(x, y, wRange, bRange, steps)
+	if len(grid) != steps || len(grid[0]) != steps {
+		t.Fatalf("got a %dx%d grid, want %dx%d", len(grid), len(grid[0]), steps, steps)
+	}
+	bestI, bestJ := 0, 0
+	for i := range grid {
+		for j := range grid[i] {
+			if grid[i][j] < grid[bestI][bestJ] {
+				bestI, bestJ = i, j
+			}
+		}
+	}
+	w := wRange[0] + (wRange[1]-wRange[0])*float64(bestI)/(steps-1)
+	b := bRange[0] + (bRange[1]-bRange[0])*float64(bestJ)/(steps-1)
+	if math.Abs(w-1.8) > 1e-9 || math.Abs(b-32) > 1e-9 {
+		t.Errorf("the lowest cost %v is at w=%v b=%v, want w=1.8 b=32", grid[bestI][bestJ], w, b)
+	}
+}
+
+func TestLearningRateSweepFlagsDivergedAlphas(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	results, err := LearningRateSweep(x, y, []float64{0.0005, 0.01, 0.0002}, 5000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3", len(results))
+	}
+	for alpha, diverged := range map[float64]bool{0.0005: false, 0.0002: false, 0.01: true} {
+		cost := results[alpha].FinalCost()
+		if isFinite(cost) == diverged {
+			t.Errorf("alpha %v: final cost %v, want diverged=%v", alpha, cost, diverged)
+		}
+	}
+}
+
+func TestLearningRateSweepDoesNotDependOnTheOrder(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	forward, _ := LearningRateSweep(x, y, []float64{0.0001, 0.0003, 0.0005}, 1000)
+	backward, _ := LearningRateSweep(x, y, []float64{0.0005, 0.0003, 0.0001}, 1000)
+	for alpha, result := range forward {
+		if *result.Final != *backward[alpha].Final {
+			t.Errorf("alpha %v: trained %v in one order and %v in the other", alpha, result.Final, backward[alpha].Final)
+		}
+	}
+}
+
+func TestLearningRateSweepRejectsDuplicateAlphas(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	for _, alphas := range [][]float64{{0.0005, 0.0001, 0.0005}, {0.0005, math.NaN()}} {
+		if _, err := LearningRateSweep(x, y, alphas, 10); !errors.Is(err, ErrInvalidHyperparameter) {
+			t.Errorf("%v: got %v, want ErrInvalidHyperparameter", alphas, err)
+		}
+	}
+}
+
+func TestLearningRateSweepInitializesEveryAlphaIndependently(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	// Without a single epoch the final models are the initializations.
+	results, err := LearningRateSweep(x, y, []float64{0.0001, 0.0002}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first, second := results[0.0001].Final, results[0.0002].Final; *first == *second {
+		t.Errorf("both alphas started from %v", first)
+	}
+}
+
+func TestBestFromSweepPicksTheLowestTestCost(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	xTest, yTest := generateDataSets(0.5, 0, 0, nil)
+	results, err := LearningRateSweep(x, y, []float64{0.0001, 0.0005, 0.0002, 0.01}, 2000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	alpha, model := BestFromSweep(results, xTest, yTest)
+	if model != results[alpha].Final {
+		t.Fatalf("alpha %v came with another model", alpha)
+	}
+	_, best := forwardPropagation(model, xTest, yTest)
+	for a, result := range results {
+		if _, cost := forwardPropagation(result.Final, xTest, yTest); cost < best {
+			t.Errorf("alpha %v has the lower test cost %v than the picked %v with %v", a, cost, alpha, best)
+		}
+	}
+	if alpha != 0.0005 {
+		t.Errorf("picked alpha %v, want 0.0005", alpha)
+	}
+
+	diverged := map[float64]*TrainingResult{0.01: results[0.01]}
+	if alpha, model := BestFromSweep(diverged, xTest, yTest); !math.IsNaN(alpha) || model != nil {
+		t.Errorf("picked the diverged alpha %v", alpha)
+	}
+}
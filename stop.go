@@ -0,0 +1,41 @@
+package main
+
+// StopCriterion decides after every epoch whether to stop the training, see TrainOptions.Stop.
+// Criteria compose with AnyOf and AllOf, i.e. "a target cost or at most 1000 epochs":
+// AnyOf(CostBelow(1e-3), MaxEpochs(1000)).
+type StopCriterion func(epoch int, state TrainState) bool
+
+// CostBelow stops as soon as the cost of an epoch is below 'target'.
+func CostBelow(target float64) StopCriterion {
+	return func(_ int, state TrainState) bool { return state.Cost < target }
+}
+
+// MaxEpochs stops after 'n' epochs.
+func MaxEpochs(n int) StopCriterion {
+	return func(epoch int, _ TrainState) bool { return epoch+1 >= n }
+}
+
+// AnyOf stops when at least one of the criteria says so (OR). Every criterion is asked every
+// epoch, so criteria counting epochs keep their state even when an earlier one already stops.
+// Without criteria it never stops.
+func AnyOf(criteria ...StopCriterion) StopCriterion {
+	return func(epoch int, state TrainState) bool {
+		stop := false
+		for _, criterion := range criteria {
+			stop = criterion(epoch, state) || stop
+		}
+		return stop
+	}
+}
+
+// AllOf stops when all the criteria say so in the same epoch (AND). Like with AnyOf every
+// criterion is asked every epoch. Without criteria it never stops.
+func AllOf(criteria ...StopCriterion) StopCriterion {
+	return func(epoch int, state TrainState) bool {
+		stop := len(criteria) > 0
+		for _, criterion := range criteria {
+			stop = criterion(epoch, state) && stop
+		}
+		return stop
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import "testing"
+
+func TestStopPredicateEndsTheTraining(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+
+	atEpoch := func(epoch int, _ TrainState) bool { return epoch == 41 }
+	result := trainModel(NewNanoNeuron(NewRandSource(1)), 70000, 0.0005, x, y, TrainOptions{Stop: atEpoch})
+	if len(result.CostHistory) != 42 || !result.Converged {
+		t.Errorf("trained %d epochs (converged=%v), want 42", len(result.CostHistory), result.Converged)
+	}
+
+	const target = 0.5
+	lowCost := func(_ int, state TrainState) bool { return state.Cost < target }
+	result = trainModel(NewNanoNeuron(NewRandSource(1)), 70000, 0.0005, x, y, TrainOptions{Stop: lowCost})
+	epochs := len(result.CostHistory)
+	if epochs == 70000 || !(result.FinalCost() < target) {
+		t.Fatalf("stopped after %d epochs at the cost %v, want below %v", epochs, result.FinalCost(), target)
+	}
+	if epochs > 1 && result.CostHistory[epochs-2] < target {
+		t.Errorf("the cost %v was already below %v one epoch earlier", result.CostHistory[epochs-2], target)
+	}
+}
+
+func TestCombinedStopCriteria(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	train := func(stop StopCriterion) int {
+		return len(trainModel(NewNanoNeuron(NewRandSource(1)), 70000, 0.0005, x, y, TrainOptions{Stop: stop}).CostHistory)
+	}
+
+	costEpochs := train(CostBelow(0.5))
+	if costEpochs == 70000 {
+		t.Fatal("the target cost wasn't reached")
+	}
+	if epochs := train(AnyOf(CostBelow(0.5), MaxEpochs(costEpochs+100))); epochs != costEpochs {
+		t.Errorf("the target cost stopped after %d epochs, want %d", epochs, costEpochs)
+	}
+	if epochs := train(AnyOf(CostBelow(0.5), MaxEpochs(10))); epochs != 10 {
+		t.Errorf("the epoch limit stopped after %d epochs, want 10", epochs)
+	}
+	if epochs := train(AllOf(CostBelow(0.5), MaxEpochs(costEpochs+100))); epochs != costEpochs+100 {
+		t.Errorf("both criteria stopped after %d epochs, want %d", epochs, costEpochs+100)
+	}
+
+	state := TrainState{Cost: 1}
+	if AnyOf()(0, state) || AllOf()(0, state) {
+		t.Error("no criteria stopped the training")
+	}
+}
+
+func TestControlStopsAndPausesTheTraining(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	control := func(epoch int, _ TrainState) Control {
+		if epoch == 41 {
+			return Stop
+		}
+		return Continue
+	}
+	result := trainModel(NewNanoNeuron(NewRandSource(1)), 70000, 0.0005, x, y, TrainOptions{Control: control})
+	if len(result.CostHistory) != 42 || !result.Converged {
+		t.Errorf("trained %d epochs (converged=%v), want 42", len(result.CostHistory), result.Converged)
+	}
+
+	// The training pauses after epoch 4 until it is resumed.
+	resume := make(chan struct{})
+	paused := make(chan struct{})
+	control = func(epoch int, _ TrainState) Control {
+		if epoch == 4 {
+			close(paused)
+			return Pause
+		}
+		return Continue
+	}
+	done := make(chan *TrainingResult)
+	go func() {
+		done <- trainModel(NewNanoNeuron(NewRandSource(1)), 10, 0.0005, x, y, TrainOptions{Control: control, Resume: resume})
+	}()
+	<-paused
+	select {
+	case <-done:
+		t.Fatal("the training didn't pause")
+	case resume <- struct{}{}:
+	}
+	if result := <-done; len(result.CostHistory) != 10 {
+		t.Errorf("the resumed training ran %d epochs, want 10", len(result.CostHistory))
+	}
+}
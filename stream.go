@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// LineError is a problem with a single line of a prediction stream.
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// StreamErrors collects all the lines of a stream that could not be predicted.
+type StreamErrors []*LineError
+
+func (e StreamErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// PredictStream reads one input value per line from 'r' and writes one prediction per line to 'w',
+// so NanoNeuron can be used in shell pipelines: cat inputs.txt | nano-neuron predict.
+// Blank lines are skipped. Lines that can't be parsed are skipped too and reported together
+// with their line numbers in a StreamErrors once the whole stream has been processed.
+// Use PredictStreamStrict to stop at the first bad line instead.
+func PredictStream(model *NanoNeuron, r io.Reader, w io.Writer) error {
+	return predictStream(model, r, w, PlainFormatter{}, false)
+}
+
+// PredictStreamStrict is like PredictStream but aborts on the first line that can't be parsed.
+func PredictStreamStrict(model *NanoNeuron, r io.Reader, w io.Writer) error {
+	return predictStream(model, r, w, PlainFormatter{}, true)
+}
+
+// PredictStreamWith is like PredictStream with every prediction written by 'formatter',
+// i.e. JSONFormatter for a JSON Lines output.
+func PredictStreamWith(model *NanoNeuron, r io.Reader, w io.Writer, formatter OutputFormatter) error {
+	return predictStream(model, r, w, formatter, false)
+}
+
+func predictStream(model *NanoNeuron, r io.Reader, w io.Writer, formatter OutputFormatter, strict bool) error {
+	var lineErrors StreamErrors
+	scanner := bufio.NewScanner(r)
+	out := bufio.NewWriter(w)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		x, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			lineErr := &LineError{Line: line, Err: err}
+			if strict {
+				out.Flush()
+				return lineErr
+			}
+			lineErrors = append(lineErrors, lineErr)
+			continue
+		}
+		if _, err := fmt.Fprintln(out, formatter.Format(x, model.predict(x))); err != nil {
+			return err
+		}
+	}
+	if err := out.Flush(); err != nil {
+		return err
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if len(lineErrors) > 0 {
+		return lineErrors
+	}
+	return nil
+}
+
+// PredictCSV reads a CSV with the input values in its first column from 'r' and writes it to 'w'
+// with a 'prediction' column appended to every row, for spreadsheet workflows.
+// All the other columns are passed through untouched. A first row that isn't numeric is
+// treated as a header and gets the 'prediction' title. A row that can't be parsed stops the
+// processing with a LineError.
+func PredictCSV(model *NanoNeuron, r io.Reader, w io.Writer) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	out := csv.NewWriter(w)
+	line := 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		line++
+		x, err := strconv.ParseFloat(strings.TrimSpace(record[0]), 64)
+		switch {
+		case err == nil:
+			record = append(record, strconv.FormatFloat(model.predict(x), 'g', -1, 64))
+		case line == 1:
+			record = append(record, "prediction")
+		default:
+			out.Flush()
+			return &LineError{Line: line, Err: err}
+		}
+		if err := out.Write(record); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// WriteResidualsCSV writes a CSV with the header 'x,y,prediction,residual' and a row for every
+// example of the data-set, the residual being y - prediction. Sorting it in a spreadsheet by the
+// residual shows where the model errs the most. Mismatched 'x' and 'y' are reported as ErrLengthMismatch.
+func WriteResidualsCSV(w io.Writer, model *NanoNeuron, x, y []float64) error {
+	if len(x) != len(y) {
+		return fmt.Errorf("residuals: %w: %d inputs but %d labels", ErrLengthMismatch, len(x), len(y))
+	}
+	out := csv.NewWriter(w)
+	if err := out.Write([]string{"x", "y", "prediction", "residual"}); err != nil {
+		return err
+	}
+	format := func(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) }
+	for i := range x {
+		prediction := model.predict(x[i])
+		if err := out.Write([]string{format(x[i]), format(y[i]), format(prediction), format(y[i] - prediction)}); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
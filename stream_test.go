@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestPredictStream(t *testing.T) {
+	model := &NanoNeuron{w: 1.8, b: 32}
+	input := "0\n\n10\nten\n  -40  \n"
+	var out bytes.Buffer
+	err := PredictStream(model, strings.NewReader(input), &out)
+	if got, want := out.String(), "32\n50\n-40\n"; got != want {
+		t.Errorf("got output %q, want %q", got, want)
+	}
+	var lineErrors StreamErrors
+	if !errors.As(err, &lineErrors) || len(lineErrors) != 1 || lineErrors[0].Line != 4 {
+		t.Errorf("got %v, want a single error on line 4", err)
+	}
+}
+
+func TestPredictStreamStrict(t *testing.T) {
+	model := &NanoNeuron{w: 1.8, b: 32}
+	var out bytes.Buffer
+	err := PredictStreamStrict(model, strings.NewReader("0\n\nten\n10\n"), &out)
+	var lineErr *LineError
+	if !errors.As(err, &lineErr) || lineErr.Line != 3 {
+		t.Errorf("got %v, want an error on line 3", err)
+	}
+	if got := out.String(); got != "32\n" {
+		t.Errorf("got output %q, want only the line before the error", got)
+	}
+}
+
+func TestPredictCSVAppendsOnlyThePrediction(t *testing.T) {
+	model := &NanoNeuron{w: 1.8, b: 32}
+	in := "celsius,city,\"note, quoted\"\n" +
+		"0,Oslo,freezing\n" +
+		"100,,boiling\n"
+	var out strings.Builder
+	if err := PredictCSV(model, strings.NewReader(in), &out); err != nil {
+		t.Fatalf("PredictCSV: %v", err)
+	}
+	want := "celsius,city,\"note, quoted\",prediction\n" +
+		"0,Oslo,freezing,32\n" +
+		"100,,boiling,212\n"
+	if got := out.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	var lineErr *LineError
+	err := PredictCSV(model, strings.NewReader("celsius\n1\nwarm\n"), io.Discard)
+	if !errors.As(err, &lineErr) || lineErr.Line != 3 {
+		t.Errorf("got %v, want a LineError of line 3", err)
+	}
+}
+
+func TestWriteResidualsCSV(t *testing.T) {
+	model := &NanoNeuron{w: 2, b: 30}
+	x, y := generateDataSets(-5, 0, 0, nil)
+	var out bytes.Buffer
+	if err := WriteResidualsCSV(&out, model, x, y); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != len(x)+1 || !slices.Equal(records[0], []string{"x", "y", "prediction", "residual"}) {
+		t.Fatalf("got %d rows starting with %v", len(records), records[0])
+	}
+	for i, record := range records[1:] {
+		var values [4]float64
+		for j := range values {
+			if values[j], err = strconv.ParseFloat(record[j], 64); err != nil {
+				t.Fatalf("row %d: %v", i, err)
+			}
+		}
+		if values[0] != x[i] || values[1] != y[i] || values[2] != model.predict(x[i]) || values[3] != values[1]-values[2] {
+			t.Errorf("row %d: %v, want the residual y - prediction of (%v, %v)", i, record, x[i], y[i])
+		}
+	}
+
+	if err := WriteResidualsCSV(io.Discard, model, x, y[1:]); !errors.Is(err, ErrLengthMismatch) {
+		t.Errorf("got %v for mismatched lengths, want ErrLengthMismatch", err)
+	}
+}
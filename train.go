@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Train is the checked entry point to the training process of trainModel.
+// It validates the data-set and the hyperparameters before teaching the model (so
+// mismatched inputs are reported as ErrLengthMismatch instead of a panic) and
+// reports ErrDiverged when the training has blown up (the learning rate was too big)
+// and ErrStalled when the StallWindow watchdog has stopped it.
+// The returned result is valid even together with ErrDiverged, to help analysing what went wrong.
+func Train(model *NanoNeuron, xTrain, yTrain []float64, epochs int, alpha float64, opts TrainOptions) (*TrainingResult, error) {
+	if err := validateDataSet(xTrain, yTrain); err != nil {
+		return nil, fmt.Errorf("training data: %w", err)
+	}
+	if opts.XVal != nil || opts.YVal != nil {
+		if err := validateDataSet(opts.XVal, opts.YVal); err != nil {
+			return nil, fmt.Errorf("validation data: %w", err)
+		}
+	}
+	if opts.Weights != nil && len(opts.Weights) != len(xTrain) {
+		return nil, fmt.Errorf("training weights: %w: %d examples but %d weights", ErrLengthMismatch, len(xTrain), len(opts.Weights))
+	}
+	if err := validateTrainOptions(epochs, alpha, opts); err != nil {
+		return nil, err
+	}
+
+	result := trainModel(model, epochs, alpha, xTrain, yTrain, opts)
+	if result.Diverged {
+		return result, fmt.Errorf("%w: the cost kept growing after %d halvings of the learning rate", ErrDiverged, result.RateHalvings)
+	}
+	if n := len(result.CostHistory); n > 0 && !isFinite(result.CostHistory[n-1]) {
+		return result, fmt.Errorf("%w at epoch %d", ErrDiverged, n-1)
+	}
+	if !isFinite(model.w) || !isFinite(model.b) {
+		return result, fmt.Errorf("%w: parameters w=%v b=%v", ErrDiverged, model.w, model.b)
+	}
+	if result.Stalled {
+		n := len(result.CostHistory)
+		return result, fmt.Errorf("%w at epoch %d with cost %v", ErrStalled, n-1, result.CostHistory[n-1])
+	}
+	return result, nil
+}
+
+func validateTrainOptions(epochs int, alpha float64, opts TrainOptions) error {
+	switch {
+	case epochs < 1:
+		return fmt.Errorf("%w: epochs must be positive, got %d", ErrInvalidHyperparameter, epochs)
+	case opts.Schedule == nil && (!isFinite(alpha) || alpha <= 0):
+		return fmt.Errorf("%w: alpha must be a positive number, got %v", ErrInvalidHyperparameter, alpha)
+	case opts.BatchSize < 0:
+		return fmt.Errorf("%w: batch size must not be negative, got %d", ErrInvalidHyperparameter, opts.BatchSize)
+	case opts.AdaptiveBatch != nil && (opts.AdaptiveBatch.MinBatch < 1 || opts.AdaptiveBatch.MaxBatch < opts.AdaptiveBatch.MinBatch):
+		return fmt.Errorf("%w: adaptive batch sizes must be 1 <= min <= max, got %d and %d", ErrInvalidHyperparameter, opts.AdaptiveBatch.MinBatch, opts.AdaptiveBatch.MaxBatch)
+	case opts.AccumSteps < 0:
+		return fmt.Errorf("%w: accumulation steps must not be negative, got %d", ErrInvalidHyperparameter, opts.AccumSteps)
+	case opts.ParamTolerance < 0:
+		return fmt.Errorf("%w: parameter tolerance must not be negative, got %v", ErrInvalidHyperparameter, opts.ParamTolerance)
+	case opts.RelTolerance < 0:
+		return fmt.Errorf("%w: relative tolerance must not be negative, got %v", ErrInvalidHyperparameter, opts.RelTolerance)
+	case opts.StallWindow < 0 || opts.StallEpsilon < 0:
+		return fmt.Errorf("%w: stall window and epsilon must not be negative, got %d and %v", ErrInvalidHyperparameter, opts.StallWindow, opts.StallEpsilon)
+	case opts.GradClipNorm < 0:
+		return fmt.Errorf("%w: gradient clip norm must not be negative, got %v", ErrInvalidHyperparameter, opts.GradClipNorm)
+	case opts.MaxEpochDuration < 0:
+		return fmt.Errorf("%w: maximum epoch duration must not be negative, got %v", ErrInvalidHyperparameter, opts.MaxEpochDuration)
+	case opts.Weights != nil && (opts.BatchSize > 0 || opts.AdaptiveBatch != nil || opts.SlowEpochShrink || opts.AccumSteps > 1 || opts.CostProvider != nil ||
+		opts.SkipNonFinite || opts.ImportanceSampling != nil || opts.Shuffle):
+		return fmt.Errorf("%w: weights work with the full batch squared error only", ErrInvalidHyperparameter)
+	case opts.Autograd && (opts.CostProvider != nil || opts.Weights != nil):
+		return fmt.Errorf("%w: autograd works with the unweighted squared error only", ErrInvalidHyperparameter)
+	case opts.SnapshotRestarts && !hasRestarts(opts.Schedule):
+		return fmt.Errorf("%w: restart snapshots need a schedule with warm restarts", ErrInvalidHyperparameter)
+	case opts.ImportanceSampling != nil && opts.ImportanceSampling.Rand == nil:
+		return fmt.Errorf("%w: importance sampling needs a random source", ErrInvalidHyperparameter)
+	case !(opts.JitterStd >= 0) || math.IsInf(opts.JitterStd, 0):
+		return fmt.Errorf("%w: input jitter must be a non-negative number, got %v", ErrInvalidHyperparameter, opts.JitterStd)
+	case opts.JitterStd > 0 && opts.JitterRand == nil:
+		return fmt.Errorf("%w: input jitter needs a random source", ErrInvalidHyperparameter)
+	}
+	return nil
+}
+
+func hasRestarts(schedule LearningRateSchedule) bool {
+	if offset, ok := schedule.(offsetSchedule); ok {
+		return hasRestarts(offset.schedule)
+	}
+	_, ok := schedule.(cycleEnder)
+	return ok
+}
+
+func isFinite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+// The clock is only checked once per this many epochs to keep its overhead low.
+const clockCheckEpochs = 100
+
+// TrainForDuration trains the model for a wall-clock 'budget' instead of a fixed number of epochs.
+// Epochs run in chunks of clockCheckEpochs between the clock checks, so the training may
+// overrun the budget by up to one chunk. At least one chunk is always run. The training ends
+// before the budget when there is nothing left to gain: it converged (i.e. to a perfect fit),
+// stalled, diverged or its cost is no longer a finite number.
+func TrainForDuration(model *NanoNeuron, x, y []float64, alpha float64, budget time.Duration) *TrainingResult {
+	start := time.Now()
+	result := trainModel(model, clockCheckEpochs, alpha, x, y, TrainOptions{})
+	for !result.Converged && !result.Stalled && !result.Diverged && isFinite(result.FinalCost()) && time.Since(start) < budget {
+		result.append(trainModel(model, clockCheckEpochs, alpha, x, y, TrainOptions{}))
+	}
+	return result
+}
+
+// estimateEpochs is how many epochs EstimateTrainingTime actually runs.
+const estimateEpochs = 100
+
+// EstimateTrainingTime roughly predicts how long training 'model' for 'epochs' epochs on the
+// data-set would take. It times up to estimateEpochs epochs on a copy of the model (so 'model'
+// stays untouched) and extrapolates linearly.
+func EstimateTrainingTime(model *NanoNeuron, x, y []float64, epochs int) time.Duration {
+	if epochs <= 0 {
+		return 0
+	}
+	sample := min(epochs, estimateEpochs)
+	clone := *model
+	start := time.Now()
+	// The learning rate does not change the amount of work, zero keeps the numbers tame.
+	trainModel(&clone, sample, 0, x, y, TrainOptions{})
+	elapsed := time.Since(start)
+	return max(elapsed*time.Duration(epochs)/time.Duration(sample), 1)
+}
+
+// Number is any built-in type a data-set may come in.
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64
+}
+
+// TrainNumbers is Train for data-sets of any numeric type. The values are converted
+// to float64 first, so the result is the same as training on the converted data.
+func TrainNumbers[T Number](model *NanoNeuron, x, y []T, epochs int, alpha float64, opts TrainOptions) (*TrainingResult, error) {
+	return Train(model, toFloats(x), toFloats(y), epochs, alpha, opts)
+}
+
+// TrainInts is TrainNumbers for the most common case of integer data.
+func TrainInts(model *NanoNeuron, x, y []int, epochs int, alpha float64, opts TrainOptions) (*TrainingResult, error) {
+	return TrainNumbers(model, x, y, epochs, alpha, opts)
+}
+
+func toFloats[T Number](values []T) []float64 {
+	floats := make([]float64, len(values))
+	for i, v := range values {
+		floats[i] = float64(v)
+	}
+	return floats
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestTrainForDuration(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	model := NewNanoNeuron(NewRandSource(1))
+	const budget = 50 * time.Millisecond
+	start := time.Now()
+	result := TrainForDuration(model, x, y, 0.0005, budget)
+	elapsed := time.Since(start)
+	if elapsed < budget || elapsed > budget+time.Second {
+		t.Errorf("trained for %v with a budget of %v", elapsed, budget)
+	}
+	if len(result.CostHistory) < clockCheckEpochs {
+		t.Fatalf("trained only %d epochs", len(result.CostHistory))
+	}
+	if result.FinalCost() >= result.InitialCost {
+		t.Errorf("the cost went from %v to %v", result.InitialCost, result.FinalCost())
+	}
+}
+
+func TestTrainForDurationStopsWhenThereIsNothingToGain(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	const budget = 10 * time.Second
+	start := time.Now()
+	// Too big a learning rate overflows the cost within the first chunk.
+	diverged := TrainForDuration(NewNanoNeuron(NewRandSource(1)), x, y, 1, budget)
+	if epochs := len(diverged.CostHistory); epochs != clockCheckEpochs || isFinite(diverged.FinalCost()) {
+		t.Errorf("the diverged training ran %d epochs to the cost %v, want a single chunk", epochs, diverged.FinalCost())
+	}
+
+	// In hundreds of degrees the exactly linear data is fitted perfectly in a few chunks.
+	for i := range x {
+		x[i] /= 100
+	}
+	perfect := TrainForDuration(NewNanoNeuron(NewRandSource(1)), x, y, 1, budget)
+	if !perfect.PerfectFit {
+		t.Errorf("the training ended after %d epochs without a perfect fit", len(perfect.CostHistory))
+	}
+	if elapsed := time.Since(start); elapsed > budget/2 {
+		t.Errorf("the finished trainings took %v of their budget %v", elapsed, budget)
+	}
+}
+
+func TestTrainIntsEqualsTrainingOnFloats(t *testing.T) {
+	cs := []int{-40, -10, 0, 10, 25, 37, 100}
+	fs := make([]int, len(cs))
+	for i, c := range cs {
+		fs[i] = c*9/5 + 32
+	}
+	fromInts := NewNanoNeuron(NewRandSource(1))
+	if _, err := TrainInts(fromInts, cs, fs, 2000, 0.0005, TrainOptions{}); err != nil {
+		t.Fatalf("TrainInts: %v", err)
+	}
+	fromFloats := NewNanoNeuron(NewRandSource(1))
+	if _, err := Train(fromFloats, toFloats(cs), toFloats(fs), 2000, 0.0005, TrainOptions{}); err != nil {
+		t.Fatalf("Train: %v", err)
+	}
+	if *fromInts != *fromFloats {
+		t.Errorf("trained on ints %v, on floats %v", fromInts, fromFloats)
+	}
+
+	fromUint8 := NewNanoNeuron(NewRandSource(1))
+	if _, err := TrainNumbers(fromUint8, []uint8{0, 10, 100}, []uint8{32, 50, 212}, 100, 0.0005, TrainOptions{}); err != nil {
+		t.Fatalf("TrainNumbers: %v", err)
+	}
+}
+
+func TestAdaptiveRecoveryConvergesWithAggressiveAlpha(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	model := NewNanoNeuron(NewRandSource(1))
+	result, err := Train(model, x, y, 70000, 0.01, TrainOptions{AdaptiveRecovery: true})
+	if err != nil {
+		t.Fatalf("Train: %v", err)
+	}
+	if result.RateHalvings == 0 {
+		t.Errorf("alpha 0.01 diverges on the demo data, expected the rate to be halved")
+	}
+	if math.Abs(model.w-1.8) > 0.01 || math.Abs(model.b-32) > 0.5 {
+		t.Errorf("got w=%v b=%v, want close to w=1.8 b=32", model.w, model.b)
+	}
+	if cost := result.FinalCost(); !(cost < 0.01) {
+		t.Errorf("final cost %v, want below 0.01", cost)
+	}
+}
+
+func TestAdaptiveRecoveryRestoresOptimizerState(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	model := NewNanoNeuron(NewRandSource(1))
+	momentum := NewMomentum(0.9)
+	result, err := Train(model, x, y, 100, 0.01, TrainOptions{AdaptiveRecovery: true, Optimizer: momentum})
+	if err != nil {
+		t.Fatalf("Train: %v", err)
+	}
+	for i := 1; i < len(result.CostHistory); i++ {
+		if result.CostHistory[i] > result.CostHistory[i-1]*(1+recoveryTolerance) {
+			t.Fatalf("epoch %d: cost grew from %v to %v", i, result.CostHistory[i-1], result.CostHistory[i])
+		}
+	}
+	vW, vB := momentum.Velocity()
+	if !isFinite(vW) || !isFinite(vB) {
+		t.Errorf("velocity (%v, %v) is not finite", vW, vB)
+	}
+}
+
+func TestAdaptiveRecoveryGivesUpWithErrDiverged(t *testing.T) {
+	// A NaN label spoils every epoch, no learning rate can fix that.
+	x, y := generateDataSets(0, 0, 0, nil)
+	y[3] = math.NaN()
+	model := NewNanoNeuron(NewRandSource(1))
+	w, b := model.w, model.b
+	result, err := Train(model, x, y, 100, 0.0005, TrainOptions{AdaptiveRecovery: true})
+	if !errors.Is(err, ErrDiverged) {
+		t.Fatalf("got %v, want ErrDiverged", err)
+	}
+	if !result.Diverged || result.RateHalvings != maxRateHalvings {
+		t.Errorf("got Diverged=%v after %d halvings, want true after %d", result.Diverged, result.RateHalvings, maxRateHalvings)
+	}
+	if model.w != w || model.b != b {
+		t.Errorf("the spoiled epochs weren't rolled back: w=%v b=%v, want w=%v b=%v", model.w, model.b, w, b)
+	}
+}
+
+func TestEstimateTrainingTimeScalesWithTheEpochs(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	model := NewNanoNeuron(NewRandSource(1))
+	before := *model
+	// The fastest of a few estimates is the least disturbed by the rest of the machine.
+	fastest := func(epochs int) time.Duration {
+		best := time.Duration(math.MaxInt64)
+		for range 5 {
+			best = min(best, EstimateTrainingTime(model, x, y, epochs))
+		}
+		return best
+	}
+	short, long := fastest(10000), fastest(100000)
+	if short <= 0 || long <= 0 {
+		t.Fatalf("estimated %v and %v", short, long)
+	}
+	if ratio := float64(long) / float64(short); ratio < 3 || ratio > 30 {
+		t.Errorf("10 times the epochs are estimated to take %.1f times as long", ratio)
+	}
+	if *model != before {
+		t.Errorf("the estimate trained the model: %v, was %v", model, &before)
+	}
+}
+
+func TestStallWatchdogCatchesASaturatedSigmoid(t *testing.T) {
+	// Driven deep into its flat tail, the sigmoid passes back practically no gradient.
+	x, y := generateDataSets(0, 0, 0, nil)
+	model := &NanoNeuron{w: 0, b: -60, activation: Sigmoid{}}
+	opts := TrainOptions{StallWindow: 100, StallEpsilon: 1e-9, StallTarget: 1}
+	result, err := Train(model, x, y, 70000, 0.0005, opts)
+	if !errors.Is(err, ErrStalled) {
+		t.Fatalf("got %v, want ErrStalled", err)
+	}
+	if !result.Stalled || result.Converged || len(result.CostHistory) > 1000 {
+		t.Errorf("stalled=%v converged=%v after %d epochs", result.Stalled, result.Converged, len(result.CostHistory))
+	}
+
+	// A training that gets close to the target is never taken for a stall.
+	healthy := NewNanoNeuron(NewRandSource(1))
+	if _, err := Train(healthy, x, y, 70000, 0.0005, opts); err != nil {
+		t.Errorf("the healthy training reported %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+// Trainer runs a training of a fixed number of epochs in chunks, so a server training in the
+// background can yield to its request handlers in between. The state persists across the
+// chunks: the model parameters, the optimizer and the epoch count of the learning rate schedule.
+type Trainer struct {
+	Model   *NanoNeuron
+	XTrain  []float64
+	YTrain  []float64
+	Epochs  int // the total epoch budget
+	Alpha   float64
+	Options TrainOptions
+	// Resumed is the number of epochs trained before this Trainer took over, i.e. the epoch of
+	// a checkpoint loaded by LoadCheckpoint. They count into the budget and the schedule.
+	Resumed int
+
+	result *TrainingResult // all the chunks so far
+}
+
+// RunChunk advances the training by up to 'epochs' epochs and reports whether it is done:
+// the total epoch budget is reached or the training has converged (or stalled).
+// Without training examples there is nothing to do, so it is done right away.
+func (t *Trainer) RunChunk(epochs int) (done bool) {
+	trained := t.Trained()
+	epochs = min(epochs, t.Epochs-trained)
+	if epochs <= 0 || len(t.XTrain) == 0 || (t.result != nil && (t.result.Converged || t.result.Stalled)) {
+		return true
+	}
+	opts := continuedOptions(t.Options, trained)
+	opts.totalEpochs = t.Epochs
+	chunk := trainModel(t.Model, epochs, t.Alpha, t.XTrain, t.YTrain, opts)
+	if t.result == nil {
+		t.result = chunk
+	} else {
+		t.result.append(chunk)
+	}
+	return t.result.Converged || t.result.Stalled || t.Trained() >= t.Epochs
+}
+
+// Trained is the number of epochs trained so far.
+func (t *Trainer) Trained() int {
+	if t.result == nil {
+		return t.Resumed
+	}
+	return t.Resumed + len(t.result.CostHistory)
+}
+
+// Result is the training result of all the chunks run so far (nil before the first chunk).
+// It doesn't include the Resumed epochs.
+func (t *Trainer) Result() *TrainingResult {
+	return t.result
+}
+
+// continuedOptions adapts the options of a training to continue one that has already run
+// 'trained' epochs: the initializer is left out and the schedule and the shuffling continue
+// counting epochs.
+func continuedOptions(opts TrainOptions, trained int) TrainOptions {
+	if trained == 0 {
+		return opts
+	}
+	opts.Init = nil
+	opts.epochOffset = trained
+	if opts.Schedule != nil {
+		opts.Schedule = offsetSchedule{schedule: opts.Schedule, offset: trained}
+	}
+	return opts
+}
+
+// append adds the result of the training that continued this one.
+func (r *TrainingResult) append(next *TrainingResult) {
+	r.CostHistory = append(r.CostHistory, next.CostHistory...)
+	r.BatchCostStd = append(r.BatchCostStd, next.BatchCostStd...)
+	r.GradNormHistory = append(r.GradNormHistory, next.GradNormHistory...)
+	r.MeanAbsGradHistory = append(r.MeanAbsGradHistory, next.MeanAbsGradHistory...)
+	if next.BatchSizeHistory != nil {
+		r.BatchSizeHistory = append(r.BatchSizeHistory, next.BatchSizeHistory...)
+	}
+	r.Snapshots = append(r.Snapshots, next.Snapshots...)
+	if next.ParamHistory != nil {
+		r.ParamHistory = append(r.ParamHistory, next.ParamHistory...)
+	}
+	r.Final = next.Final
+	r.Converged = next.Converged
+	r.PerfectFit = next.PerfectFit
+	r.Stalled = next.Stalled
+	r.Diverged = next.Diverged
+	r.RateHalvings += next.RateHalvings
+	r.Skipped += next.Skipped
+	r.SlowEpochs += next.SlowEpochs
+	if next.BestModel != nil && (r.BestModel == nil || next.BestCost < r.BestCost) {
+		r.BestModel, r.BestCost = next.BestModel, next.BestCost
+	}
+	if next.AveragedModel != nil {
+		// The tail of the whole training may span several parts: weigh their averages by
+		// the number of epochs in them.
+		if r.AveragedModel == nil {
+			r.AveragedModel, r.averaged = next.AveragedModel, next.averaged
+		} else {
+			average := *next.AveragedModel
+			r.averaged += next.averaged
+			share := float64(next.averaged) / float64(r.averaged)
+			average.w = r.AveragedModel.w + (average.w-r.AveragedModel.w)*share
+			average.b = r.AveragedModel.b + (average.b-r.AveragedModel.b)*share
+			r.AveragedModel = &average
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestTrainerChunksTrainLikeOneRun(t *testing.T) {
+	x, y := generateDataSets(0, 1, 0, NewRandSource(1))
+	const epochs = 100
+
+	whole := NewNanoNeuron(NewRandSource(1))
+	want := trainModel(whole, epochs, 0.0005, x, y, TrainOptions{})
+
+	chunked := NewNanoNeuron(NewRandSource(1))
+	trainer := &Trainer{Model: chunked, XTrain: x, YTrain: y, Epochs: epochs, Alpha: 0.0005}
+	for chunks := 0; !trainer.RunChunk(30); chunks++ {
+		if chunks > epochs {
+			t.Fatal("the trainer never got done")
+		}
+	}
+
+	if *chunked != *whole {
+		t.Errorf("chunked model %v, want %v", chunked, whole)
+	}
+	if trainer.Trained() != epochs {
+		t.Errorf("trained %d epochs, want %d", trainer.Trained(), epochs)
+	}
+	if got := trainer.Result(); !reflect.DeepEqual(got.CostHistory, want.CostHistory) {
+		t.Errorf("chunked cost history %v, want %v", got.CostHistory, want.CostHistory)
+	}
+}
+
+func TestTrainerChunksShuffleLikeOneRun(t *testing.T) {
+	x, y := generateDataSets(0, 1, 0, NewRandSource(1))
+	const epochs = 100
+	opts := TrainOptions{BatchSize: 10, Shuffle: true, ShuffleSeed: 3}
+
+	whole := NewNanoNeuron(NewRandSource(1))
+	want := trainModel(whole, epochs, 0.0001, x, y, opts)
+
+	chunked := NewNanoNeuron(NewRandSource(1))
+	trainer := &Trainer{Model: chunked, XTrain: x, YTrain: y, Epochs: epochs, Alpha: 0.0001, Options: opts}
+	for !trainer.RunChunk(30) {
+	}
+	if *chunked != *whole || !reflect.DeepEqual(trainer.Result().CostHistory, want.CostHistory) {
+		t.Errorf("chunked shuffled training reached %v, one run %v", chunked, whole)
+	}
+}
+
+func TestTrainerAveragesTheTailOfTheWholeBudget(t *testing.T) {
+	x, y := generateDataSets(0, 10, 0, NewRandSource(1))
+	const epochs = 1000
+	opts := TrainOptions{BatchSize: 1, AverageTail: 0.5}
+
+	want := trainModel(NewNanoNeuron(NewRandSource(1)), epochs, 0.0001, x, y, opts).AveragedModel
+
+	trainer := &Trainer{Model: NewNanoNeuron(NewRandSource(1)), XTrain: x, YTrain: y, Epochs: epochs, Alpha: 0.0001, Options: opts}
+	for !trainer.RunChunk(300) {
+	}
+	got := trainer.Result().AveragedModel
+	if got == nil || math.Abs(got.w-want.w) > 1e-9 || math.Abs(got.b-want.b) > 1e-9 {
+		t.Errorf("chunked training averaged %v, one run %v", got, want)
+	}
+
+	online := &OnlineTrainer{Model: NewNanoNeuron(NewRandSource(1)), Alpha: 0.0001, Options: opts, Data: DataSet{X: x, Y: y}}
+	if _, err := online.Train(10); !errors.Is(err, ErrInvalidHyperparameter) {
+		t.Errorf("the online AverageTail got %v, want ErrInvalidHyperparameter", err)
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import "math"
+
+// affine is the y -> scale * y + offset transform of the model output.
+type affine struct {
+	scale  float64
+	offset float64
+}
+
+// WithOutputTransform returns a copy of the model whose predictions are scale * predict(x) + offset.
+// The original model is left untouched, so one trained model can serve its predictions in different units.
+// For a model trained to predict Fahrenheit, Kelvin is
+//
+//	kelvin := model.WithOutputTransform(5.0/9, 273.15-32*5.0/9)
+//
+// Transforms can be chained, the last one is applied on top of the previous ones.
+func (n *NanoNeuron) WithOutputTransform(scale, offset float64) *NanoNeuron {
+	transformed := *n
+	if n.output != nil {
+		scale, offset = scale*n.output.scale, scale*n.output.offset+offset
+	}
+	transformed.output = &affine{scale: scale, offset: offset}
+	return &transformed
+}
+
+// Quantize returns a copy of the model with 'w' and 'b' rounded onto a signed fixed-point grid of
+// 'bits' bits (2 to 53) for constrained deployment targets. The grid spans the dynamic range of
+// the parameters, [-max(|w|, |b|), +max(|w|, |b|)], in 2^(bits-1) - 1 steps on each side, so
+// every parameter moves by at most half a step and a prediction by at most (|x| + 1) / 2 steps.
+func (n *NanoNeuron) Quantize(bits int) *NanoNeuron {
+	bits = min(max(bits, 2), 53)
+	quantized := *n
+	limit := math.Max(math.Abs(n.w), math.Abs(n.b))
+	if limit == 0 || !isFinite(limit) {
+		return &quantized
+	}
+	step := limit / float64(int64(1)<<(bits-1)-1)
+	quantized.w = math.Round(n.w/step) * step
+	quantized.b = math.Round(n.b/step) * step
+	return &quantized
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestWithOutputTransform(t *testing.T) {
+	fahrenheit := &NanoNeuron{w: 1.8, b: 32}
+	kelvin := fahrenheit.WithOutputTransform(5.0/9, 273.15-32*5.0/9)
+	for _, x := range []float64{-273.15, -40, 0, 37, 100} {
+		want := 5.0/9*fahrenheit.predict(x) + 273.15 - 32*5.0/9
+		if got := kelvin.predict(x); math.Abs(got-want) > 1e-9 {
+			t.Errorf("%v °C: %v K, want %v", x, got, want)
+		}
+		if got := kelvin.predict(x); math.Abs(got-(x+273.15)) > 1e-9 {
+			t.Errorf("%v °C: %v K, want %v", x, got, x+273.15)
+		}
+	}
+	if fahrenheit.output != nil {
+		t.Error("the base model was changed")
+	}
+
+	// Chained transforms apply the last one on top of the previous ones.
+	chained := fahrenheit.WithOutputTransform(2, 1).WithOutputTransform(3, -4)
+	if got, want := chained.predict(10), 3*(2*fahrenheit.predict(10)+1)-4; math.Abs(got-want) > 1e-9 {
+		t.Errorf("chained transform predicted %v, want %v", got, want)
+	}
+}
+
+func TestQuantizeStaysWithinHalfAStep(t *testing.T) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	model := NewNanoNeuron(NewRandSource(1))
+	trainModel(model, 70000, 0.0005, x, y, TrainOptions{})
+	for _, bits := range []int{4, 8, 16} {
+		quantized := model.Quantize(bits)
+		step := math.Max(math.Abs(model.w), math.Abs(model.b)) / float64(int(1)<<(bits-1)-1)
+		for _, c := range []float64{-40, 0, 37, 100} {
+			bound := (math.Abs(c) + 1) / 2 * step
+			if diff := math.Abs(quantized.predict(c) - model.predict(c)); diff > bound {
+				t.Errorf("%d bits: the prediction for %v is off by %v, more than %v", bits, c, diff, bound)
+			}
+		}
+	}
+	if diff := math.Abs(model.Quantize(16).predict(100) - model.predict(100)); diff > 0.05 {
+		t.Errorf("16 bits are off by %v at 100°C", diff)
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import "fmt"
+
+// Convert Celsius values to Kelvin using formula: k = c + 273.15.
+// It's the second conversion the demo can teach NanoNeuron (see the -units flag).
+func celsiusToKelvin(c float64) float64 {
+	const w = 1
+	const b = 273.15
+	return c*w + b
+}
+
+// demoUnits is a conversion from Celsius the demo teaches NanoNeuron: the correct conversion
+// the data-sets are labeled with and the names it's printed with.
+type demoUnits struct {
+	label  func(c float64) float64
+	symbol string // i.e. "°F"
+	name   string // i.e. "Fahrenheit"
+}
+
+// selectUnits maps the value of the -units flag to the conversion of the demo.
+func selectUnits(flagValue string) (demoUnits, error) {
+	switch flagValue {
+	case "fahrenheit":
+		return demoUnits{label: celsiusToFahrenheit, symbol: "°F", name: "Fahrenheit"}, nil
+	case "kelvin":
+		return demoUnits{label: celsiusToKelvin, symbol: "K", name: "Kelvin"}, nil
+	}
+	return demoUnits{}, fmt.Errorf("unknown units %q, want fahrenheit or kelvin", flagValue)
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSelectUnitsPicksTheLabeler(t *testing.T) {
+	for _, c := range []struct {
+		flag         string
+		symbol       string
+		freezing     float64
+		boiling      float64
+		absoluteZero float64
+	}{
+		{"fahrenheit", "°F", 32, 212, -459.67},
+		{"kelvin", "K", 273.15, 373.15, 0},
+	} {
+		units, err := selectUnits(c.flag)
+		if err != nil {
+			t.Fatalf("%s: %v", c.flag, err)
+		}
+		if units.symbol != c.symbol {
+			t.Errorf("%s: symbol %q, want %q", c.flag, units.symbol, c.symbol)
+		}
+		for _, p := range [][2]float64{{0, c.freezing}, {100, c.boiling}, {-273.15, c.absoluteZero}} {
+			if got := units.label(p[0]); math.Abs(got-p[1]) > 1e-9 {
+				t.Errorf("%s: %v °C is labeled %v, want %v", c.flag, p[0], got, p[1])
+			}
+		}
+	}
+	if _, err := selectUnits("rankine"); err == nil {
+		t.Error("unknown units were accepted")
+	}
+}
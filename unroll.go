@@ -0,0 +1,59 @@
+package main
+
+// forwardPropagationUnrolled is forwardPropagationInto processing 4 examples per loop iteration,
+// which saves some of the loop overhead of the hot path. The costs are still added up one by one
+// in the order of the examples, so the result is exactly the same. The remaining len % 4
+// examples are processed one by one at the end.
+func forwardPropagationUnrolled(model *NanoNeuron, xTrain, yTrain, predictions []float64) float64 {
+	mustMatch("forward propagation", xTrain, yTrain)
+	mustMatch("forward propagation", xTrain, predictions)
+	m := len(xTrain)
+	cost := 0.0
+	i := 0
+	for ; i+4 <= m; i += 4 {
+		x, y, p := xTrain[i:i+4:i+4], yTrain[i:i+4:i+4], predictions[i:i+4:i+4]
+		p[0] = model.predict(x[0])
+		p[1] = model.predict(x[1])
+		p[2] = model.predict(x[2])
+		p[3] = model.predict(x[3])
+		cost += predictionCost(y[0], p[0])
+		cost += predictionCost(y[1], p[1])
+		cost += predictionCost(y[2], p[2])
+		cost += predictionCost(y[3], p[3])
+	}
+	for ; i < m; i++ {
+		predictions[i] = model.predict(xTrain[i])
+		cost += predictionCost(yTrain[i], predictions[i])
+	}
+	return cost / float64(m)
+}
+
+// backwardPropagationUnrolled is backwardPropagation processing 4 examples per loop iteration,
+// with the same order of the additions and so exactly the same result.
+func backwardPropagationUnrolled(model *NanoNeuron, predictions, xTrain, yTrain []float64) (float64, float64) {
+	mustMatch("backward propagation", xTrain, yTrain)
+	mustMatch("backward propagation", xTrain, predictions)
+	m := len(xTrain)
+	dW, dB := 0.0, 0.0
+	delta := func(i int) float64 {
+		return model.chainRule(xTrain[i], 2*costScale*(yTrain[i]-predictions[i]))
+	}
+	i := 0
+	for ; i+4 <= m; i += 4 {
+		d0, d1, d2, d3 := delta(i), delta(i+1), delta(i+2), delta(i+3)
+		dW += d0 * xTrain[i]
+		dB += d0
+		dW += d1 * xTrain[i+1]
+		dB += d1
+		dW += d2 * xTrain[i+2]
+		dB += d2
+		dW += d3 * xTrain[i+3]
+		dB += d3
+	}
+	for ; i < m; i++ {
+		d := delta(i)
+		dW += d * xTrain[i]
+		dB += d
+	}
+	return dW / float64(m), dB / float64(m)
+}
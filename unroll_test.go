@@ -0,0 +1,59 @@
+package main
+
+import "testing"
+
+func TestUnrolledPropagationEqualsTheStraightOne(t *testing.T) {
+	x, y := generateDataSets(0, 1, 0, NewRandSource(1))
+	models := []*NanoNeuron{
+		NewNanoNeuron(NewRandSource(1)),
+		{w: 0.5, b: -3, activation: LeakyReLU(0.1)},
+		{w: 1.7, b: 30, output: &affine{scale: 2, offset: 1}},
+	}
+	// All the remainders of the length divided by 4, including the data-sets shorter than 4.
+	for _, m := range []int{0, 1, 2, 3, 4, 5, 6, 7, 97, len(x)} {
+		for _, model := range models {
+			straight, unrolled := make([]float64, m), make([]float64, m)
+			wantCost := forwardPropagationInto(model, x[:m], y[:m], straight)
+			gotCost := forwardPropagationUnrolled(model, x[:m], y[:m], unrolled)
+			if m > 0 && gotCost != wantCost {
+				t.Errorf("%d examples: unrolled cost %v, want %v", m, gotCost, wantCost)
+			}
+			for i := range straight {
+				if unrolled[i] != straight[i] {
+					t.Fatalf("%d examples: unrolled prediction %d is %v, want %v", m, i, unrolled[i], straight[i])
+				}
+			}
+			if m == 0 {
+				continue
+			}
+			wantW, wantB := backwardPropagation(model, straight, x[:m], y[:m])
+			gotW, gotB := backwardPropagationUnrolled(model, straight, x[:m], y[:m])
+			if gotW != wantW || gotB != wantB {
+				t.Errorf("%d examples: unrolled gradient (%v, %v), want (%v, %v)", m, gotW, gotB, wantW, wantB)
+			}
+		}
+	}
+
+	straight := NewNanoNeuron(NewRandSource(1))
+	unrolled := NewNanoNeuron(NewRandSource(1))
+	want := trainModel(straight, 1000, 0.0005, x, y, TrainOptions{})
+	got := trainModel(unrolled, 1000, 0.0005, x, y, TrainOptions{Unroll: true})
+	if *unrolled != *straight || got.FinalCost() != want.FinalCost() {
+		t.Errorf("the unrolled training ended at %v with the cost %v, want %v with %v", unrolled, got.FinalCost(), straight, want.FinalCost())
+	}
+}
+
+func BenchmarkTrainModelStraight(b *testing.B) {
+	benchmarkTrainModelUnroll(b, false)
+}
+
+func BenchmarkTrainModelUnrolled(b *testing.B) {
+	benchmarkTrainModelUnroll(b, true)
+}
+
+func benchmarkTrainModelUnroll(b *testing.B, unroll bool) {
+	x, y := generateDataSets(0, 0, 0, nil)
+	for range b.N {
+		trainModel(NewNanoNeuron(NewRandSource(1)), 1000, 0.0005, x, y, TrainOptions{Unroll: unroll})
+	}
+}
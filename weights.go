@@ -0,0 +1,27 @@
+package main
+
+// weightedCost is the average prediction cost where every example counts 'weights' times.
+func weightedCost(y, predictions, weights []float64) float64 {
+	mustMatch("weighted cost", y, weights)
+	cost, total := 0.0, 0.0
+	for i := range y {
+		cost += weights[i] * predictionCost(y[i], predictions[i])
+		total += weights[i]
+	}
+	return cost / total
+}
+
+// weightedBackwardPropagation is backwardPropagation where every example counts 'weights' times.
+func weightedBackwardPropagation(model *NanoNeuron, predictions, xTrain, yTrain, weights []float64) (float64, float64) {
+	mustMatch("weighted backward propagation", xTrain, yTrain)
+	mustMatch("weighted backward propagation", xTrain, predictions)
+	mustMatch("weighted backward propagation", xTrain, weights)
+	dW, dB, total := 0.0, 0.0, 0.0
+	for i := range xTrain {
+		delta := weights[i] * model.chainRule(xTrain[i], 2*costScale*(yTrain[i]-predictions[i]))
+		dW += delta * xTrain[i]
+		dB += delta
+		total += weights[i]
+	}
+	return dW / total, dB / total
+}